@@ -0,0 +1,145 @@
+// Package apperr defines the typed domain errors shared by services and
+// handlers. Every error carries a stable code that clients can branch on, a
+// kind that decides the HTTP status, and optional details that are both
+// returned to clients and used to fill in the localized message.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Kind classifies an error.
+type Kind int
+
+const (
+	KindInvalid Kind = iota + 1
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindGone
+	KindUnprocessable
+	KindTooManyRequests
+	KindInternal
+)
+
+// Status returns the HTTP status code for the kind.
+func (k Kind) Status() int {
+	switch k {
+	case KindInvalid:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindGone:
+		return http.StatusGone
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is a domain error with a stable code. Errors declared as package
+// variables act as sentinels; With and Wrap return copies, and errors.Is
+// matches any copy by code.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Details map[string]any
+	cause   error
+}
+
+// New declares an error with the given kind and code.
+func New(kind Kind, code string) *Error {
+	return &Error{Kind: kind, Code: code}
+}
+
+// Error returns the English message, which is what ends up in logs.
+func (e *Error) Error() string {
+	message := e.Message(LanguageEnglish)
+	if e.cause != nil {
+		return message + ": " + e.cause.Error()
+	}
+	return message
+}
+
+// Message returns the message in the given language.
+func (e *Error) Message(language string) string {
+	template := lookup(language, e.Code)
+	if len(e.Details) == 0 || !strings.Contains(template, "{") {
+		return template
+	}
+	pairs := make([]string, 0, len(e.Details)*2)
+	for key, value := range e.Details {
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// With returns a copy of the error with one more detail.
+func (e *Error) With(key string, value any) *Error {
+	clone := *e
+	clone.Details = make(map[string]any, len(e.Details)+1)
+	for k, v := range e.Details {
+		clone.Details[k] = v
+	}
+	clone.Details[key] = value
+	return &clone
+}
+
+// Wrap returns a copy of the error that records the underlying cause. The
+// cause is logged but never shown to clients.
+func (e *Error) Wrap(cause error) *Error {
+	clone := *e
+	clone.cause = cause
+	return &clone
+}
+
+// Unwrap returns the recorded cause.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Coder is implemented by richer error types that know their domain error.
+type Coder interface {
+	AppError() *Error
+}
+
+// From returns the domain error behind err. Errors that are not domain errors
+// become ErrInternal wrapping err.
+func From(err error) *Error {
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.AppError()
+	}
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// Errors shared by every part of the API.
+var (
+	ErrInvalidRequest = New(KindInvalid, "request.invalid")
+	ErrRouteNotFound  = New(KindNotFound, "route.not_found")
+	ErrRateLimited    = New(KindTooManyRequests, "rate.limited")
+	ErrInternal       = New(KindInternal, "internal")
+)
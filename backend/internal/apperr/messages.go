@@ -0,0 +1,274 @@
+package apperr
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported message languages.
+const (
+	LanguageChinese = "zh-CN"
+	LanguageEnglish = "en"
+
+	// DefaultLanguage is used when the client states no supported language.
+	DefaultLanguage = LanguageChinese
+)
+
+// messages holds the client-facing text per language and code. Placeholders
+// in braces are filled from the error details.
+var messages = map[string]map[string]string{
+	LanguageChinese: {
+		"request.invalid": "请求参数不正确",
+		"route.not_found": "接口不存在",
+		"rate.limited":    "请求过于频繁，请稍后再试",
+		"internal":        "服务器异常，请稍后再试",
+
+		"auth.invalid_credentials": "账号或密码不正确",
+		"auth.invalid_invite_code": "班级邀请码无效",
+		"auth.unknown_role":        "不支持的角色类型",
+
+		"level.not_found":           "关卡不存在",
+		"level.locked":              "关卡未解锁，完成上一关后再试试吧",
+		"level.misconfigured":       "关卡未绑定章节",
+		"level.invalid":             "关卡校验失败",
+		"level.exists":              "关卡已存在",
+		"chapter.not_found":         "章节不存在",
+		"chapter.exists":            "章节已存在",
+		"order.invalid":             "排序需要包含全部项目且不能重复",
+		"revision.not_found":        "未找到关卡版本",
+		"revision.not_draft":        "该版本不是草稿，无法修改或发布",
+		"attempt.not_found":         "未找到答题记录",
+		"program.empty":             "请先添加积木再运行程序",
+		"program.block_locked":      "积木 {block} 尚未解锁",
+		"program.repeat_range":      "重复次数 {times} 超出范围，应为 0 到 {max}",
+		"program.nesting_too_deep":  "积木嵌套过深，最多 {max} 层",
+		"program.syntax":            "第 {line} 行第 {column} 列有语法错误",
+		"program.no_text_form":      "程序中有无法转换为文本的积木",
+		"program.too_long":          "程序文本过长，最多 {maxBytes} 字节",
+		"hint.limit_reached":        "提示次数已用完（每 {windowMinutes} 分钟最多 {hintLimit} 次），请稍后再试",
+		"avatar.outfit_required":    "请选择要装备的装扮",
+		"avatar.outfit_locked":      "装扮 {outfit} 尚未解锁",
+		"language.unsupported":      "不支持的语言 {language}",
+		"badge.not_found":           "徽章不存在",
+		"badge.invalid":             "徽章定义未通过校验",
+		"avatar.item_not_found":     "装扮 {itemId} 不存在",
+		"avatar.item_invalid":       "装扮定义未通过校验",
+		"avatar.item_not_for_sale":  "装扮 {itemId} 不在商店出售",
+		"avatar.item_owned":         "已经拥有装扮 {itemId}",
+		"wallet.insufficient_funds": "余额不足：需要 {price}，当前 {balance}",
+		"idempotency.key_reused":    "幂等键 {key} 已用于其他请求",
+		"project.not_found":         "作品 {projectId} 不存在",
+		"project.invalid":           "作品未通过校验",
+		"sandbox.locked":            "完成更多关卡后才能使用沙盒模式",
+		"work.invalid":              "提交的作品不完整",
+
+		"class.not_found":            "班级不存在",
+		"unlock.invalid":             "解锁规则无效",
+		"unlock.not_found":           "没有为该学生单独开放此关卡",
+		"class.frozen":               "老师暂停了运行，请先看老师",
+		"class.level_restricted":     "老师让大家先专注在指定的关卡上",
+		"class.sandbox_disabled":     "老师暂时关闭了沙盒",
+		"controls.invalid":           "课堂控制设置无效",
+		"class.not_teacher":          "你不是该班级的任课教师",
+		"class.invalid":              "班级信息不完整：{field}",
+		"class.archived":             "班级已归档",
+		"invite.invalid":             "邀请码设置无效：{field}",
+		"invite.expired":             "邀请码已过期，请向老师索取新的邀请码",
+		"invite.exhausted":           "邀请码已达到使用次数上限，请向老师索取新的邀请码",
+		"roster.invalid":             "名单文件无效",
+		"student.not_found":          "学生不存在",
+		"course.not_found":           "课程不存在",
+		"work.not_found":             "作品不存在",
+		"review.unsupported_status":  "不支持的批阅状态",
+		"review.invalid_stars":       "评定星级需在 0 到 3 之间",
+		"work.not_pending":           "作品已批阅，等待学生重新提交",
+		"review.invalid":             "评分不符合评分标准",
+		"rubric.not_found":           "评分标准不存在",
+		"rubric.invalid":             "评分标准未通过校验",
+		"assignment.not_found":       "作业不存在",
+		"assignment.invalid":         "作业设置未通过校验",
+		"assignment.closed":          "作业已结束",
+		"assessment.not_found":       "该作业不是测验",
+		"assessment.not_started":     "请先开始测验",
+		"assessment.ended":           "测验已结束",
+		"assessment.run_limit":       "本关运行次数已用完",
+		"assessment.in_progress":     "测验进行中，暂不可使用此功能",
+		"assessment.hints_disabled":  "测验中不能使用提示",
+		"assessment.not_ended":       "测验尚未结束，不能公布成绩",
+		"assessment.released":        "测验成绩已公布",
+		"analytics.unknown_resource": "不支持的分析数据",
+		"analytics.invalid_filter":   "分析筛选条件不正确",
+		"content.not_owner":          "该内容属于其他教师",
+
+		"export.invalid":     "导出参数不正确：{field}",
+		"export.not_found":   "导出记录不存在",
+		"export.not_ready":   "导出文件尚未生成",
+		"export.expired":     "导出文件已过期，请重新导出",
+		"export.too_many":    "进行中的导出过多，请稍后再试",
+		"export.unavailable": "导出服务暂不可用，请稍后再试",
+
+		"levelpack.invalid":            "关卡包中有关卡未通过校验",
+		"levelpack.invalid_schema":     "关卡包内容不完整",
+		"levelpack.unsupported_format": "不支持的关卡包格式",
+		"levelpack.unsupported_schema": "不支持的关卡包版本",
+		"levelpack.malformed":          "关卡包无法解析",
+
+		"parent.not_found": "未找到家长信息",
+		"child.not_found":  "未找到孩子信息",
+	},
+	LanguageEnglish: {
+		"request.invalid": "The request is invalid",
+		"route.not_found": "Endpoint not found",
+		"rate.limited":    "Too many requests, please try again later",
+		"internal":        "Something went wrong, please try again later",
+
+		"auth.invalid_credentials": "Incorrect account or password",
+		"auth.invalid_invite_code": "Invalid class invite code",
+		"auth.unknown_role":        "Unsupported role",
+
+		"level.not_found":           "Level not found",
+		"level.locked":              "This level is locked, finish the previous level first",
+		"level.misconfigured":       "Level is not attached to a chapter",
+		"level.invalid":             "The level failed validation",
+		"level.exists":              "Level already exists",
+		"chapter.not_found":         "Chapter not found",
+		"chapter.exists":            "Chapter already exists",
+		"order.invalid":             "The order must list every item exactly once",
+		"revision.not_found":        "Level revision not found",
+		"revision.not_draft":        "The revision is not a draft",
+		"attempt.not_found":         "Attempt not found",
+		"program.empty":             "Add some blocks before running the program",
+		"program.block_locked":      "Block {block} is not unlocked yet",
+		"program.repeat_range":      "The repeat count {times} is out of range; it must be between 0 and {max}",
+		"program.nesting_too_deep":  "Blocks are nested too deeply; at most {max} levels are allowed",
+		"program.syntax":            "Syntax error at line {line}, column {column}",
+		"program.no_text_form":      "The program contains blocks without a text form",
+		"program.too_long":          "The program text is too long; at most {maxBytes} bytes are allowed",
+		"hint.limit_reached":        "Hint limit reached ({hintLimit} every {windowMinutes} minutes), please try again later",
+		"avatar.outfit_required":    "Choose an outfit to equip",
+		"avatar.outfit_locked":      "Outfit {outfit} is not unlocked yet",
+		"language.unsupported":      "Language {language} is not supported",
+		"badge.not_found":           "Badge not found",
+		"badge.invalid":             "The badge definition did not pass validation",
+		"avatar.item_not_found":     "Avatar item {itemId} not found",
+		"avatar.item_invalid":       "The avatar item did not pass validation",
+		"avatar.item_not_for_sale":  "Avatar item {itemId} is not for sale",
+		"avatar.item_owned":         "You already own {itemId}",
+		"wallet.insufficient_funds": "Not enough {currency}: {price} needed, {balance} available",
+		"idempotency.key_reused":    "Idempotency key {key} was already used for a different request",
+		"project.not_found":         "Project {projectId} not found",
+		"project.invalid":           "The project did not pass validation",
+		"sandbox.locked":            "Complete more levels to unlock sandbox mode",
+		"work.invalid":              "The submission is incomplete",
+
+		"class.not_found":            "Class not found",
+		"unlock.invalid":             "Invalid unlock policy",
+		"unlock.not_found":           "This level was not unlocked for the student",
+		"class.frozen":               "Your teacher paused all runs, eyes on the teacher",
+		"class.level_restricted":     "Your teacher asked the class to focus on another level",
+		"class.sandbox_disabled":     "Your teacher turned off the sandbox for now",
+		"controls.invalid":           "Invalid classroom controls",
+		"class.not_teacher":          "You are not the teacher of this class",
+		"class.invalid":              "Invalid class: {field}",
+		"class.archived":             "This class is archived",
+		"invite.invalid":             "Invalid invite settings: {field}",
+		"invite.expired":             "This invite code has expired; ask your teacher for a new one",
+		"invite.exhausted":           "This invite code has been used up; ask your teacher for a new one",
+		"roster.invalid":             "Invalid roster file",
+		"student.not_found":          "Student not found",
+		"course.not_found":           "Course not found",
+		"work.not_found":             "Work not found",
+		"review.unsupported_status":  "Unsupported review status",
+		"review.invalid_stars":       "Stars must be between 0 and 3",
+		"work.not_pending":           "This work was already reviewed; wait for the student to resubmit",
+		"review.invalid":             "The scores do not match the rubric",
+		"rubric.not_found":           "Rubric not found",
+		"rubric.invalid":             "The rubric did not pass validation",
+		"assignment.not_found":       "Assignment not found",
+		"assignment.invalid":         "The assignment did not pass validation",
+		"assignment.closed":          "This assignment is closed",
+		"assessment.not_found":       "This assignment is not an assessment",
+		"assessment.not_started":     "Start the assessment first",
+		"assessment.ended":           "The assessment has ended",
+		"assessment.run_limit":       "No runs left for this level",
+		"assessment.in_progress":     "Not available while the assessment is running",
+		"assessment.hints_disabled":  "Hints are disabled during the assessment",
+		"assessment.not_ended":       "Results can only be released once the assessment has ended",
+		"assessment.released":        "The results have already been released",
+		"analytics.unknown_resource": "Unknown analytics resource",
+		"analytics.invalid_filter":   "Invalid analytics filter",
+		"content.not_owner":          "This content belongs to another teacher",
+
+		"export.invalid":     "Invalid export parameter: {field}",
+		"export.not_found":   "Export not found",
+		"export.not_ready":   "The export file is not ready yet",
+		"export.expired":     "The export file has expired, please export again",
+		"export.too_many":    "Too many exports in progress, please try again later",
+		"export.unavailable": "Exports are unavailable right now, please try again later",
+
+		"levelpack.invalid":            "Some levels in the pack failed validation",
+		"levelpack.invalid_schema":     "The level pack is incomplete",
+		"levelpack.unsupported_format": "Unsupported level pack format",
+		"levelpack.unsupported_schema": "Unsupported level pack schema version",
+		"levelpack.malformed":          "The level pack could not be parsed",
+
+		"parent.not_found": "Parent profile not found",
+		"child.not_found":  "Child profile not found",
+	},
+}
+
+func lookup(language, code string) string {
+	if message, ok := messages[language][code]; ok {
+		return message
+	}
+	if message, ok := messages[DefaultLanguage][code]; ok {
+		return message
+	}
+	return code
+}
+
+// MatchLanguage returns the supported language that best fits a language tag
+// such as "en-US" or "zh-Hans", or "" when none does.
+func MatchLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	switch {
+	case tag == "":
+		return ""
+	case tag == "zh" || strings.HasPrefix(tag, "zh-") || strings.HasPrefix(tag, "zh_"):
+		return LanguageChinese
+	case tag == "en" || strings.HasPrefix(tag, "en-") || strings.HasPrefix(tag, "en_"):
+		return LanguageEnglish
+	default:
+		return ""
+	}
+}
+
+// NegotiateLanguage picks the supported language preferred by an
+// Accept-Language header, or "" when it names none.
+func NegotiateLanguage(header string) string {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+	for _, c := range candidates {
+		if language := MatchLanguage(c.tag); language != "" {
+			return language
+		}
+	}
+	return ""
+}
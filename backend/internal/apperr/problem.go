@@ -0,0 +1,62 @@
+package apperr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem is one reason a definition was rejected, listed in the problems
+// detail of validation errors. Code is stable so that clients can word it
+// in their own language, Path names the offending field and Params holds the
+// values the wording refers to.
+type Problem struct {
+	Path   string         `json:"path,omitempty"`
+	Code   string         `json:"code"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// NewProblem returns a problem of the field at path. Params are given as
+// alternating names and values.
+func NewProblem(path, code string, params ...any) Problem {
+	problem := Problem{Path: path, Code: code}
+	if len(params) > 1 {
+		problem.Params = make(map[string]any, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			problem.Params[fmt.Sprint(params[i])] = params[i+1]
+		}
+	}
+	return problem
+}
+
+// String formats the problem for logs and command line output, for example
+// "tiles: out_of_bounds (x=3, y=9)".
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Path != "" {
+		b.WriteString(p.Path + ": ")
+	}
+	b.WriteString(p.Code)
+	if len(p.Params) > 0 {
+		keys := make([]string, 0, len(p.Params))
+		for key := range p.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = fmt.Sprintf("%s=%v", key, p.Params[key])
+		}
+		b.WriteString(" (" + strings.Join(pairs, ", ") + ")")
+	}
+	return b.String()
+}
+
+// JoinProblems formats problems as one line for error messages.
+func JoinProblems(problems []Problem) string {
+	parts := make([]string, len(problems))
+	for i, problem := range problems {
+		parts[i] = problem.String()
+	}
+	return strings.Join(parts, "; ")
+}
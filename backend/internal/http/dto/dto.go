@@ -39,9 +39,23 @@ type CredentialAuthResponse struct {
 	User AuthResponse `json:"user"`
 }
 
-// StudentRunRequest describes a block-program execution request.
+// StudentRunRequest describes a program execution request. The program is
+// given either as blocks or as text in Source.
 type StudentRunRequest struct {
-	Program []service.Instruction `json:"program" validate:"required,min=1,dive"`
+	Program  []service.Instruction `json:"program" validate:"required_without=Source,dive"`
+	Source   string                `json:"source" validate:"required_without=Program"`
+	Duration *int                  `json:"duration" validate:"omitempty,min=0"`
+}
+
+// ToDomain converts the DTO into the service run request.
+func (r StudentRunRequest) ToDomain() service.RunRequest {
+	return service.RunRequest{Program: r.Program, Source: r.Source, Duration: r.Duration}
+}
+
+// ProgramConvertRequest carries a program in one of its two forms.
+type ProgramConvertRequest struct {
+	Program []service.Instruction `json:"program" validate:"required_without=Source,dive"`
+	Source  *string               `json:"source" validate:"required_without=Program"`
 }
 
 // StudentCompleteRequest records a completion event for a level.
@@ -90,11 +104,51 @@ func (r StudentSettingsUpdateRequest) ToDomain() service.SettingsUpdate {
 }
 
 // StudentAvatarUpdateRequest equips a new avatar for the student.
+// Either an item to equip or a slot to empty is required.
 type StudentAvatarUpdateRequest struct {
-	Equipped string `json:"equipped" validate:"required"`
+	Equipped string `json:"equipped" validate:"required_without=Unequip"`
+	Unequip  string `json:"unequip" validate:"omitempty,oneof=head cape pet"`
 }
 
 // ToDomain converts the DTO to service payload.
 func (r StudentAvatarUpdateRequest) ToDomain() service.AvatarUpdate {
-	return service.AvatarUpdate{Equipped: r.Equipped}
+	return service.AvatarUpdate{Equipped: r.Equipped, Unequip: service.AvatarSlot(r.Unequip)}
+}
+
+// ShopPurchaseRequest buys an avatar item.
+type ShopPurchaseRequest struct {
+	ItemID string `json:"itemId" validate:"required"`
+}
+
+// SandboxProjectRequest saves a sandbox project on a level or a custom map.
+// The program is given either as blocks or as text in Source.
+type SandboxProjectRequest struct {
+	Title   string                `json:"title"`
+	LevelID string                `json:"levelId" validate:"required_without=Map"`
+	Map     *service.ProjectMap   `json:"map" validate:"required_without=LevelID"`
+	Program []service.Instruction `json:"program" validate:"omitempty,dive"`
+	Source  string                `json:"source"`
+}
+
+// ToDomain converts the DTO into the service project input.
+func (r SandboxProjectRequest) ToDomain() service.ProjectInput {
+	return service.ProjectInput{Title: r.Title, LevelID: r.LevelID, Map: r.Map, Program: r.Program}
+}
+
+// WorkSubmitRequest hands in a level solution or a sandbox project, optionally
+// for a task of an assignment. A level solution is given either as blocks or
+// as text in Source.
+type WorkSubmitRequest struct {
+	Title        string                `json:"title"`
+	LevelID      string                `json:"levelId" validate:"required_without=ProjectID"`
+	ProjectID    string                `json:"projectId" validate:"required_without=LevelID"`
+	Program      []service.Instruction `json:"program" validate:"omitempty,dive"`
+	Source       string                `json:"source"`
+	AssignmentID string                `json:"assignmentId" validate:"required_with=ItemID"`
+	ItemID       string                `json:"itemId" validate:"required_with=AssignmentID"`
+}
+
+// ToDomain converts the DTO into the service work input.
+func (r WorkSubmitRequest) ToDomain() service.WorkInput {
+	return service.WorkInput{Title: r.Title, LevelID: r.LevelID, ProjectID: r.ProjectID, Program: r.Program, AssignmentID: r.AssignmentID, ItemID: r.ItemID}
 }
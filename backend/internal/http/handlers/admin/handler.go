@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	studentService "github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// Handler exposes platform administration endpoints.
+type Handler struct {
+	students *studentService.Service
+	log      *zap.Logger
+}
+
+// New constructs the handler.
+func New(students *studentService.Service, log *zap.Logger) *Handler {
+	return &Handler{students: students, log: log}
+}
+
+func (h *Handler) adminID(c *gin.Context) string {
+	if header := c.GetHeader("x-user-id"); header != "" {
+		return header
+	}
+	return "admin-demo"
+}
+
+// AvatarItems lists the avatar catalog including items that are not sold.
+func (h *Handler) AvatarItems(c *gin.Context) {
+	c.JSON(http.StatusOK, h.students.AvatarItems(c.Request.Context()))
+}
+
+// SaveAvatarItem creates or replaces a catalog item.
+func (h *Handler) SaveAvatarItem(c *gin.Context) {
+	var payload studentService.AvatarItem
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid avatar item payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	payload.ID = c.Param("itemId")
+	item, err := h.students.SaveAvatarItem(c.Request.Context(), payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteAvatarItem removes a catalog item.
+func (h *Handler) DeleteAvatarItem(c *gin.Context) {
+	if err := h.students.DeleteAvatarItem(c.Request.Context(), c.Param("itemId")); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// GrantAvatarItem gives an avatar item to any student.
+func (h *Handler) GrantAvatarItem(c *gin.Context) {
+	studentID := c.Param("studentId")
+	var payload struct {
+		ItemID string `json:"itemId"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.ItemID == "" {
+		h.log.Warn("invalid avatar grant payload", zap.Error(err))
+		c.Error(httperr.InvalidField("itemId", err))
+		return
+	}
+	entry, err := h.students.GrantAvatarItem(c.Request.Context(), studentID, payload.ItemID, h.adminID(c), payload.Reason)
+	if err != nil {
+		h.log.Warn("failed to grant avatar item", zap.String("student_id", studentID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// StudentWallet returns the balances and transactions of any student.
+func (h *Handler) StudentWallet(c *gin.Context) {
+	c.JSON(http.StatusOK, h.students.Ledger(c.Request.Context(), c.Param("studentId")))
+}
@@ -1,7 +1,6 @@
 package auth
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/codeadventurers/api-go/internal/http/dto"
+	"github.com/codeadventurers/api-go/internal/http/httperr"
 	service "github.com/codeadventurers/api-go/internal/service/auth"
 )
 
@@ -39,7 +39,7 @@ func (h *Handler) Guest(c *gin.Context) {
 	profile, err := h.service.GuestLogin(c.Request.Context(), req.Name)
 	if err != nil {
 		h.log.Error("guest login failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	h.log.Info("guest login succeeded", zap.String("user_id", profile.UserID))
@@ -69,7 +69,7 @@ func (h *Handler) Class(c *gin.Context) {
 
 func (h *Handler) respondValidationError(c *gin.Context, err error) {
 	h.log.Warn("authentication request validation failed", zap.String("path", c.FullPath()), zap.Error(err))
-	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	c.Error(httperr.Invalid(err))
 }
 
 // Login handles credential based login for teachers、家长等角色.
@@ -120,18 +120,6 @@ func (h *Handler) fromProfile(profile service.Profile) dto.AuthResponse {
 }
 
 func (h *Handler) respondAuthError(c *gin.Context, err error, contextRole string, identifier string) {
-	switch {
-	case errors.Is(err, service.ErrInvalidCredentials):
-		h.log.Warn("authentication failed", zap.String("role", contextRole), zap.String("identifier", identifier), zap.Error(err))
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "账号或密码不正确"})
-	case errors.Is(err, service.ErrInvalidInviteCode):
-		h.log.Warn("invalid invite code", zap.String("code", identifier), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "班级邀请码无效"})
-	case errors.Is(err, service.ErrUnknownRole):
-		h.log.Warn("unknown role supplied", zap.String("role", contextRole), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的角色类型"})
-	default:
-		h.log.Error("authentication failure", zap.String("context", contextRole), zap.String("identifier", identifier), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "登录失败，请稍后再试"})
-	}
+	h.log.Warn("authentication failed", zap.String("role", contextRole), zap.String("identifier", identifier), zap.Error(err))
+	c.Error(err)
 }
@@ -0,0 +1,57 @@
+package parent
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	exportService "github.com/codeadventurers/api-go/internal/service/export"
+)
+
+// Exports lists the exports the parent requested, newest first.
+func (h *Handler) Exports(c *gin.Context) {
+	records := h.exports.List(c.Request.Context(), exportService.RoleParent, h.parentID(c))
+	c.JSON(http.StatusOK, gin.H{"exports": records})
+}
+
+// CreateExport queues a progress or attempt history export of a child.
+func (h *Handler) CreateExport(c *gin.Context) {
+	var payload exportService.Request
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid export payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	record, err := h.exports.Create(c.Request.Context(), exportService.RoleParent, h.parentID(c), payload)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, record)
+}
+
+// Export returns the status of one export.
+func (h *Handler) Export(c *gin.Context) {
+	record, err := h.exports.Get(c.Request.Context(), exportService.RoleParent, h.parentID(c), c.Param("exportId"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// DownloadExport streams the file of a completed export until it expires.
+func (h *Handler) DownloadExport(c *gin.Context) {
+	record, file, err := h.exports.Open(c.Request.Context(), exportService.RoleParent, h.parentID(c), c.Param("exportId"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer file.Close()
+	c.DataFromReader(http.StatusOK, int64(record.Size), record.ContentType(), file, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", record.FileName),
+	})
+}
@@ -2,22 +2,26 @@ package parent
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	exportService "github.com/codeadventurers/api-go/internal/service/export"
 	service "github.com/codeadventurers/api-go/internal/service/parent"
 )
 
 // Handler coordinates parent dashboard HTTP endpoints.
 type Handler struct {
 	service *service.Service
+	exports *exportService.Service
 	log     *zap.Logger
 }
 
 // New constructs the handler.
-func New(service *service.Service, log *zap.Logger) *Handler {
-	return &Handler{service: service, log: log}
+func New(service *service.Service, exports *exportService.Service, log *zap.Logger) *Handler {
+	return &Handler{service: service, exports: exports, log: log}
 }
 
 func (h *Handler) parentID(c *gin.Context) string {
@@ -69,6 +73,70 @@ func (h *Handler) Progress(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"progress": result})
 }
 
+// Activity returns a child's streak and activity calendar.
+func (h *Handler) Activity(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "0"))
+	if err != nil {
+		h.log.Warn("invalid activity days", zap.Error(err))
+		c.Error(httperr.InvalidField("days", err))
+		return
+	}
+	result, err := h.service.Activity(c.Request.Context(), h.parentID(c), c.Param("childId"), days)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Attempts lists a child's attempts on a level.
+func (h *Handler) Attempts(c *gin.Context) {
+	childID := c.Param("childId")
+	levelID := c.Param("levelId")
+	result, err := h.service.Attempts(c.Request.Context(), h.parentID(c), childID, levelID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"attempts": result})
+}
+
+// Attempt returns a single attempt of a child.
+func (h *Handler) Attempt(c *gin.Context) {
+	childID := c.Param("childId")
+	attemptID := c.Param("attemptId")
+	result, err := h.service.Attempt(c.Request.Context(), h.parentID(c), childID, attemptID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Replay returns a page of re-simulated replay frames for a child's attempt.
+func (h *Handler) Replay(c *gin.Context) {
+	childID := c.Param("childId")
+	attemptID := c.Param("attemptId")
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		h.log.Warn("invalid replay offset", zap.Error(err))
+		c.Error(httperr.InvalidField("offset", err))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.log.Warn("invalid replay limit", zap.Error(err))
+		c.Error(httperr.InvalidField("limit", err))
+		return
+	}
+	result, err := h.service.Replay(c.Request.Context(), h.parentID(c), childID, attemptID, offset, limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 // Settings returns notification preferences.
 func (h *Handler) Settings(c *gin.Context) {
 	result, err := h.service.Settings(c.Request.Context(), h.parentID(c))
@@ -88,7 +156,7 @@ func (h *Handler) UpdateSettings(c *gin.Context) {
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		h.log.Warn("invalid parent settings payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求格式不正确"})
+		c.Error(httperr.Invalid(err))
 		return
 	}
 
@@ -105,17 +173,8 @@ func (h *Handler) UpdateSettings(c *gin.Context) {
 }
 
 func (h *Handler) handleError(c *gin.Context, err error) {
-	switch err {
-	case service.ErrParentNotFound:
-		h.log.Warn("parent profile not found", zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "未找到家长信息"})
-	case service.ErrChildNotFound:
-		h.log.Warn("child profile not found", zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "未找到孩子信息"})
-	default:
-		h.log.Error("parent request failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "服务器异常，请稍后再试"})
-	}
+	h.log.Warn("parent request failed", zap.String("parent_id", h.parentID(c)), zap.Error(err))
+	c.Error(err)
 }
 
 
@@ -1,13 +1,18 @@
 package student
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 
 	"github.com/codeadventurers/api-go/internal/http/dto"
+	"github.com/codeadventurers/api-go/internal/http/httperr"
 	"github.com/codeadventurers/api-go/internal/jobs"
 	service "github.com/codeadventurers/api-go/internal/service/student"
 )
@@ -25,6 +30,15 @@ func New(service *service.Service, jobs *jobs.Dispatcher, validate *validator.Va
 	return &Handler{service: service, jobs: jobs, validate: validate, log: log}
 }
 
+// Language returns the language stored in the settings of the calling
+// student, used to localize error messages on student endpoints.
+func (h *Handler) Language(c *gin.Context) string {
+	if !strings.HasPrefix(c.Request.URL.Path, "/api/student") {
+		return ""
+	}
+	return h.service.Language(c.Request.Context(), h.userID(c))
+}
+
 func (h *Handler) userID(c *gin.Context) string {
 	if header := c.GetHeader("x-user-id"); header != "" {
 		return header
@@ -42,7 +56,7 @@ func (h *Handler) Profile(c *gin.Context) {
 	profile, err := h.service.Profile(c.Request.Context(), userID)
 	if err != nil {
 		h.log.Error("failed to fetch profile", zap.String("user_id", userID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, profile)
@@ -55,7 +69,7 @@ func (h *Handler) Map(c *gin.Context) {
 	result, err := h.service.Map(c.Request.Context(), userID)
 	if err != nil {
 		h.log.Error("failed to fetch student map", zap.String("user_id", userID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, result)
@@ -69,7 +83,7 @@ func (h *Handler) Level(c *gin.Context) {
 	result, err := h.service.Level(c.Request.Context(), userID, levelID)
 	if err != nil {
 		h.log.Warn("failed to fetch level detail", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, result)
@@ -83,7 +97,7 @@ func (h *Handler) Prep(c *gin.Context) {
 	result, err := h.service.Prep(c.Request.Context(), userID, levelID)
 	if err != nil {
 		h.log.Error("failed to fetch level prep", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, result)
@@ -105,10 +119,10 @@ func (h *Handler) Run(c *gin.Context) {
 	}
 
 	h.log.Info("processing level run", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Int("blocks", len(req.Program)))
-	result, err := h.service.Run(c.Request.Context(), userID, levelID, req.Program)
+	result, err := h.service.Run(c.Request.Context(), userID, levelID, req.ToDomain())
 	if err != nil {
 		h.log.Warn("level run failed", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, result)
@@ -130,12 +144,13 @@ func (h *Handler) Complete(c *gin.Context) {
 	}
 
 	h.log.Info("marking level complete", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Int("stars", req.Stars))
-	if err := h.service.Complete(c.Request.Context(), userID, levelID, req.ToDomain()); err != nil {
+	result, err := h.service.Complete(c.Request.Context(), userID, levelID, req.ToDomain())
+	if err != nil {
 		h.log.Error("failed to mark level complete", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "newBadges": result.NewBadges, "newCompendium": result.NewCompendium})
 }
 
 // Sandbox executes code in sandbox mode without affecting progress.
@@ -153,11 +168,16 @@ func (h *Handler) Sandbox(c *gin.Context) {
 		return
 	}
 
+	program, ok := h.programFromRequest(c, req.Program, req.Source)
+	if !ok {
+		return
+	}
+
 	h.log.Info("running sandbox", zap.String("user_id", userID), zap.String("level_id", levelID))
-	result, err := h.service.Sandbox(c.Request.Context(), userID, levelID, req.Program)
+	result, err := h.service.Sandbox(c.Request.Context(), userID, levelID, program)
 	if err != nil {
 		h.log.Warn("sandbox run failed", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, result)
@@ -180,8 +200,13 @@ func (h *Handler) Hint(c *gin.Context) {
 
 	result, err := h.service.Hint(c.Request.Context(), userID, levelID, req.ToDomain())
 	if err != nil {
-		h.log.Warn("failed to compute hint", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		var limitErr *service.HintLimitError
+		if errors.As(err, &limitErr) {
+			h.log.Info("hint limit reached", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Time("next_hint_at", limitErr.NextHintAt))
+		} else {
+			h.log.Warn("failed to compute hint", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Error(err))
+		}
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, result)
@@ -193,7 +218,7 @@ func (h *Handler) Settings(c *gin.Context) {
 	result, err := h.service.Settings(c.Request.Context(), userID)
 	if err != nil {
 		h.log.Error("failed to fetch settings", zap.String("user_id", userID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, result)
@@ -217,7 +242,7 @@ func (h *Handler) UpdateSettings(c *gin.Context) {
 	updated, err := h.service.UpdateSettings(c.Request.Context(), userID, req.ToDomain())
 	if err != nil {
 		h.log.Error("failed to update settings", zap.String("user_id", userID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, updated)
@@ -228,7 +253,7 @@ func (h *Handler) ResetProgress(c *gin.Context) {
 	userID := h.userID(c)
 	if err := h.service.ResetProgress(c.Request.Context(), userID); err != nil {
 		h.log.Error("failed to reset progress", zap.String("user_id", userID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
@@ -240,12 +265,38 @@ func (h *Handler) Avatar(c *gin.Context) {
 	state, err := h.service.Avatar(c.Request.Context(), userID)
 	if err != nil {
 		h.log.Error("failed to fetch avatar", zap.String("user_id", userID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, state)
 }
 
+// Activity returns the student's streak and activity calendar.
+func (h *Handler) Activity(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "0"))
+	if err != nil {
+		h.log.Warn("invalid activity days", zap.Error(err))
+		c.Error(httperr.InvalidField("days", err))
+		return
+	}
+	c.JSON(http.StatusOK, h.service.Activity(c.Request.Context(), h.userID(c), days))
+}
+
+// Heartbeat records that the student is actively playing.
+func (h *Handler) Heartbeat(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Heartbeat(c.Request.Context(), h.userID(c)))
+}
+
+// Badges lists every badge with whether the student has earned it.
+func (h *Handler) Badges(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.BadgeStatuses(c.Request.Context(), h.userID(c)))
+}
+
+// Compendium lists the compendium entries of the student's chapters.
+func (h *Handler) Compendium(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.StudentCompendium(c.Request.Context(), h.userID(c)))
+}
+
 // UpdateAvatar equips a new avatar for the student.
 func (h *Handler) UpdateAvatar(c *gin.Context) {
 	userID := h.userID(c)
@@ -263,13 +314,370 @@ func (h *Handler) UpdateAvatar(c *gin.Context) {
 	state, err := h.service.UpdateAvatar(c.Request.Context(), userID, req.ToDomain())
 	if err != nil {
 		h.log.Warn("failed to update avatar", zap.String("user_id", userID), zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, state)
 }
 
+// Shop lists the avatar catalog with the student's wallet.
+func (h *Handler) Shop(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Shop(c.Request.Context(), h.userID(c)))
+}
+
+// Purchase buys an avatar item. Clients may send an Idempotency-Key header
+// to make retries safe.
+func (h *Handler) Purchase(c *gin.Context) {
+	userID := h.userID(c)
+
+	var req dto.ShopPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+
+	result, err := h.service.Purchase(c.Request.Context(), userID, req.ItemID, c.GetHeader("Idempotency-Key"))
+	if err != nil {
+		h.log.Warn("failed to purchase avatar item", zap.String("user_id", userID), zap.String("item_id", req.ItemID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Wallet returns the student's balances and transactions.
+func (h *Handler) Wallet(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Ledger(c.Request.Context(), h.userID(c)))
+}
+
+// Projects lists the student's sandbox projects.
+func (h *Handler) Projects(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Projects(c.Request.Context(), h.userID(c)))
+}
+
+// Gallery lists the projects published in the student's class.
+func (h *Handler) Gallery(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Gallery(c.Request.Context(), h.userID(c)))
+}
+
+// Project returns an own project or one from the class gallery.
+func (h *Handler) Project(c *gin.Context) {
+	project, err := h.service.Project(c.Request.Context(), h.userID(c), c.Param("projectId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// CreateProject saves a new sandbox project.
+func (h *Handler) CreateProject(c *gin.Context) {
+	userID := h.userID(c)
+	input, ok := h.bindProject(c)
+	if !ok {
+		return
+	}
+	project, err := h.service.CreateProject(c.Request.Context(), userID, input)
+	if err != nil {
+		h.log.Warn("failed to create project", zap.String("user_id", userID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, project)
+}
+
+// UpdateProject replaces an own sandbox project.
+func (h *Handler) UpdateProject(c *gin.Context) {
+	userID := h.userID(c)
+	projectID := c.Param("projectId")
+	input, ok := h.bindProject(c)
+	if !ok {
+		return
+	}
+	project, err := h.service.UpdateProject(c.Request.Context(), userID, projectID, input)
+	if err != nil {
+		h.log.Warn("failed to update project", zap.String("user_id", userID), zap.String("project_id", projectID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteProject removes an own sandbox project.
+func (h *Handler) DeleteProject(c *gin.Context) {
+	if err := h.service.DeleteProject(c.Request.Context(), h.userID(c), c.Param("projectId")); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ForkProject copies a project into a new one of the student.
+func (h *Handler) ForkProject(c *gin.Context) {
+	project, err := h.service.ForkProject(c.Request.Context(), h.userID(c), c.Param("projectId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, project)
+}
+
+// PublishProject submits an own project to the class gallery.
+func (h *Handler) PublishProject(c *gin.Context) {
+	project, err := h.service.PublishProject(c.Request.Context(), h.userID(c), c.Param("projectId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// UnpublishProject takes an own project out of the class gallery.
+func (h *Handler) UnpublishProject(c *gin.Context) {
+	project, err := h.service.UnpublishProject(c.Request.Context(), h.userID(c), c.Param("projectId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// RunProject runs a project's saved program, or the program in the body.
+func (h *Handler) RunProject(c *gin.Context) {
+	userID := h.userID(c)
+	projectID := c.Param("projectId")
+
+	var req struct {
+		Program []service.Instruction `json:"program"`
+		Source  string                `json:"source"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondValidationError(c, err)
+		return
+	}
+	program, ok := h.programFromRequest(c, req.Program, req.Source)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.RunProject(c.Request.Context(), userID, projectID, program)
+	if err != nil {
+		h.log.Warn("project run failed", zap.String("user_id", userID), zap.String("project_id", projectID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// SubmitWork hands a level solution or sandbox project in for review.
+func (h *Handler) SubmitWork(c *gin.Context) {
+	userID := h.userID(c)
+
+	var req dto.WorkSubmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+	program, ok := h.programFromRequest(c, req.Program, req.Source)
+	if !ok {
+		return
+	}
+	req.Program = program
+
+	work, err := h.service.SubmitWork(c.Request.Context(), userID, req.ToDomain())
+	if err != nil {
+		h.log.Warn("failed to submit work", zap.String("user_id", userID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, work)
+}
+
+// Works lists the student's submissions and their review state.
+func (h *Handler) Works(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"works": h.service.Works(c.Request.Context(), h.userID(c))})
+}
+
+// Work returns one of the student's submissions.
+func (h *Handler) Work(c *gin.Context) {
+	work, err := h.service.Work(c.Request.Context(), h.userID(c), c.Param("workId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, work)
+}
+
+// Assignments lists the assignments of the student's class with their
+// completion.
+func (h *Handler) Assignments(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"assignments": h.service.StudentAssignments(c.Request.Context(), h.userID(c))})
+}
+
+// Assignment returns one assignment of the student's class with their
+// completion.
+func (h *Handler) Assignment(c *gin.Context) {
+	assignment, err := h.service.StudentAssignment(c.Request.Context(), h.userID(c), c.Param("assignmentId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// Assessment returns the student's state on an assessment with its
+// countdown.
+func (h *Handler) Assessment(c *gin.Context) {
+	assessment, err := h.service.StudentAssessment(c.Request.Context(), h.userID(c), c.Param("assignmentId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, assessment)
+}
+
+// StartAssessment starts the student's countdown on an assessment.
+func (h *Handler) StartAssessment(c *gin.Context) {
+	userID := h.userID(c)
+	assessment, err := h.service.StartAssessment(c.Request.Context(), userID, c.Param("assignmentId"))
+	if err != nil {
+		h.log.Warn("failed to start assessment", zap.String("user_id", userID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, assessment)
+}
+
+// SubmitAssessment hands in the student's final program for a level of an
+// assessment.
+func (h *Handler) SubmitAssessment(c *gin.Context) {
+	levelID := c.Param("levelId")
+	userID := h.userID(c)
+
+	var req dto.StudentRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+
+	program, ok := h.programFromRequest(c, req.Program, req.Source)
+	if !ok {
+		return
+	}
+
+	assessment, err := h.service.SubmitAssessment(c.Request.Context(), userID, c.Param("assignmentId"), levelID, program)
+	if err != nil {
+		h.log.Warn("failed to submit assessment", zap.String("user_id", userID), zap.String("level_id", levelID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, assessment)
+}
+
+// FinishAssessment hands the student's assessment in before the time is up.
+func (h *Handler) FinishAssessment(c *gin.Context) {
+	assessment, err := h.service.FinishAssessment(c.Request.Context(), h.userID(c), c.Param("assignmentId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, assessment)
+}
+
+// Notifications returns the student's inbox.
+func (h *Handler) Notifications(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Notifications(c.Request.Context(), h.userID(c)))
+}
+
+// ReadNotifications marks the listed notifications, or all when ids is
+// omitted, as read.
+func (h *Handler) ReadNotifications(c *gin.Context) {
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondValidationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, h.service.MarkNotificationsRead(c.Request.Context(), h.userID(c), req.IDs))
+}
+
+// bindProject reads a project payload, parsing a text program if no blocks
+// are given. It reports false after responding with the error.
+func (h *Handler) bindProject(c *gin.Context) (service.ProjectInput, bool) {
+	var req dto.SandboxProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondValidationError(c, err)
+		return service.ProjectInput{}, false
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.respondValidationError(c, err)
+		return service.ProjectInput{}, false
+	}
+	program, ok := h.programFromRequest(c, req.Program, req.Source)
+	if !ok {
+		return service.ProjectInput{}, false
+	}
+	req.Program = program
+	return req.ToDomain(), true
+}
+
+// ConvertProgram translates a program between its block and text forms and
+// returns both, with the text in canonical formatting.
+func (h *Handler) ConvertProgram(c *gin.Context) {
+	var req dto.ProgramConvertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.respondValidationError(c, err)
+		return
+	}
+
+	program := req.Program
+	if req.Source != nil {
+		parsed, ok := h.programFromRequest(c, nil, *req.Source)
+		if !ok {
+			return
+		}
+		program = parsed
+	}
+	source, err := service.FormatProgram(program)
+	if err != nil {
+		h.log.Warn("program has no text form", zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"program": program, "source": source})
+}
+
+// programFromRequest returns the blocks of a request, parsing the text
+// program when no blocks are given. It reports false after responding with
+// the error.
+func (h *Handler) programFromRequest(c *gin.Context, program []service.Instruction, source string) ([]service.Instruction, bool) {
+	program, err := service.ProgramFromSource(program, source)
+	if err != nil {
+		h.log.Warn("invalid program text", zap.String("path", c.FullPath()), zap.Error(err))
+		c.Error(err)
+		return nil, false
+	}
+	return program, true
+}
+
 func (h *Handler) respondValidationError(c *gin.Context, err error) {
 	h.log.Warn("student request validation failed", zap.String("path", c.FullPath()), zap.Error(err))
-	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	c.Error(httperr.Invalid(err))
 }
@@ -0,0 +1,98 @@
+package teacher
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	studentService "github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// CreateAssignment gives an assignment to a class.
+func (h *Handler) CreateAssignment(c *gin.Context) {
+	classID := c.Param("classId")
+	var payload studentService.AssignmentInput
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid assignment payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	assignment, err := h.service.CreateAssignment(c.Request.Context(), h.teacherID(c), classID, payload)
+	if err != nil {
+		h.respondAssignmentError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// ClassAssignments lists the assignments of a class, optionally filtered by
+// the status query parameter.
+func (h *Handler) ClassAssignments(c *gin.Context) {
+	classID := c.Param("classId")
+	status := studentService.AssignmentStatus(c.Query("status"))
+	switch status {
+	case "", studentService.AssignmentOpen, studentService.AssignmentClosed:
+	default:
+		c.Error(httperr.InvalidField("status", nil))
+		return
+	}
+	assignments, err := h.service.ClassAssignments(c.Request.Context(), h.teacherID(c), classID, status)
+	if err != nil {
+		h.respondAssignmentError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"assignments": assignments})
+}
+
+// AssignmentMatrix returns an assignment with the completion of every
+// student of the class.
+func (h *Handler) AssignmentMatrix(c *gin.Context) {
+	classID := c.Param("classId")
+	matrix, err := h.service.AssignmentMatrix(c.Request.Context(), h.teacherID(c), classID, c.Param("assignmentId"))
+	if err != nil {
+		h.respondAssignmentError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, matrix)
+}
+
+// CloseAssignment stops an assignment from accepting work.
+func (h *Handler) CloseAssignment(c *gin.Context) {
+	classID := c.Param("classId")
+	assignment, err := h.service.CloseAssignment(c.Request.Context(), h.teacherID(c), classID, c.Param("assignmentId"))
+	if err != nil {
+		h.respondAssignmentError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// AssessmentResults returns the submissions and scores of every student of
+// the class on an assessment.
+func (h *Handler) AssessmentResults(c *gin.Context) {
+	classID := c.Param("classId")
+	results, err := h.service.AssessmentResults(c.Request.Context(), h.teacherID(c), classID, c.Param("assignmentId"))
+	if err != nil {
+		h.respondAssignmentError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// ReleaseAssessment shows students their assessment results.
+func (h *Handler) ReleaseAssessment(c *gin.Context) {
+	classID := c.Param("classId")
+	assignment, err := h.service.ReleaseAssessment(c.Request.Context(), h.teacherID(c), classID, c.Param("assignmentId"))
+	if err != nil {
+		h.respondAssignmentError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+func (h *Handler) respondAssignmentError(c *gin.Context, err error, classID string) {
+	h.log.Warn("assignment operation failed", zap.String("class_id", classID), zap.Error(err))
+	c.Error(err)
+}
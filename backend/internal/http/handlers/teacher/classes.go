@@ -0,0 +1,159 @@
+package teacher
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	service "github.com/codeadventurers/api-go/internal/service/teacher"
+)
+
+// maxRosterSize caps the size of uploaded roster files.
+const maxRosterSize = 1 << 20
+
+// CreateClass creates a class owned by the teacher.
+func (h *Handler) CreateClass(c *gin.Context) {
+	var payload service.ClassInput
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid class payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	detail, err := h.service.CreateClass(c.Request.Context(), h.teacherID(c), payload)
+	if err != nil {
+		h.respondClassError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusCreated, detail)
+}
+
+// UpdateClass renames, reconfigures, archives or restores a class.
+func (h *Handler) UpdateClass(c *gin.Context) {
+	classID := c.Param("classId")
+	var payload service.ClassUpdate
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid class update payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	detail, err := h.service.UpdateClass(c.Request.Context(), h.teacherID(c), classID, payload)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// RegenerateInvite gives a class a new invite code with optional max uses and
+// expiry. The body may be empty for an unlimited code.
+func (h *Handler) RegenerateInvite(c *gin.Context) {
+	classID := c.Param("classId")
+	var payload service.InviteSettings
+	if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Warn("invalid invite payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	invite, err := h.service.RegenerateInvite(c.Request.Context(), h.teacherID(c), classID, payload)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, invite)
+}
+
+// ExpireInvite stops the invite code of a class from admitting students.
+func (h *Handler) ExpireInvite(c *gin.Context) {
+	classID := c.Param("classId")
+	invite, err := h.service.ExpireInvite(c.Request.Context(), h.teacherID(c), classID)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, invite)
+}
+
+// ImportRoster adds the students of a CSV file sent as the request body to a
+// class; dryRun=true only reports who would be added.
+func (h *Handler) ImportRoster(c *gin.Context) {
+	classID := c.Param("classId")
+	dryRun := c.Query("dryRun") == "true"
+	report, err := h.service.ImportRoster(c.Request.Context(), h.teacherID(c), classID, io.LimitReader(c.Request.Body, maxRosterSize), dryRun)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
+
+// RemoveStudent takes a student off the class roster.
+func (h *Handler) RemoveStudent(c *gin.Context) {
+	classID := c.Param("classId")
+	if err := h.service.RemoveStudent(c.Request.Context(), h.teacherID(c), classID, c.Param("studentId")); err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// TransferStudent moves a student to another class.
+func (h *Handler) TransferStudent(c *gin.Context) {
+	classID := c.Param("classId")
+	var payload struct {
+		TargetClassID string `json:"targetClassId"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.TargetClassID == "" {
+		h.log.Warn("invalid transfer payload", zap.Error(err))
+		c.Error(httperr.InvalidField("targetClassId", err))
+		return
+	}
+	member, err := h.service.TransferStudent(c.Request.Context(), h.teacherID(c), classID, c.Param("studentId"), payload.TargetClassID)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"classId": payload.TargetClassID, "student": member})
+}
+
+// ClassControls returns the live controls of a class.
+func (h *Handler) ClassControls(c *gin.Context) {
+	classID := c.Param("classId")
+	controls, err := h.service.ClassControls(c.Request.Context(), h.teacherID(c), classID)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, controls)
+}
+
+// UpdateClassControls freezes runs, restricts levels, disables the sandbox or
+// shows a banner in a class. The body replaces all controls; an empty object
+// lifts them.
+func (h *Handler) UpdateClassControls(c *gin.Context) {
+	classID := c.Param("classId")
+	var payload service.ClassControls
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid class controls payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	controls, err := h.service.UpdateClassControls(c.Request.Context(), h.teacherID(c), classID, payload)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, controls)
+}
+
+func (h *Handler) respondClassError(c *gin.Context, err error, classID string) {
+	h.log.Warn("class operation failed", zap.String("class_id", classID), zap.Error(err))
+	c.Error(err)
+}
@@ -0,0 +1,64 @@
+package teacher
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	exportService "github.com/codeadventurers/api-go/internal/service/export"
+)
+
+// Exports lists the exports the teacher requested, newest first.
+func (h *Handler) Exports(c *gin.Context) {
+	records := h.exports.List(c.Request.Context(), exportService.RoleTeacher, h.teacherID(c))
+	c.JSON(http.StatusOK, gin.H{"exports": records})
+}
+
+// CreateExport queues a gradebook, progress or attempt history export.
+func (h *Handler) CreateExport(c *gin.Context) {
+	var payload exportService.Request
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid export payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	record, err := h.exports.Create(c.Request.Context(), exportService.RoleTeacher, h.teacherID(c), payload)
+	if err != nil {
+		h.respondExportError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusAccepted, record)
+}
+
+// Export returns the status of one export.
+func (h *Handler) Export(c *gin.Context) {
+	exportID := c.Param("exportId")
+	record, err := h.exports.Get(c.Request.Context(), exportService.RoleTeacher, h.teacherID(c), exportID)
+	if err != nil {
+		h.respondExportError(c, err, exportID)
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// DownloadExport streams the file of a completed export until it expires.
+func (h *Handler) DownloadExport(c *gin.Context) {
+	exportID := c.Param("exportId")
+	record, file, err := h.exports.Open(c.Request.Context(), exportService.RoleTeacher, h.teacherID(c), exportID)
+	if err != nil {
+		h.respondExportError(c, err, exportID)
+		return
+	}
+	defer file.Close()
+	c.DataFromReader(http.StatusOK, int64(record.Size), record.ContentType(), file, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", record.FileName),
+	})
+}
+
+func (h *Handler) respondExportError(c *gin.Context, err error, exportID string) {
+	h.log.Warn("export operation failed", zap.String("teacher_id", h.teacherID(c)), zap.String("export_id", exportID), zap.Error(err))
+	c.Error(err)
+}
@@ -7,18 +7,27 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	"github.com/codeadventurers/api-go/internal/http/identity"
+	exportService "github.com/codeadventurers/api-go/internal/service/export"
+	studentService "github.com/codeadventurers/api-go/internal/service/student"
 	service "github.com/codeadventurers/api-go/internal/service/teacher"
 )
 
 // Handler exposes analytics endpoints for teachers.
 type Handler struct {
 	service *service.Service
+	exports *exportService.Service
 	log     *zap.Logger
 }
 
 // New creates the teacher handler.
-func New(service *service.Service, log *zap.Logger) *Handler {
-	return &Handler{service: service, log: log}
+func New(service *service.Service, exports *exportService.Service, log *zap.Logger) *Handler {
+	return &Handler{service: service, exports: exports, log: log}
+}
+
+func (h *Handler) teacherID(c *gin.Context) string {
+	return identity.TeacherID(c)
 }
 
 // Analytics returns aggregated data for the requested resource path.
@@ -31,10 +40,10 @@ func (h *Handler) Analytics(c *gin.Context) {
 		}
 	}
 	h.log.Info("teacher analytics requested", zap.String("resource", resource), zap.Int("query_params", len(query)))
-	payload, err := h.service.Analytics(c.Request.Context(), resource, query)
+	payload, err := h.service.Analytics(c.Request.Context(), h.teacherID(c), resource, query)
 	if err != nil {
 		h.log.Error("teacher analytics failed", zap.String("resource", resource), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	h.log.Info("teacher analytics succeeded", zap.String("resource", resource))
@@ -43,10 +52,10 @@ func (h *Handler) Analytics(c *gin.Context) {
 
 // Courses lists all available teacher courses.
 func (h *Handler) Courses(c *gin.Context) {
-	result, err := h.service.Courses(c.Request.Context())
+	result, err := h.service.Courses(c.Request.Context(), h.teacherID(c))
 	if err != nil {
 		h.log.Error("failed to fetch teacher courses", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"courses": result})
@@ -54,10 +63,10 @@ func (h *Handler) Courses(c *gin.Context) {
 
 // Classes returns class summaries for the teacher.
 func (h *Handler) Classes(c *gin.Context) {
-	result, err := h.service.Classes(c.Request.Context())
+	result, err := h.service.Classes(c.Request.Context(), h.teacherID(c), c.Query("archived") == "true")
 	if err != nil {
 		h.log.Error("failed to fetch teacher classes", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"classes": result})
@@ -66,24 +75,26 @@ func (h *Handler) Classes(c *gin.Context) {
 // ClassDetail returns details for a class.
 func (h *Handler) ClassDetail(c *gin.Context) {
 	classID := c.Param("classId")
-	result, err := h.service.ClassDetail(c.Request.Context(), classID)
+	result, err := h.service.ClassDetail(c.Request.Context(), h.teacherID(c), classID)
 	if err != nil {
 		h.log.Warn("class detail fetch failed", zap.String("class_id", classID), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, result)
 }
 
-// UpdateHintLimit updates hint limit for a class.
+// UpdateHintLimit updates the hint limit of a class and, when given, the
+// window in minutes it applies to.
 func (h *Handler) UpdateHintLimit(c *gin.Context) {
 	classID := c.Param("classId")
 	var payload struct {
-		HintLimit int `json:"hintLimit"`
+		HintLimit         int `json:"hintLimit"`
+		HintWindowMinutes int `json:"hintWindowMinutes"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		h.log.Warn("invalid hint limit payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求体格式不正确"})
+		c.Error(httperr.Invalid(err))
 		return
 	}
 	if payload.HintLimit == 0 {
@@ -97,9 +108,9 @@ func (h *Handler) UpdateHintLimit(c *gin.Context) {
 		payload.HintLimit = 1
 	}
 
-	if err := h.service.UpdateClassHintLimit(c.Request.Context(), classID, payload.HintLimit); err != nil {
+	if err := h.service.UpdateClassHintLimit(c.Request.Context(), h.teacherID(c), classID, payload.HintLimit, payload.HintWindowMinutes); err != nil {
 		h.log.Warn("update hint limit failed", zap.String("class_id", classID), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
@@ -107,36 +118,62 @@ func (h *Handler) UpdateHintLimit(c *gin.Context) {
 
 // PendingWorks lists pending works for review.
 func (h *Handler) PendingWorks(c *gin.Context) {
-	result, err := h.service.PendingWorks(c.Request.Context())
+	result, err := h.service.PendingWorks(c.Request.Context(), h.teacherID(c))
 	if err != nil {
 		h.log.Error("failed to fetch pending works", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"works": result})
 }
 
-// ReviewWork marks a work as reviewed.
+// ReviewWork approves or rejects a submission with feedback and an optional
+// star override.
 func (h *Handler) ReviewWork(c *gin.Context) {
 	workID := c.Param("workId")
-	var payload struct {
-		Status string `json:"status"`
-	}
+	var payload studentService.WorkReview
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		h.log.Warn("invalid review payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请求体格式不正确"})
+		c.Error(httperr.Invalid(err))
 		return
 	}
-	if err := h.service.ReviewWork(c.Request.Context(), workID, payload.Status); err != nil {
+	work, err := h.service.ReviewWork(c.Request.Context(), h.teacherID(c), workID, payload)
+	if err != nil {
 		h.log.Warn("review work failed", zap.String("work_id", workID), zap.Error(err))
-		status := http.StatusBadRequest
-		if err == service.ErrWorkNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	c.JSON(http.StatusOK, work)
+}
+
+// ClassWorks lists the submissions of a class, filtered by the status query
+// parameter when given.
+func (h *Handler) ClassWorks(c *gin.Context) {
+	classID := c.Param("classId")
+	status := studentService.WorkStatus(c.Query("status"))
+	switch status {
+	case "", studentService.WorkPending, studentService.WorkApproved, studentService.WorkRejected:
+	default:
+		c.Error(httperr.InvalidField("status", nil))
+		return
+	}
+	works, err := h.service.ClassWorks(c.Request.Context(), h.teacherID(c), classID, status)
+	if err != nil {
+		h.log.Warn("failed to list class works", zap.String("class_id", classID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"works": works})
+}
+
+// ClassWork returns a submission of the class including its program.
+func (h *Handler) ClassWork(c *gin.Context) {
+	work, err := h.service.ClassWork(c.Request.Context(), h.teacherID(c), c.Param("classId"), c.Param("workId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, work)
 }
 
 // AssignCourse assigns a course to a class.
@@ -147,18 +184,464 @@ func (h *Handler) AssignCourse(c *gin.Context) {
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil || payload.CourseID == "" {
 		h.log.Warn("invalid assign course payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "请提供课程 ID"})
+		c.Error(httperr.InvalidField("courseId", err))
 		return
 	}
 
-	if err := h.service.AssignCourseToClass(c.Request.Context(), classID, payload.CourseID); err != nil {
+	if err := h.service.AssignCourseToClass(c.Request.Context(), h.teacherID(c), classID, payload.CourseID); err != nil {
 		h.log.Warn("assign course failed", zap.String("class_id", classID), zap.String("course_id", payload.CourseID), zap.Error(err))
-		status := http.StatusBadRequest
-		if err == service.ErrClassNotFound || err == service.ErrCourseNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// StudentAttempts lists a student's attempts on a level.
+func (h *Handler) StudentAttempts(c *gin.Context) {
+	classID := c.Param("classId")
+	studentID := c.Param("studentId")
+	levelID := c.Param("levelId")
+	result, err := h.service.StudentAttempts(c.Request.Context(), h.teacherID(c), classID, studentID, levelID)
+	if err != nil {
+		h.respondAttemptError(c, err, studentID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"attempts": result})
+}
+
+// StudentActivity returns a student's streak and activity calendar.
+func (h *Handler) StudentActivity(c *gin.Context) {
+	studentID := c.Param("studentId")
+	days, err := strconv.Atoi(c.DefaultQuery("days", "0"))
+	if err != nil {
+		h.log.Warn("invalid activity days", zap.Error(err))
+		c.Error(httperr.InvalidField("days", err))
+		return
+	}
+	result, err := h.service.StudentActivity(c.Request.Context(), h.teacherID(c), c.Param("classId"), studentID, days)
+	if err != nil {
+		h.respondAttemptError(c, err, studentID)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GrantAvatarItem gives an avatar item to a student of the class.
+func (h *Handler) GrantAvatarItem(c *gin.Context) {
+	studentID := c.Param("studentId")
+	var payload struct {
+		ItemID string `json:"itemId"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.ItemID == "" {
+		h.log.Warn("invalid avatar grant payload", zap.Error(err))
+		c.Error(httperr.InvalidField("itemId", err))
+		return
+	}
+	entry, err := h.service.GrantAvatarItem(c.Request.Context(), h.teacherID(c), c.Param("classId"), studentID, payload.ItemID, payload.Reason)
+	if err != nil {
+		h.log.Warn("failed to grant avatar item", zap.String("student_id", studentID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// ClassProjects lists the sandbox projects of a class awaiting moderation, or
+// those in the status given by the status query parameter.
+func (h *Handler) ClassProjects(c *gin.Context) {
+	classID := c.Param("classId")
+	status := studentService.ProjectStatus(c.Query("status"))
+	switch status {
+	case "", studentService.ProjectPending, studentService.ProjectPublished, studentService.ProjectRejected:
+	default:
+		c.Error(httperr.InvalidField("status", nil))
+		return
+	}
+	projects, err := h.service.ClassProjects(c.Request.Context(), h.teacherID(c), classID, status)
+	if err != nil {
+		h.log.Warn("failed to list class projects", zap.String("class_id", classID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+// ModerateProject approves or rejects a project for the class gallery.
+func (h *Handler) ModerateProject(c *gin.Context) {
+	projectID := c.Param("projectId")
+	var payload struct {
+		Approve *bool  `json:"approve"`
+		Note    string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Approve == nil {
+		h.log.Warn("invalid project moderation payload", zap.Error(err))
+		c.Error(httperr.InvalidField("approve", err))
+		return
+	}
+	project, err := h.service.ModerateProject(c.Request.Context(), h.teacherID(c), c.Param("classId"), projectID, *payload.Approve, payload.Note)
+	if err != nil {
+		h.log.Warn("failed to moderate project", zap.String("project_id", projectID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// StudentAttempt returns a single attempt including its program.
+func (h *Handler) StudentAttempt(c *gin.Context) {
+	classID := c.Param("classId")
+	studentID := c.Param("studentId")
+	attemptID := c.Param("attemptId")
+	result, err := h.service.StudentAttempt(c.Request.Context(), h.teacherID(c), classID, studentID, attemptID)
+	if err != nil {
+		h.respondAttemptError(c, err, studentID)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// StudentReplay returns a page of re-simulated replay frames for an attempt.
+func (h *Handler) StudentReplay(c *gin.Context) {
+	classID := c.Param("classId")
+	studentID := c.Param("studentId")
+	attemptID := c.Param("attemptId")
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		h.log.Warn("invalid replay offset", zap.Error(err))
+		c.Error(httperr.InvalidField("offset", err))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		h.log.Warn("invalid replay limit", zap.Error(err))
+		c.Error(httperr.InvalidField("limit", err))
+		return
+	}
+	result, err := h.service.StudentReplay(c.Request.Context(), h.teacherID(c), classID, studentID, attemptID, offset, limit)
+	if err != nil {
+		h.respondAttemptError(c, err, studentID)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) respondAttemptError(c *gin.Context, err error, studentID string) {
+	h.log.Warn("student attempts lookup failed", zap.String("student_id", studentID), zap.Error(err))
+	c.Error(err)
+}
+
+// LevelRevisions lists all revisions of a level owned by the teacher.
+func (h *Handler) LevelRevisions(c *gin.Context) {
+	levelID := c.Param("levelId")
+	result, err := h.service.LevelRevisions(c.Request.Context(), h.teacherID(c), levelID)
+	if err != nil {
+		h.respondRevisionError(c, err, levelID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revisions": result})
+}
+
+// CreateLevelDraft stores a new draft revision of a level.
+func (h *Handler) CreateLevelDraft(c *gin.Context) {
+	levelID := c.Param("levelId")
+	var payload studentService.LevelDefinition
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid level draft payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	result, err := h.service.CreateLevelDraft(c.Request.Context(), h.teacherID(c), levelID, payload)
+	if err != nil {
+		h.respondRevisionError(c, err, levelID)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// PublishLevelRevision publishes a draft revision of a level.
+func (h *Handler) PublishLevelRevision(c *gin.Context) {
+	levelID := c.Param("levelId")
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		h.log.Warn("invalid level revision", zap.String("level_id", levelID), zap.Error(err))
+		c.Error(httperr.InvalidField("revision", err))
+		return
+	}
+	result, err := h.service.PublishLevelRevision(c.Request.Context(), h.teacherID(c), levelID, revision)
+	if err != nil {
+		h.respondRevisionError(c, err, levelID)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// AffectedStudents lists students whose progress predates the published revision.
+func (h *Handler) AffectedStudents(c *gin.Context) {
+	levelID := c.Param("levelId")
+	result, err := h.service.AffectedStudents(c.Request.Context(), h.teacherID(c), levelID)
+	if err != nil {
+		h.respondRevisionError(c, err, levelID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"students": result})
+}
+
+// MigrateLevelProgress moves affected students onto the published revision.
+func (h *Handler) MigrateLevelProgress(c *gin.Context) {
+	levelID := c.Param("levelId")
+	var payload struct {
+		StudentIDs []string `json:"studentIds"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid migrate payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	result, err := h.service.MigrateLevelProgress(c.Request.Context(), h.teacherID(c), levelID, payload.StudentIDs)
+	if err != nil {
+		h.respondRevisionError(c, err, levelID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"results": result})
+}
+
+func (h *Handler) respondRevisionError(c *gin.Context, err error, levelID string) {
+	h.log.Warn("level revision operation failed", zap.String("level_id", levelID), zap.Error(err))
+	c.Error(err)
+}
+
+// CreateCourse creates a course owned by the teacher.
+func (h *Handler) CreateCourse(c *gin.Context) {
+	var payload struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Name == "" {
+		h.log.Warn("invalid course payload", zap.Error(err))
+		c.Error(httperr.InvalidField("name", err))
+		return
+	}
+	result, err := h.service.CreateCourse(c.Request.Context(), h.teacherID(c), service.CourseInput{Name: payload.Name, Description: payload.Description})
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// UpdateCourse edits a course owned by the teacher.
+func (h *Handler) UpdateCourse(c *gin.Context) {
+	var payload struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Name == "" {
+		h.log.Warn("invalid course payload", zap.Error(err))
+		c.Error(httperr.InvalidField("name", err))
+		return
+	}
+	result, err := h.service.UpdateCourse(c.Request.Context(), h.teacherID(c), c.Param("courseId"), service.CourseInput{Name: payload.Name, Description: payload.Description})
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteCourse removes a course owned by the teacher.
+func (h *Handler) DeleteCourse(c *gin.Context) {
+	if err := h.service.DeleteCourse(c.Request.Context(), h.teacherID(c), c.Param("courseId")); err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// CourseTranslations reports missing translations of a course. The optional
+// locale query parameter limits the report to one locale.
+func (h *Handler) CourseTranslations(c *gin.Context) {
+	reports, err := h.service.CourseTranslations(c.Request.Context(), h.teacherID(c), c.Param("courseId"), c.Query("locale"))
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// CreateChapter appends a chapter to a course.
+func (h *Handler) CreateChapter(c *gin.Context) {
+	var payload struct {
+		Title        string                                `json:"title"`
+		Summary      string                                `json:"summary"`
+		Translations map[string]studentService.ChapterText `json:"translations"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Title == "" {
+		h.log.Warn("invalid chapter payload", zap.Error(err))
+		c.Error(httperr.InvalidField("title", err))
+		return
+	}
+	result, err := h.service.CreateChapter(c.Request.Context(), h.teacherID(c), c.Param("courseId"), service.ChapterInput{Title: payload.Title, Summary: payload.Summary, Translations: payload.Translations})
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// UpdateChapter edits a chapter.
+func (h *Handler) UpdateChapter(c *gin.Context) {
+	var payload struct {
+		Title        string                                `json:"title"`
+		Summary      string                                `json:"summary"`
+		Translations map[string]studentService.ChapterText `json:"translations"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.Title == "" {
+		h.log.Warn("invalid chapter payload", zap.Error(err))
+		c.Error(httperr.InvalidField("title", err))
+		return
+	}
+	result, err := h.service.UpdateChapter(c.Request.Context(), h.teacherID(c), c.Param("chapterId"), service.ChapterInput{Title: payload.Title, Summary: payload.Summary, Translations: payload.Translations})
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteChapter removes a chapter and its levels.
+func (h *Handler) DeleteChapter(c *gin.Context) {
+	if err := h.service.DeleteChapter(c.Request.Context(), h.teacherID(c), c.Param("chapterId")); err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ReorderChapters sets the chapter order of a course.
+func (h *Handler) ReorderChapters(c *gin.Context) {
+	var payload struct {
+		ChapterIDs []string `json:"chapterIds"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid chapter order payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	if err := h.service.ReorderChapters(c.Request.Context(), h.teacherID(c), c.Param("courseId"), payload.ChapterIDs); err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// CreateLevel validates and stores a new level in a chapter.
+func (h *Handler) CreateLevel(c *gin.Context) {
+	var payload studentService.LevelDefinition
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid level payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	result, err := h.service.CreateLevel(c.Request.Context(), h.teacherID(c), c.Param("chapterId"), payload)
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// UpdateLevel validates a level and publishes it as a new revision.
+func (h *Handler) UpdateLevel(c *gin.Context) {
+	var payload studentService.LevelDefinition
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid level payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	result, err := h.service.UpdateLevel(c.Request.Context(), h.teacherID(c), c.Param("levelId"), payload)
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteLevel removes a level.
+func (h *Handler) DeleteLevel(c *gin.Context) {
+	if err := h.service.DeleteLevel(c.Request.Context(), h.teacherID(c), c.Param("levelId")); err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Badges lists the badges the teacher can see.
+func (h *Handler) Badges(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Badges(c.Request.Context(), h.teacherID(c)))
+}
+
+// SaveBadge creates or replaces a badge owned by the teacher.
+func (h *Handler) SaveBadge(c *gin.Context) {
+	var payload studentService.BadgeDefinition
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid badge payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	payload.ID = c.Param("badgeId")
+	result, err := h.service.SaveBadge(c.Request.Context(), h.teacherID(c), payload)
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteBadge removes a badge owned by the teacher.
+func (h *Handler) DeleteBadge(c *gin.Context) {
+	if err := h.service.DeleteBadge(c.Request.Context(), h.teacherID(c), c.Param("badgeId")); err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ReorderLevels sets the level order of a chapter.
+func (h *Handler) ReorderLevels(c *gin.Context) {
+	var payload struct {
+		LevelIDs []string `json:"levelIds"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid level order payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	if err := h.service.ReorderLevels(c.Request.Context(), h.teacherID(c), c.Param("chapterId"), payload.LevelIDs); err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ValidateLevel checks a level definition without storing it and returns the
+// shortest solution found.
+func (h *Handler) ValidateLevel(c *gin.Context) {
+	var payload studentService.LevelDefinition
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid level payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	result, err := h.service.ValidateLevel(c.Request.Context(), payload)
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) respondAuthoringError(c *gin.Context, err error) {
+	h.log.Warn("authoring operation failed", zap.String("teacher_id", h.teacherID(c)), zap.Error(err))
+	c.Error(err)
+}
@@ -0,0 +1,86 @@
+package teacher
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	"github.com/codeadventurers/api-go/internal/levelpack"
+	service "github.com/codeadventurers/api-go/internal/service/teacher"
+)
+
+// maxPackSize caps the size of uploaded level packs.
+const maxPackSize = 4 << 20
+
+// ExportCourse downloads a course as a level pack. The format query parameter
+// selects json (default) or yaml.
+func (h *Handler) ExportCourse(c *gin.Context) {
+	format := c.DefaultQuery("format", levelpack.FormatJSON)
+	pack, err := h.service.ExportCourse(c.Request.Context(), h.teacherID(c), c.Param("courseId"))
+	if err != nil {
+		h.respondLevelPackError(c, err, service.ImportReport{})
+		return
+	}
+	data, err := levelpack.Encode(pack, format)
+	if err != nil {
+		h.respondLevelPackError(c, err, service.ImportReport{})
+		return
+	}
+
+	contentType := "application/json"
+	if format == levelpack.FormatYAML {
+		contentType = "application/yaml"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", pack.Course.ID+"."+format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// ImportCourse imports a level pack. The format is taken from the format
+// query parameter or the Content-Type header; dryRun=true only reports the
+// changes the import would make.
+func (h *Handler) ImportCourse(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = levelpack.FormatJSON
+		if strings.Contains(c.ContentType(), "yaml") {
+			format = levelpack.FormatYAML
+		}
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPackSize))
+	if err != nil {
+		h.log.Warn("failed to read level pack", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	pack, err := levelpack.Decode(data, format)
+	if err != nil {
+		h.respondLevelPackError(c, err, service.ImportReport{})
+		return
+	}
+
+	dryRun := c.Query("dryRun") == "true"
+	report, err := h.service.ImportPack(c.Request.Context(), h.teacherID(c), pack, dryRun)
+	if err != nil {
+		h.respondLevelPackError(c, err, report)
+		return
+	}
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
+
+func (h *Handler) respondLevelPackError(c *gin.Context, err error, report service.ImportReport) {
+	h.log.Warn("level pack operation failed", zap.String("teacher_id", h.teacherID(c)), zap.Error(err))
+	if errors.Is(err, service.ErrInvalidPack) {
+		err = service.ErrInvalidPack.With("report", report)
+	}
+	c.Error(err)
+}
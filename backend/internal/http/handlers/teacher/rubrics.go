@@ -0,0 +1,122 @@
+package teacher
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	studentService "github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// Rubrics lists the rubrics of a class.
+func (h *Handler) Rubrics(c *gin.Context) {
+	classID := c.Param("classId")
+	rubrics, err := h.service.Rubrics(c.Request.Context(), h.teacherID(c), classID)
+	if err != nil {
+		h.respondRubricError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, rubrics)
+}
+
+// SaveRubric creates or replaces a rubric of a class.
+func (h *Handler) SaveRubric(c *gin.Context) {
+	classID := c.Param("classId")
+	var payload studentService.Rubric
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid rubric payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	payload.ID = c.Param("rubricId")
+	payload.ClassID = classID
+	result, err := h.service.SaveRubric(c.Request.Context(), h.teacherID(c), payload)
+	if err != nil {
+		h.respondRubricError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteRubric removes a rubric of a class.
+func (h *Handler) DeleteRubric(c *gin.Context) {
+	classID := c.Param("classId")
+	if err := h.service.DeleteRubric(c.Request.Context(), h.teacherID(c), classID, c.Param("rubricId")); err != nil {
+		h.respondRubricError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Gradebook returns the rubric scores of a class. The format query parameter
+// selects json (default) or csv.
+func (h *Handler) Gradebook(c *gin.Context) {
+	classID := c.Param("classId")
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.Error(httperr.InvalidField("format", nil))
+		return
+	}
+	book, err := h.service.Gradebook(c.Request.Context(), h.teacherID(c), classID)
+	if err != nil {
+		h.respondRubricError(c, err, classID)
+		return
+	}
+	if format == "json" {
+		c.JSON(http.StatusOK, book)
+		return
+	}
+
+	data, err := gradebookCSV(book)
+	if err != nil {
+		h.respondRubricError(c, err, classID)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", classID+"-gradebook.csv"))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+func (h *Handler) respondRubricError(c *gin.Context, err error, classID string) {
+	h.log.Warn("rubric operation failed", zap.String("class_id", classID), zap.Error(err))
+	c.Error(err)
+}
+
+// gradebookCSV writes one row per student with a column per rubric. Rubrics
+// without a reviewed score are left empty. The UTF-8 byte order mark lets
+// spreadsheet programs detect the encoding of Chinese names.
+func gradebookCSV(book studentService.Gradebook) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+
+	header := []string{"studentId", "name"}
+	for _, rubric := range book.Rubrics {
+		header = append(header, fmt.Sprintf("%s (%d)", rubric.Title, rubric.MaxPoints()))
+	}
+	header = append(header, "total", "maxPoints")
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, row := range book.Rows {
+		record := []string{row.ID, row.Name}
+		for _, cell := range row.Cells {
+			value := ""
+			if cell.Total != nil {
+				value = strconv.Itoa(*cell.Total)
+			}
+			record = append(record, value)
+		}
+		record = append(record, strconv.Itoa(row.Total), strconv.Itoa(row.MaxPoints))
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
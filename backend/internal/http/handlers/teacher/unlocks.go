@@ -0,0 +1,108 @@
+package teacher
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/http/httperr"
+	service "github.com/codeadventurers/api-go/internal/service/teacher"
+)
+
+// SetCourseUnlockPolicy sets how the levels of a course unlock.
+func (h *Handler) SetCourseUnlockPolicy(c *gin.Context) {
+	var payload service.UnlockPolicy
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid unlock policy payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	course, err := h.service.SetCourseUnlockPolicy(c.Request.Context(), h.teacherID(c), c.Param("courseId"), &payload)
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, course)
+}
+
+// ClearCourseUnlockPolicy restores sequential unlocking for a course.
+func (h *Handler) ClearCourseUnlockPolicy(c *gin.Context) {
+	course, err := h.service.SetCourseUnlockPolicy(c.Request.Context(), h.teacherID(c), c.Param("courseId"), nil)
+	if err != nil {
+		h.respondAuthoringError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, course)
+}
+
+// SetClassUnlockPolicy sets how levels unlock for a class, overriding the
+// policies of its courses.
+func (h *Handler) SetClassUnlockPolicy(c *gin.Context) {
+	classID := c.Param("classId")
+	var payload service.UnlockPolicy
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.log.Warn("invalid unlock policy payload", zap.Error(err))
+		c.Error(httperr.Invalid(err))
+		return
+	}
+	class, err := h.service.SetClassUnlockPolicy(c.Request.Context(), h.teacherID(c), classID, &payload)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, class)
+}
+
+// ClearClassUnlockPolicy lets the policies of its courses apply to a class
+// again.
+func (h *Handler) ClearClassUnlockPolicy(c *gin.Context) {
+	classID := c.Param("classId")
+	class, err := h.service.SetClassUnlockPolicy(c.Request.Context(), h.teacherID(c), classID, nil)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, class)
+}
+
+// StudentUnlocks lists the levels opened for one student.
+func (h *Handler) StudentUnlocks(c *gin.Context) {
+	classID := c.Param("classId")
+	unlocks, err := h.service.StudentUnlocks(c.Request.Context(), h.teacherID(c), classID, c.Param("studentId"))
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unlocks": unlocks})
+}
+
+// UnlockLevelForStudent opens a level for one student.
+func (h *Handler) UnlockLevelForStudent(c *gin.Context) {
+	classID := c.Param("classId")
+	var payload struct {
+		LevelID string `json:"levelId"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || payload.LevelID == "" {
+		h.log.Warn("invalid level unlock payload", zap.Error(err))
+		c.Error(httperr.InvalidField("levelId", err))
+		return
+	}
+	unlocks, err := h.service.UnlockLevelForStudent(c.Request.Context(), h.teacherID(c), classID, c.Param("studentId"), payload.LevelID)
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unlocks": unlocks})
+}
+
+// RevokeLevelUnlock closes a level opened for one student.
+func (h *Handler) RevokeLevelUnlock(c *gin.Context) {
+	classID := c.Param("classId")
+	unlocks, err := h.service.RevokeLevelUnlock(c.Request.Context(), h.teacherID(c), classID, c.Param("studentId"), c.Param("levelId"))
+	if err != nil {
+		h.respondClassError(c, err, classID)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unlocks": unlocks})
+}
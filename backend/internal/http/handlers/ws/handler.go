@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"encoding/json"
 	"net/http"
 	"time"
 
@@ -8,21 +9,30 @@ import (
 	"go.uber.org/zap"
 	"nhooyr.io/websocket"
 
+	"github.com/codeadventurers/api-go/internal/http/identity"
+	"github.com/codeadventurers/api-go/internal/service/monitor"
+	"github.com/codeadventurers/api-go/internal/service/student"
+	"github.com/codeadventurers/api-go/internal/service/teacher"
 	"github.com/codeadventurers/api-go/internal/ws"
 )
 
-// Handler manages websocket connections for run streams.
+// Handler manages websocket connections for run streams and class monitors.
 type Handler struct {
-	manager *ws.Manager
-	log     *zap.Logger
+	manager  *ws.Manager
+	students *student.Service
+	teachers *teacher.Service
+	monitor  *monitor.Service
+	log      *zap.Logger
 }
 
 // New creates a websocket handler.
-func New(manager *ws.Manager, log *zap.Logger) *Handler {
-	return &Handler{manager: manager, log: log}
+func New(manager *ws.Manager, students *student.Service, teachers *teacher.Service, monitor *monitor.Service, log *zap.Logger) *Handler {
+	return &Handler{manager: manager, students: students, teachers: teachers, monitor: monitor, log: log}
 }
 
-// Stream upgrades the connection and registers it with the manager.
+// Stream upgrades the connection and registers it with the manager. Students
+// of a class also receive the classroom controls of their class, first the
+// ones in effect and then every change.
 func (h *Handler) Stream(c *gin.Context) {
 	userID := c.GetString("user_id")
 	if userID == "" {
@@ -43,6 +53,12 @@ func (h *Handler) Stream(c *gin.Context) {
 
 	h.log.Info("websocket connection established", zap.String("user_id", userID))
 	h.manager.Register(userID, conn)
+	if classID, controls := h.students.StudentControls(c.Request.Context(), userID); classID != "" {
+		h.manager.Subscribe(ws.ClassTopic(classID), conn)
+		if controls.UpdatedAt != 0 {
+			h.write(c, conn, monitor.ControlsEvent(classID, controls))
+		}
+	}
 
 	for {
 		_, _, err := conn.Read(c.Request.Context())
@@ -54,3 +70,65 @@ func (h *Handler) Stream(c *gin.Context) {
 		time.Sleep(time.Second)
 	}
 }
+
+// Monitor streams the live state of the students of a class to its teacher,
+// who is identified as on the teacher REST endpoints. The first message is a
+// snapshot of the class, followed by run, hint, complete and idle events as
+// they happen.
+func (h *Handler) Monitor(c *gin.Context) {
+	classID := c.Param("classId")
+	teacherID := identity.TeacherID(c)
+	roster, err := h.teachers.ClassRoster(c.Request.Context(), teacherID, classID)
+	if err != nil {
+		h.log.Warn("class monitor refused", zap.String("class_id", classID), zap.String("user_id", teacherID), zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	conn, err := websocket.Accept(c.Writer, c.Request, &websocket.AcceptOptions{
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		h.log.Error("websocket accept failed", zap.Error(err))
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "closing")
+
+	ctx := c.Request.Context()
+	topic := ws.ClassMonitorTopic(classID)
+	h.manager.Register(teacherID, conn)
+	h.manager.Subscribe(topic, conn)
+	defer h.manager.Cleanup(teacherID, conn)
+	h.log.Info("class monitor connected", zap.String("class_id", classID), zap.String("user_id", teacherID))
+
+	members := make([]monitor.Member, 0, len(roster))
+	for _, member := range roster {
+		members = append(members, monitor.Member{ID: member.ID, Name: member.Name})
+	}
+	snapshot := h.monitor.Snapshot(ctx, classID, members)
+	if controls := h.students.ClassControls(ctx, classID); controls.UpdatedAt != 0 {
+		snapshot.Controls = &controls
+	}
+	if err := h.write(c, conn, snapshot); err != nil {
+		return
+	}
+
+	for {
+		if _, _, err := conn.Read(ctx); err != nil {
+			h.log.Info("class monitor closed", zap.String("class_id", classID), zap.String("user_id", teacherID), zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handler) write(c *gin.Context, conn *websocket.Conn, event monitor.Event) error {
+	payload, err := json.Marshal(event)
+	if err == nil {
+		err = conn.Write(c.Request.Context(), websocket.MessageText, payload)
+	}
+	if err != nil {
+		h.log.Warn("websocket write failed", zap.String("class_id", event.ClassID), zap.String("type", string(event.Type)), zap.Error(err))
+	}
+	return err
+}
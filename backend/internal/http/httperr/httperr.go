@@ -0,0 +1,146 @@
+// Package httperr turns errors recorded by handlers into the JSON envelope
+// shared by every endpoint:
+//
+//	{"code": "level.not_found", "message": "关卡不存在", "details": {...}, "requestId": "..."}
+//
+// Handlers report failures with c.Error(err) and return; the middleware maps
+// the error to its status code and localizes the message.
+package httperr
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-Id"
+
+const requestIDKey = "request_id"
+
+// Envelope is the body of every error response.
+type Envelope struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"requestId"`
+}
+
+// LanguageFunc returns the stored language preference of the caller, or ""
+// when there is none.
+type LanguageFunc func(c *gin.Context) string
+
+// Responder writes error envelopes.
+type Responder struct {
+	log      *zap.Logger
+	language LanguageFunc
+}
+
+// New creates a responder. language may be nil.
+func New(log *zap.Logger, language LanguageFunc) *Responder {
+	return &Responder{log: log, language: language}
+}
+
+// RequestID assigns every request an ID, reusing the one sent by the client.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// Middleware writes the envelope for the last error recorded by the handler
+// chain unless a response has already been written.
+func (r *Responder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		r.Respond(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery converts panics into an internal error envelope.
+func (r *Responder) Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		r.log.Error("panic recovered", zap.Any("panic", recovered), zap.String("request_id", c.GetString(requestIDKey)))
+		r.Respond(c, apperr.ErrInternal)
+	})
+}
+
+// NoRoute answers requests for unknown endpoints.
+func NoRoute(c *gin.Context) {
+	c.Error(apperr.ErrRouteNotFound)
+}
+
+// Respond writes the envelope for err and aborts the chain.
+func (r *Responder) Respond(c *gin.Context, err error) {
+	appErr := apperr.From(err)
+	requestID := c.GetString(requestIDKey)
+
+	fields := []zap.Field{
+		zap.String("code", appErr.Code),
+		zap.String("path", c.Request.URL.Path),
+		zap.String("request_id", requestID),
+		zap.Error(err),
+	}
+	if appErr.Kind == apperr.KindInternal {
+		r.log.Error("request failed", fields...)
+	} else {
+		r.log.Debug("request rejected", fields...)
+	}
+
+	c.AbortWithStatusJSON(appErr.Kind.Status(), Envelope{
+		Code:      appErr.Code,
+		Message:   appErr.Message(r.languageOf(c)),
+		Details:   appErr.Details,
+		RequestID: requestID,
+	})
+}
+
+// languageOf prefers the caller's stored setting over Accept-Language.
+func (r *Responder) languageOf(c *gin.Context) string {
+	if r.language != nil {
+		if language := apperr.MatchLanguage(r.language(c)); language != "" {
+			return language
+		}
+	}
+	if language := apperr.NegotiateLanguage(c.GetHeader("Accept-Language")); language != "" {
+		return language
+	}
+	return apperr.DefaultLanguage
+}
+
+// Invalid wraps a binding or validation failure. Field-level validation
+// failures are listed in the details.
+func Invalid(err error) *apperr.Error {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return apperr.ErrInvalidRequest.Wrap(err)
+	}
+	fields := make([]map[string]string, 0, len(fieldErrors))
+	for _, fieldErr := range fieldErrors {
+		fields = append(fields, map[string]string{"field": fieldErr.Field(), "rule": fieldErr.Tag()})
+	}
+	return apperr.ErrInvalidRequest.With("fields", fields).Wrap(err)
+}
+
+// InvalidField reports a missing or malformed request field.
+func InvalidField(field string, cause error) *apperr.Error {
+	appErr := apperr.ErrInvalidRequest.With("field", field)
+	if cause != nil {
+		return appErr.Wrap(cause)
+	}
+	return appErr
+}
@@ -0,0 +1,22 @@
+// Package identity resolves the user a request acts for. Until sessions are
+// in place the user is named by the x-user-id header.
+package identity
+
+import "github.com/gin-gonic/gin"
+
+// DemoTeacherID is the seeded teacher who owns the demo classes and courses.
+// Teacher requests that name no user act as this teacher.
+const DemoTeacherID = "teacher-1"
+
+// TeacherID returns the teacher a request acts for. Browsers cannot set
+// headers on WebSocket requests, so the userId query parameter is accepted
+// when the x-user-id header is missing.
+func TeacherID(c *gin.Context) string {
+	if header := c.GetHeader("x-user-id"); header != "" {
+		return header
+	}
+	if query := c.Query("userId"); query != "" {
+		return query
+	}
+	return DemoTeacherID
+}
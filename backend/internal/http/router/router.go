@@ -8,13 +8,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.uber.org/zap"
 
+	adminHandler "github.com/codeadventurers/api-go/internal/http/handlers/admin"
 	authHandler "github.com/codeadventurers/api-go/internal/http/handlers/auth"
 	healthHandler "github.com/codeadventurers/api-go/internal/http/handlers/health"
 	parentHandler "github.com/codeadventurers/api-go/internal/http/handlers/parent"
 	studentHandler "github.com/codeadventurers/api-go/internal/http/handlers/student"
 	teacherHandler "github.com/codeadventurers/api-go/internal/http/handlers/teacher"
 	wsHandler "github.com/codeadventurers/api-go/internal/http/handlers/ws"
+	"github.com/codeadventurers/api-go/internal/http/httperr"
 	"github.com/codeadventurers/api-go/internal/platform/config"
 	"github.com/codeadventurers/api-go/internal/platform/rate"
 )
@@ -26,24 +29,40 @@ type Dependencies struct {
 	Student     *studentHandler.Handler
 	Teacher     *teacherHandler.Handler
 	Parent      *parentHandler.Handler
+	Admin       *adminHandler.Handler
 	Health      *healthHandler.Handler
 	WS          *wsHandler.Handler
 	RateLimiter *rate.Limiter
+	Logger      *zap.Logger
 }
 
 // New builds the gin router with all routes configured.
 func New(deps Dependencies) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
-	engine.Use(gin.Recovery())
+
+	log := deps.Logger
+	if log == nil {
+		log = zap.NewNop()
+	}
+	var language httperr.LanguageFunc
+	if deps.Student != nil {
+		language = deps.Student.Language
+	}
+	responder := httperr.New(log, language)
+
+	engine.Use(httperr.RequestID())
+	engine.Use(responder.Recovery())
 	engine.Use(otelgin.Middleware("api-go"))
 	engine.Use(gin.Logger())
 	engine.Use(configureCORS(deps.Config))
+	engine.Use(responder.Middleware())
 	if deps.RateLimiter != nil {
 		engine.Use(deps.RateLimiter.Middleware())
 	}
 
 	registerRoutes(engine, deps)
+	engine.NoRoute(httperr.NoRoute)
 	return engine
 }
 
@@ -71,21 +90,112 @@ func registerRoutes(engine *gin.Engine, deps Dependencies) {
 			student.POST("/levels/:id/complete", deps.Student.Complete)
 			student.POST("/levels/:id/sandbox", deps.Student.Sandbox)
 			student.POST("/hints/:id", deps.Student.Hint)
+			student.POST("/programs/convert", deps.Student.ConvertProgram)
 			student.GET("/settings", deps.Student.Settings)
 			student.PUT("/settings", deps.Student.UpdateSettings)
 			student.POST("/settings/reset-progress", deps.Student.ResetProgress)
 			student.GET("/avatar", deps.Student.Avatar)
 			student.PUT("/avatar", deps.Student.UpdateAvatar)
+			student.GET("/badges", deps.Student.Badges)
+			student.GET("/compendium", deps.Student.Compendium)
+			student.GET("/activity", deps.Student.Activity)
+			student.POST("/activity/heartbeat", deps.Student.Heartbeat)
+			student.GET("/shop", deps.Student.Shop)
+			student.POST("/shop/purchase", deps.Student.Purchase)
+			student.GET("/wallet", deps.Student.Wallet)
+			student.GET("/projects", deps.Student.Projects)
+			student.POST("/projects", deps.Student.CreateProject)
+			student.GET("/projects/:projectId", deps.Student.Project)
+			student.PUT("/projects/:projectId", deps.Student.UpdateProject)
+			student.DELETE("/projects/:projectId", deps.Student.DeleteProject)
+			student.POST("/projects/:projectId/fork", deps.Student.ForkProject)
+			student.POST("/projects/:projectId/publish", deps.Student.PublishProject)
+			student.POST("/projects/:projectId/unpublish", deps.Student.UnpublishProject)
+			student.POST("/projects/:projectId/run", deps.Student.RunProject)
+			student.GET("/gallery", deps.Student.Gallery)
+			student.GET("/works", deps.Student.Works)
+			student.POST("/works", deps.Student.SubmitWork)
+			student.GET("/works/:workId", deps.Student.Work)
+			student.GET("/assignments", deps.Student.Assignments)
+			student.GET("/assignments/:assignmentId", deps.Student.Assignment)
+			student.GET("/assignments/:assignmentId/assessment", deps.Student.Assessment)
+			student.POST("/assignments/:assignmentId/assessment/start", deps.Student.StartAssessment)
+			student.POST("/assignments/:assignmentId/assessment/levels/:levelId/submit", deps.Student.SubmitAssessment)
+			student.POST("/assignments/:assignmentId/assessment/finish", deps.Student.FinishAssessment)
+			student.GET("/notifications", deps.Student.Notifications)
+			student.POST("/notifications/read", deps.Student.ReadNotifications)
 		}
 
 		teacher := api.Group("/teacher")
 		{
 			teacher.GET("/analytics/*resource", deps.Teacher.Analytics)
 			teacher.GET("/courses", deps.Teacher.Courses)
+			teacher.POST("/courses", deps.Teacher.CreateCourse)
+			teacher.POST("/courses/import", deps.Teacher.ImportCourse)
+			teacher.GET("/courses/:courseId/export", deps.Teacher.ExportCourse)
+			teacher.GET("/courses/:courseId/translations", deps.Teacher.CourseTranslations)
+			teacher.PUT("/courses/:courseId", deps.Teacher.UpdateCourse)
+			teacher.DELETE("/courses/:courseId", deps.Teacher.DeleteCourse)
+			teacher.PUT("/courses/:courseId/unlock-policy", deps.Teacher.SetCourseUnlockPolicy)
+			teacher.DELETE("/courses/:courseId/unlock-policy", deps.Teacher.ClearCourseUnlockPolicy)
+			teacher.POST("/courses/:courseId/chapters", deps.Teacher.CreateChapter)
+			teacher.PUT("/courses/:courseId/chapters/order", deps.Teacher.ReorderChapters)
+			teacher.PUT("/chapters/:chapterId", deps.Teacher.UpdateChapter)
+			teacher.DELETE("/chapters/:chapterId", deps.Teacher.DeleteChapter)
+			teacher.POST("/chapters/:chapterId/levels", deps.Teacher.CreateLevel)
+			teacher.PUT("/chapters/:chapterId/levels/order", deps.Teacher.ReorderLevels)
+			teacher.POST("/levels/validate", deps.Teacher.ValidateLevel)
+			teacher.PUT("/levels/:levelId", deps.Teacher.UpdateLevel)
+			teacher.DELETE("/levels/:levelId", deps.Teacher.DeleteLevel)
+			teacher.GET("/badges", deps.Teacher.Badges)
+			teacher.PUT("/badges/:badgeId", deps.Teacher.SaveBadge)
+			teacher.DELETE("/badges/:badgeId", deps.Teacher.DeleteBadge)
 			teacher.GET("/classes", deps.Teacher.Classes)
+			teacher.POST("/classes", deps.Teacher.CreateClass)
 			teacher.GET("/classes/:classId", deps.Teacher.ClassDetail)
+			teacher.PATCH("/classes/:classId", deps.Teacher.UpdateClass)
+			teacher.POST("/classes/:classId/invite", deps.Teacher.RegenerateInvite)
+			teacher.POST("/classes/:classId/invite/expire", deps.Teacher.ExpireInvite)
+			teacher.POST("/classes/:classId/roster/import", deps.Teacher.ImportRoster)
+			teacher.DELETE("/classes/:classId/students/:studentId", deps.Teacher.RemoveStudent)
+			teacher.POST("/classes/:classId/students/:studentId/transfer", deps.Teacher.TransferStudent)
+			teacher.GET("/classes/:classId/students/:studentId/levels/:levelId/attempts", deps.Teacher.StudentAttempts)
+			teacher.GET("/classes/:classId/students/:studentId/activity", deps.Teacher.StudentActivity)
+			teacher.POST("/classes/:classId/students/:studentId/avatar-items", deps.Teacher.GrantAvatarItem)
+			teacher.GET("/classes/:classId/students/:studentId/attempts/:attemptId", deps.Teacher.StudentAttempt)
+			teacher.GET("/classes/:classId/students/:studentId/attempts/:attemptId/replay", deps.Teacher.StudentReplay)
+			teacher.GET("/classes/:classId/works", deps.Teacher.ClassWorks)
+			teacher.GET("/classes/:classId/works/:workId", deps.Teacher.ClassWork)
+			teacher.GET("/classes/:classId/rubrics", deps.Teacher.Rubrics)
+			teacher.PUT("/classes/:classId/rubrics/:rubricId", deps.Teacher.SaveRubric)
+			teacher.DELETE("/classes/:classId/rubrics/:rubricId", deps.Teacher.DeleteRubric)
+			teacher.GET("/classes/:classId/gradebook", deps.Teacher.Gradebook)
+			teacher.GET("/classes/:classId/assignments", deps.Teacher.ClassAssignments)
+			teacher.POST("/classes/:classId/assignments", deps.Teacher.CreateAssignment)
+			teacher.GET("/classes/:classId/assignments/:assignmentId", deps.Teacher.AssignmentMatrix)
+			teacher.POST("/classes/:classId/assignments/:assignmentId/close", deps.Teacher.CloseAssignment)
+			teacher.GET("/classes/:classId/assignments/:assignmentId/assessment", deps.Teacher.AssessmentResults)
+			teacher.POST("/classes/:classId/assignments/:assignmentId/assessment/release", deps.Teacher.ReleaseAssessment)
+			teacher.GET("/classes/:classId/projects", deps.Teacher.ClassProjects)
+			teacher.POST("/classes/:classId/projects/:projectId/moderate", deps.Teacher.ModerateProject)
 			teacher.PATCH("/classes/:classId/hint-limit", deps.Teacher.UpdateHintLimit)
+			teacher.GET("/classes/:classId/controls", deps.Teacher.ClassControls)
+			teacher.PUT("/classes/:classId/controls", deps.Teacher.UpdateClassControls)
+			teacher.PUT("/classes/:classId/unlock-policy", deps.Teacher.SetClassUnlockPolicy)
+			teacher.DELETE("/classes/:classId/unlock-policy", deps.Teacher.ClearClassUnlockPolicy)
+			teacher.GET("/classes/:classId/students/:studentId/unlocks", deps.Teacher.StudentUnlocks)
+			teacher.POST("/classes/:classId/students/:studentId/unlocks", deps.Teacher.UnlockLevelForStudent)
+			teacher.DELETE("/classes/:classId/students/:studentId/unlocks/:levelId", deps.Teacher.RevokeLevelUnlock)
 			teacher.POST("/classes/:classId/assign-course", deps.Teacher.AssignCourse)
+			teacher.GET("/levels/:levelId/revisions", deps.Teacher.LevelRevisions)
+			teacher.POST("/levels/:levelId/revisions", deps.Teacher.CreateLevelDraft)
+			teacher.POST("/levels/:levelId/revisions/:revision/publish", deps.Teacher.PublishLevelRevision)
+			teacher.GET("/levels/:levelId/affected-students", deps.Teacher.AffectedStudents)
+			teacher.POST("/levels/:levelId/migrate", deps.Teacher.MigrateLevelProgress)
+			teacher.GET("/exports", deps.Teacher.Exports)
+			teacher.POST("/exports", deps.Teacher.CreateExport)
+			teacher.GET("/exports/:exportId", deps.Teacher.Export)
+			teacher.GET("/exports/:exportId/download", deps.Teacher.DownloadExport)
 			teacher.GET("/works/pending", deps.Teacher.PendingWorks)
 			teacher.POST("/works/:workId/review", deps.Teacher.ReviewWork)
 		}
@@ -96,19 +206,38 @@ func registerRoutes(engine *gin.Engine, deps Dependencies) {
 			parent.GET("/children", deps.Parent.Children)
 			parent.GET("/children/:childId/weekly-report", deps.Parent.WeeklyReport)
 			parent.GET("/children/:childId/progress", deps.Parent.Progress)
+			parent.GET("/children/:childId/activity", deps.Parent.Activity)
+			parent.GET("/children/:childId/levels/:levelId/attempts", deps.Parent.Attempts)
+			parent.GET("/children/:childId/attempts/:attemptId", deps.Parent.Attempt)
+			parent.GET("/children/:childId/attempts/:attemptId/replay", deps.Parent.Replay)
+			parent.GET("/exports", deps.Parent.Exports)
+			parent.POST("/exports", deps.Parent.CreateExport)
+			parent.GET("/exports/:exportId", deps.Parent.Export)
+			parent.GET("/exports/:exportId/download", deps.Parent.DownloadExport)
 			parent.GET("/settings", deps.Parent.Settings)
 			parent.PUT("/settings", deps.Parent.UpdateSettings)
 		}
+
+		admin := api.Group("/admin")
+		{
+			admin.GET("/avatar-items", deps.Admin.AvatarItems)
+			admin.PUT("/avatar-items/:itemId", deps.Admin.SaveAvatarItem)
+			admin.DELETE("/avatar-items/:itemId", deps.Admin.DeleteAvatarItem)
+			admin.GET("/students/:studentId/wallet", deps.Admin.StudentWallet)
+			admin.POST("/students/:studentId/avatar-items", deps.Admin.GrantAvatarItem)
+		}
 	}
 
 	engine.GET("/api/student/run/stream", deps.WS.Stream)
+	engine.GET("/api/teacher/classes/:classId/monitor", deps.WS.Monitor)
 }
 
 func configureCORS(cfg config.Config) gin.HandlerFunc {
 	corsConfig := cors.Config{
 		AllowCredentials: true,
-		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodOptions},
-		AllowHeaders:     []string{"Authorization", "Content-Type", "Idempotency-Key", "X-Requested-With", "X-User-Id", "x-user-id"},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:     []string{"Accept-Language", "Authorization", "Content-Type", "Idempotency-Key", "X-Request-Id", "X-Requested-With", "X-User-Id", "x-user-id"},
+		ExposeHeaders:    []string{"X-Request-Id"},
 		MaxAge:           12 * time.Hour,
 	}
 	if len(cfg.CORSOrigins) == 0 {
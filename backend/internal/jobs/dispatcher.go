@@ -7,6 +7,17 @@ import (
 	"github.com/hibiken/asynq"
 )
 
+// Task types handled by the worker.
+const (
+	TypeLevelRun = "student:level:run"
+	TypeExport   = "export:generate"
+)
+
+// exportPayload identifies the export a task generates.
+type exportPayload struct {
+	ExportID string `json:"exportId"`
+}
+
 // Dispatcher enqueues background jobs for long running tasks.
 type Dispatcher struct {
 	client *asynq.Client
@@ -23,10 +34,22 @@ func (d *Dispatcher) EnqueueRunTask(ctx context.Context, payload any) (string, e
 	if err != nil {
 		return "", err
 	}
-	task := asynq.NewTask("student:level:run", body)
+	task := asynq.NewTask(TypeLevelRun, body)
 	info, err := d.client.EnqueueContext(ctx, task)
 	if err != nil {
 		return "", err
 	}
 	return info.ID, nil
 }
+
+// EnqueueExport submits the generation of an export file. Exports are not
+// retried: a failure is recorded on the export for the user to see.
+func (d *Dispatcher) EnqueueExport(ctx context.Context, exportID string) error {
+	body, err := json.Marshal(exportPayload{ExportID: exportID})
+	if err != nil {
+		return err
+	}
+	task := asynq.NewTask(TypeExport, body, asynq.MaxRetry(0))
+	_, err = d.client.EnqueueContext(ctx, task)
+	return err
+}
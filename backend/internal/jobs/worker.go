@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/platform/config"
+)
+
+// ExportProcessor generates the file of a queued export.
+type ExportProcessor interface {
+	Process(ctx context.Context, exportID string) error
+}
+
+// Worker runs the handlers of background jobs.
+type Worker struct {
+	server *asynq.Server
+	mux    *asynq.ServeMux
+	log    *zap.Logger
+}
+
+// NewWorker creates a worker that consumes tasks from redis with the
+// configured concurrency and queue weights.
+func NewWorker(redis asynq.RedisClientOpt, cfg config.AsynqConfig, log *zap.Logger) *Worker {
+	server := asynq.NewServer(redis, asynq.Config{
+		Concurrency: cfg.Concurrency,
+		Queues:      cfg.Queues,
+		Logger:      log.Sugar(),
+	})
+	return &Worker{server: server, mux: asynq.NewServeMux(), log: log}
+}
+
+// HandleExports routes export tasks to the processor.
+func (w *Worker) HandleExports(processor ExportProcessor) {
+	w.mux.HandleFunc(TypeExport, func(ctx context.Context, task *asynq.Task) error {
+		var payload exportPayload
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return fmt.Errorf("decode export payload: %v: %w", err, asynq.SkipRetry)
+		}
+		if err := processor.Process(ctx, payload.ExportID); err != nil {
+			w.log.Warn("export failed", zap.String("export_id", payload.ExportID), zap.Error(err))
+			return fmt.Errorf("export %s: %v: %w", payload.ExportID, err, asynq.SkipRetry)
+		}
+		return nil
+	})
+}
+
+// Run processes tasks until the context is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	if err := w.server.Start(w.mux); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	w.server.Shutdown()
+	return nil
+}
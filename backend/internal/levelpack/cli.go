@@ -0,0 +1,202 @@
+package levelpack
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+const cliUsage = `usage: api-go levelpack <command> [flags]
+
+commands:
+  validate FILE                         check a pack locally with the simulator
+  export  --course ID [-o FILE]         download a course from the server
+  import  [--dry-run] FILE              upload a pack to the server
+
+common flags for export and import:
+  --server URL     API base URL (default http://localhost:8080)
+  --teacher ID     teacher id sent as x-user-id (default teacher-1)
+`
+
+// Run executes the levelpack command line and returns the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "validate":
+		err = runValidate(args[1:], stdout)
+	case "export":
+		err = runExport(args[1:], stdout)
+	case "import":
+		err = runImport(args[1:], stdout)
+	default:
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "levelpack:", err)
+		return 1
+	}
+	return 0
+}
+
+// FormatFromPath guesses the pack format from a file extension.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatJSON
+	}
+}
+
+func runValidate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("validate needs exactly one FILE")
+	}
+
+	path := flags.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	pack, err := Decode(data, FormatFromPath(path))
+	if err != nil {
+		return err
+	}
+
+	simulator := student.New()
+	failed := 0
+	for _, chapter := range pack.Course.Chapters {
+		for _, level := range chapter.Levels {
+			check, err := simulator.ValidateLevel(context.Background(), level.Definition())
+			var invalid *student.LevelValidationError
+			switch {
+			case errors.As(err, &invalid):
+				failed++
+				fmt.Fprintf(stdout, "FAIL %s\n", level.ID)
+				for _, problem := range invalid.Problems {
+					fmt.Fprintf(stdout, "  - %s\n", problem)
+				}
+			case err != nil:
+				return err
+			default:
+				fmt.Fprintf(stdout, "ok   %s (shortest %d steps)\n", level.ID, check.ShortestSteps)
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d level(s) failed validation", failed)
+	}
+	return nil
+}
+
+func runExport(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	server := flags.String("server", "http://localhost:8080", "API base URL")
+	teacher := flags.String("teacher", "teacher-1", "teacher id")
+	course := flags.String("course", "", "course id")
+	format := flags.String("format", "", "json or yaml (default from -o, else json)")
+	output := flags.String("o", "", "output file (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *course == "" {
+		return errors.New("export needs --course")
+	}
+	if *format == "" {
+		*format = FormatJSON
+		if *output != "" {
+			*format = FormatFromPath(*output)
+		}
+	}
+
+	endpoint := fmt.Sprintf("%s/api/teacher/courses/%s/export?format=%s", strings.TrimRight(*server, "/"), url.PathEscape(*course), url.QueryEscape(*format))
+	body, err := call(http.MethodGet, endpoint, *teacher, "", nil)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = stdout.Write(body)
+		return err
+	}
+	return os.WriteFile(*output, body, 0o644)
+}
+
+func runImport(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	server := flags.String("server", "http://localhost:8080", "API base URL")
+	teacher := flags.String("teacher", "teacher-1", "teacher id")
+	dryRun := flags.Bool("dry-run", false, "only report the changes")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("import needs exactly one FILE")
+	}
+
+	path := flags.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	format := FormatFromPath(path)
+	contentType := "application/json"
+	if format == FormatYAML {
+		contentType = "application/yaml"
+	}
+
+	endpoint := fmt.Sprintf("%s/api/teacher/courses/import?dryRun=%t", strings.TrimRight(*server, "/"), *dryRun)
+	body, err := call(http.MethodPost, endpoint, *teacher, contentType, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(append(body, '\n'))
+	return err
+}
+
+func call(method, endpoint, teacherID, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-user-id", teacherID)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, endpoint, resp.Status, strings.TrimSpace(string(payload)))
+	}
+	return payload, nil
+}
@@ -0,0 +1,367 @@
+// Package levelpack defines the portable level pack format used to share and
+// back up curricula: a course with its chapters, levels and compendium
+// entries, serialised as JSON or YAML and tagged with a schema version.
+package levelpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// SchemaVersion is the pack schema version written by Encode and the only one
+// Decode accepts.
+const SchemaVersion = 1
+
+// Supported serialisation formats.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+var (
+	ErrUnsupportedFormat = apperr.New(apperr.KindInvalid, "levelpack.unsupported_format")
+	ErrUnsupportedSchema = apperr.New(apperr.KindInvalid, "levelpack.unsupported_schema")
+	ErrMalformedPack     = apperr.New(apperr.KindInvalid, "levelpack.malformed")
+	ErrInvalidSchema     = apperr.New(apperr.KindInvalid, "levelpack.invalid_schema")
+)
+
+// Pack is the root document of a level pack.
+type Pack struct {
+	SchemaVersion int               `json:"schemaVersion" yaml:"schemaVersion"`
+	Course        Course            `json:"course" yaml:"course"`
+	Compendium    []CompendiumEntry `json:"compendium,omitempty" yaml:"compendium,omitempty"`
+}
+
+// Course is the course carried by a pack.
+type Course struct {
+	ID          string    `json:"id" yaml:"id"`
+	Name        string    `json:"name" yaml:"name"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Chapters    []Chapter `json:"chapters" yaml:"chapters"`
+}
+
+// Chapter is an ordered group of levels.
+type Chapter struct {
+	ID      string  `json:"id" yaml:"id"`
+	Title   string  `json:"title" yaml:"title"`
+	Summary string  `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Levels  []Level `json:"levels" yaml:"levels"`
+	// Translations holds the title and summary in other locales.
+	Translations map[string]ChapterText `json:"translations,omitempty" yaml:"translations,omitempty"`
+}
+
+// ChapterText is the text of a chapter in one locale.
+type ChapterText struct {
+	Title   string `json:"title,omitempty" yaml:"title,omitempty"`
+	Summary string `json:"summary,omitempty" yaml:"summary,omitempty"`
+}
+
+// Level is a playable level definition.
+type Level struct {
+	ID            string   `json:"id" yaml:"id"`
+	Name          string   `json:"name" yaml:"name"`
+	Width         int      `json:"width" yaml:"width"`
+	Height        int      `json:"height" yaml:"height"`
+	Tiles         []Tile   `json:"tiles" yaml:"tiles"`
+	Start         Position `json:"start" yaml:"start"`
+	Goal          Goal     `json:"goal" yaml:"goal"`
+	BestSteps     int      `json:"bestSteps,omitempty" yaml:"bestSteps,omitempty"`
+	Hints         []string `json:"hints,omitempty" yaml:"hints,omitempty"`
+	AllowedBlocks []string `json:"allowedBlocks,omitempty" yaml:"allowedBlocks,omitempty"`
+	Comic         string   `json:"comic,omitempty" yaml:"comic,omitempty"`
+	Rewards       Rewards  `json:"rewards,omitempty" yaml:"rewards,omitempty"`
+	// Translations holds the name, hints and comic in other locales.
+	Translations map[string]LevelText `json:"translations,omitempty" yaml:"translations,omitempty"`
+}
+
+// LevelText is the text of a level in one locale.
+type LevelText struct {
+	Name  string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Hints []string `json:"hints,omitempty" yaml:"hints,omitempty"`
+	Comic string   `json:"comic,omitempty" yaml:"comic,omitempty"`
+}
+
+// Tile is a single map cell. Cells not listed are not walkable.
+type Tile struct {
+	X           int    `json:"x" yaml:"x"`
+	Y           int    `json:"y" yaml:"y"`
+	Walkable    bool   `json:"walkable" yaml:"walkable"`
+	Collectible string `json:"collectible,omitempty" yaml:"collectible,omitempty"`
+}
+
+// Position is a map cell plus a facing direction.
+type Position struct {
+	X      int    `json:"x" yaml:"x"`
+	Y      int    `json:"y" yaml:"y"`
+	Facing string `json:"facing" yaml:"facing"`
+}
+
+// Point is a map cell.
+type Point struct {
+	X int `json:"x" yaml:"x"`
+	Y int `json:"y" yaml:"y"`
+}
+
+// Goal lists the victory conditions of a level.
+type Goal struct {
+	Reach        *Point `json:"reach,omitempty" yaml:"reach,omitempty"`
+	Collectibles *int   `json:"collectibles,omitempty" yaml:"collectibles,omitempty"`
+	StepLimit    *int   `json:"stepLimit,omitempty" yaml:"stepLimit,omitempty"`
+}
+
+// Rewards are granted when a level is completed.
+type Rewards struct {
+	Outfit string `json:"outfit,omitempty" yaml:"outfit,omitempty"`
+	Stars  int    `json:"stars,omitempty" yaml:"stars,omitempty"`
+}
+
+// CompendiumEntry is an encyclopedia card unlocked within a chapter.
+type CompendiumEntry struct {
+	ID          string `json:"id" yaml:"id"`
+	ChapterID   string `json:"chapterId" yaml:"chapterId"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty" yaml:"imageUrl,omitempty"`
+}
+
+// SchemaError lists structural problems in a pack document.
+type SchemaError struct {
+	Problems []apperr.Problem
+}
+
+func (e *SchemaError) Error() string {
+	return "level pack is invalid: " + apperr.JoinProblems(e.Problems)
+}
+
+// AppError reports the problems as details of ErrInvalidSchema.
+func (e *SchemaError) AppError() *apperr.Error {
+	return ErrInvalidSchema.With("problems", e.Problems)
+}
+
+// Decode parses a pack in the given format and checks its structure.
+func Decode(data []byte, format string) (Pack, error) {
+	var pack Pack
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&pack); err != nil {
+			return Pack{}, fmt.Errorf("%w: %v", ErrMalformedPack, err)
+		}
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&pack); err != nil {
+			return Pack{}, fmt.Errorf("%w: %v", ErrMalformedPack, err)
+		}
+	default:
+		return Pack{}, ErrUnsupportedFormat
+	}
+	if err := pack.Validate(); err != nil {
+		return Pack{}, err
+	}
+	return pack, nil
+}
+
+// Encode serialises a pack in the given format.
+func Encode(pack Pack, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(pack, "", "  ")
+	case FormatYAML:
+		return yaml.Marshal(pack)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Validate checks the schema version, required fields and ID uniqueness. Level
+// playability is checked separately with the simulator.
+func (p Pack) Validate() error {
+	if p.SchemaVersion != SchemaVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSchema, p.SchemaVersion)
+	}
+
+	var problems []apperr.Problem
+	fail := func(path, code string, params ...any) {
+		problems = append(problems, apperr.NewProblem(path, code, params...))
+	}
+	if strings.TrimSpace(p.Course.Name) == "" {
+		fail("course.name", "required")
+	}
+	chapters := make(map[string]struct{})
+	levels := make(map[string]struct{})
+	for ci, chapter := range p.Course.Chapters {
+		if strings.TrimSpace(chapter.Title) == "" {
+			fail(fmt.Sprintf("course.chapters[%d].title", ci), "required")
+		}
+		for locale := range chapter.Translations {
+			if !student.IsTranslationLocale(locale) {
+				fail(fmt.Sprintf("course.chapters[%d].translations", ci), "unsupported_locale", "locale", locale)
+			}
+		}
+		if chapter.ID != "" {
+			if _, dup := chapters[chapter.ID]; dup {
+				fail(fmt.Sprintf("course.chapters[%d].id", ci), "duplicate", "id", chapter.ID)
+			}
+			chapters[chapter.ID] = struct{}{}
+		}
+		for li, level := range chapter.Levels {
+			if level.ID == "" {
+				fail(fmt.Sprintf("course.chapters[%d].levels[%d].id", ci, li), "required")
+				continue
+			}
+			if _, dup := levels[level.ID]; dup {
+				fail(fmt.Sprintf("course.chapters[%d].levels[%d].id", ci, li), "duplicate", "id", level.ID)
+			}
+			levels[level.ID] = struct{}{}
+		}
+	}
+	entries := make(map[string]struct{})
+	for i, entry := range p.Compendium {
+		if entry.ID == "" || strings.TrimSpace(entry.Name) == "" {
+			fail(fmt.Sprintf("compendium[%d]", i), "required")
+			continue
+		}
+		if _, dup := entries[entry.ID]; dup {
+			fail(fmt.Sprintf("compendium[%d].id", i), "duplicate", "id", entry.ID)
+		}
+		entries[entry.ID] = struct{}{}
+		if _, ok := chapters[entry.ChapterID]; !ok {
+			fail(fmt.Sprintf("compendium[%d].chapterId", i), "not_found", "id", entry.ChapterID)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &SchemaError{Problems: problems}
+	}
+	return nil
+}
+
+// FromDefinition converts a service level definition into its pack form.
+func FromDefinition(def student.LevelDefinition) Level {
+	level := Level{
+		ID:            def.ID,
+		Name:          def.Name,
+		Width:         def.Width,
+		Height:        def.Height,
+		Tiles:         make([]Tile, len(def.Tiles)),
+		Start:         Position{X: def.Start.X, Y: def.Start.Y, Facing: string(def.Start.Facing)},
+		Goal:          Goal{Collectibles: def.Goal.Collectibles, StepLimit: def.Goal.StepLimit},
+		BestSteps:     def.BestSteps,
+		Hints:         append([]string(nil), def.Hints...),
+		AllowedBlocks: append([]string(nil), def.AllowedBlocks...),
+		Comic:         def.Comic,
+		Rewards:       Rewards{Outfit: def.Rewards.Outfit, Stars: def.Rewards.Stars},
+	}
+	for i, tile := range def.Tiles {
+		level.Tiles[i] = Tile{X: tile.X, Y: tile.Y, Walkable: tile.Walkable, Collectible: tile.Collectible}
+	}
+	if def.Goal.Reach != nil {
+		level.Goal.Reach = &Point{X: def.Goal.Reach.X, Y: def.Goal.Reach.Y}
+	}
+	if len(def.Translations) > 0 {
+		level.Translations = make(map[string]LevelText, len(def.Translations))
+		for locale, text := range def.Translations {
+			level.Translations[locale] = LevelText{Name: text.Name, Hints: append([]string(nil), text.Hints...), Comic: text.Comic}
+		}
+	}
+	return level
+}
+
+// Definition converts the pack level into a service level definition.
+func (l Level) Definition() student.LevelDefinition {
+	def := student.LevelDefinition{
+		ID:            l.ID,
+		Name:          l.Name,
+		Width:         l.Width,
+		Height:        l.Height,
+		Tiles:         make([]student.Tile, len(l.Tiles)),
+		Start:         student.Position{X: l.Start.X, Y: l.Start.Y, Facing: student.Direction(l.Start.Facing)},
+		Goal:          student.LevelGoal{Collectibles: l.Goal.Collectibles, StepLimit: l.Goal.StepLimit},
+		BestSteps:     l.BestSteps,
+		Hints:         append([]string{}, l.Hints...),
+		AllowedBlocks: append([]string{}, l.AllowedBlocks...),
+		Comic:         l.Comic,
+		Rewards:       student.LevelRewards{Outfit: l.Rewards.Outfit, Stars: l.Rewards.Stars},
+	}
+	for i, tile := range l.Tiles {
+		def.Tiles[i] = student.Tile{X: tile.X, Y: tile.Y, Walkable: tile.Walkable, Collectible: tile.Collectible}
+	}
+	if l.Goal.Reach != nil {
+		def.Goal.Reach = &student.GoalPosition{X: l.Goal.Reach.X, Y: l.Goal.Reach.Y}
+	}
+	if len(l.Translations) > 0 {
+		def.Translations = make(map[string]student.LevelText, len(l.Translations))
+		for locale, text := range l.Translations {
+			def.Translations[locale] = student.LevelText{Name: text.Name, Hints: append([]string(nil), text.Hints...), Comic: text.Comic}
+		}
+	}
+	return def
+}
+
+// ChapterTranslations converts service chapter translations into their pack
+// form.
+func ChapterTranslations(translations map[string]student.ChapterText) map[string]ChapterText {
+	if len(translations) == 0 {
+		return nil
+	}
+	converted := make(map[string]ChapterText, len(translations))
+	for locale, text := range translations {
+		converted[locale] = ChapterText{Title: text.Title, Summary: text.Summary}
+	}
+	return converted
+}
+
+// ServiceTranslations converts the chapter translations into their service
+// form.
+func (c Chapter) ServiceTranslations() map[string]student.ChapterText {
+	if len(c.Translations) == 0 {
+		return nil
+	}
+	converted := make(map[string]student.ChapterText, len(c.Translations))
+	for locale, text := range c.Translations {
+		converted[locale] = student.ChapterText{Title: text.Title, Summary: text.Summary}
+	}
+	return converted
+}
+
+// Diff lists the top-level fields that differ between the current level and
+// the one in a pack. Empty and missing lists are treated alike, and a missing
+// best step count matches any value since it is filled in by the solver.
+func Diff(current, next Level) []string {
+	var fields []string
+	differs := func(name string, a, b any) {
+		left, _ := json.Marshal(a)
+		right, _ := json.Marshal(b)
+		if !bytes.Equal(left, right) {
+			fields = append(fields, name)
+		}
+	}
+	differsList := func(name string, a, b any, empty bool) {
+		if !empty {
+			differs(name, a, b)
+		}
+	}
+	differs("name", current.Name, next.Name)
+	differs("size", [2]int{current.Width, current.Height}, [2]int{next.Width, next.Height})
+	differsList("tiles", current.Tiles, next.Tiles, len(current.Tiles) == 0 && len(next.Tiles) == 0)
+	differs("start", current.Start, next.Start)
+	differs("goal", current.Goal, next.Goal)
+	if next.BestSteps != 0 {
+		differs("bestSteps", current.BestSteps, next.BestSteps)
+	}
+	differsList("hints", current.Hints, next.Hints, len(current.Hints) == 0 && len(next.Hints) == 0)
+	differsList("allowedBlocks", current.AllowedBlocks, next.AllowedBlocks, len(current.AllowedBlocks) == 0 && len(next.AllowedBlocks) == 0)
+	differs("comic", current.Comic, next.Comic)
+	differs("rewards", current.Rewards, next.Rewards)
+	differsList("translations", current.Translations, next.Translations, len(current.Translations) == 0 && len(next.Translations) == 0)
+	return fields
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ type Config struct {
 	Redis RedisConfig
 	Asynq AsynqConfig
 
+	Export    ExportConfig
 	RateLimit RateLimitConfig
 	Telemetry TelemetryConfig
 }
@@ -44,6 +46,13 @@ type AsynqConfig struct {
 	Queues      map[string]int
 }
 
+// ExportConfig controls where generated exports are stored and how long
+// they can be downloaded.
+type ExportConfig struct {
+	Dir string
+	TTL time.Duration
+}
+
 // RateLimitConfig controls rate limiting on the HTTP layer.
 type RateLimitConfig struct {
 	PerMinute int
@@ -77,6 +86,10 @@ func Load() Config {
 			Concurrency: getEnvAsInt("ASYNQ_CONCURRENCY", 32),
 			Queues:      parseQueueWeights(getEnv("ASYNQ_QUEUES", "default=10,critical=20")),
 		},
+		Export: ExportConfig{
+			Dir: getEnv("EXPORT_DIR", filepath.Join(os.TempDir(), "codeadventurers-exports")),
+			TTL: getEnvAsDuration("EXPORT_TTL", 24*time.Hour),
+		},
 		RateLimit: RateLimitConfig{
 			PerMinute: getEnvAsInt("RATE_LIMIT_PER_MINUTE", 600),
 		},
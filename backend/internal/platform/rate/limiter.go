@@ -1,12 +1,13 @@
 package rate
 
 import (
-	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
 )
 
 // Limiter provides a token bucket limiter keyed by user identifier or IP.
@@ -46,7 +47,8 @@ func (l *Limiter) Middleware() gin.HandlerFunc {
 			c.Next()
 			return
 		}
-		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		c.Error(apperr.ErrRateLimited)
+		c.Abort()
 	}
 }
 
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrInvalidFileName is returned for names that would leave the store.
+var ErrInvalidFileName = errors.New("storage: invalid file name")
+
+// FileStore keeps generated files such as exports. Names are flat keys chosen
+// by the caller; a missing file is reported as fs.ErrNotExist.
+type FileStore interface {
+	Put(ctx context.Context, name string, data []byte) error
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// LocalFiles stores files in a directory on the local disk.
+type LocalFiles struct {
+	dir string
+}
+
+// NewLocalFiles creates the directory if needed and returns a store for it.
+func NewLocalFiles(dir string) (*LocalFiles, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalFiles{dir: dir}, nil
+}
+
+// Put writes the file through a temporary file so readers never see a
+// partial export.
+func (l *LocalFiles) Put(ctx context.Context, name string, data []byte) error {
+	path, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(l.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open opens a stored file for reading.
+func (l *LocalFiles) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	path, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete removes a stored file. Deleting a missing file is not an error.
+func (l *LocalFiles) Delete(ctx context.Context, name string) error {
+	path, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *LocalFiles) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name[0] == '.' {
+		return "", ErrInvalidFileName
+	}
+	return filepath.Join(l.dir, name), nil
+}
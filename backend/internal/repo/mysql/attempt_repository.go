@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttemptNotFound = errors.New("attempt not found")
+)
+
+// AttemptRepository handles level attempt history using GORM
+type AttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewAttemptRepository creates a new attempt repository
+func NewAttemptRepository(db *gorm.DB) *AttemptRepository {
+	return &AttemptRepository{db: db}
+}
+
+// FindByID retrieves an attempt of a student by ID
+func (r *AttemptRepository) FindByID(ctx context.Context, studentID, id string) (*LevelAttempt, error) {
+	var attempt LevelAttempt
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND student_id = ?", id, studentID).
+		First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAttemptNotFound
+	}
+	return &attempt, err
+}
+
+// FindByStudentAndLevel retrieves a student's attempts on a level, newest first
+func (r *AttemptRepository) FindByStudentAndLevel(ctx context.Context, studentID, levelID string, limit int) ([]LevelAttempt, error) {
+	var attempts []LevelAttempt
+	query := r.db.WithContext(ctx).
+		Omit("program").
+		Where("student_id = ? AND level_id = ?", studentID, levelID).
+		Order("created_at DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Find(&attempts).Error
+	return attempts, err
+}
+
+// Create records a new attempt
+func (r *AttemptRepository) Create(ctx context.Context, attempt *LevelAttempt) error {
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
+
+// PruneByStudentAndLevel keeps only the newest attempts of a student on a level
+func (r *AttemptRepository) PruneByStudentAndLevel(ctx context.Context, studentID, levelID string, keep int) error {
+	var cutoff LevelAttempt
+	err := r.db.WithContext(ctx).
+		Select("created_at").
+		Where("student_id = ? AND level_id = ?", studentID, levelID).
+		Order("created_at DESC").
+		Offset(keep - 1).
+		First(&cutoff).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).
+		Where("student_id = ? AND level_id = ? AND created_at < ?", studentID, levelID, cutoff.CreatedAt).
+		Delete(&LevelAttempt{}).Error
+}
+
+// DeleteOlderThan removes attempts recorded before the cutoff
+func (r *AttemptRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", cutoff).
+		Delete(&LevelAttempt{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteByStudent removes all attempts of a student
+func (r *AttemptRepository) DeleteByStudent(ctx context.Context, studentID string) error {
+	return r.db.WithContext(ctx).
+		Where("student_id = ?", studentID).
+		Delete(&LevelAttempt{}).Error
+}
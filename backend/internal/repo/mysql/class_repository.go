@@ -2,7 +2,9 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"math"
 
 	"gorm.io/gorm"
 )
@@ -140,32 +142,80 @@ func (r *ClassRepository) GetStudentCount(ctx context.Context, classID string) (
 	return count, err
 }
 
-// GetClassStatistics retrieves aggregated statistics for a class
-func (r *ClassRepository) GetClassStatistics(ctx context.Context, classID string) (map[string]interface{}, error) {
-	var result struct {
-		StudentCount       int64
-		AvgCompletedLevels float64
-		AvgStars           float64
-	}
+// ClassStudentStatistics is one student's progress on the levels of the
+// courses assigned to their class.
+type ClassStudentStatistics struct {
+	StudentID       string
+	LevelCount      int
+	CompletedLevels int
+	Stars           int
+	LastCompletedAt sql.NullTime
+}
 
-	err := r.db.WithContext(ctx).Model(&Student{}).
-		Select(`
-			COUNT(DISTINCT user_id) as student_count,
-			COALESCE(AVG(total_completed_levels), 0) as avg_completed_levels,
-			COALESCE(AVG(total_stars), 0) as avg_stars
-		`).
-		Where("class_id = ?", classID).
-		Scan(&result).Error
+// ClassStatistics aggregates the progress of a class. AverageProgress is the
+// mean share of the class levels each student completed and CompletionRate
+// the share of students who completed all of them, both in percent.
+type ClassStatistics struct {
+	StudentCount    int
+	LevelCount      int
+	AverageProgress int
+	CompletionRate  int
+	Students        []ClassStudentStatistics
+}
 
+// classStudentStatisticsQuery counts, for every student of a class, the
+// completed levels and stars on the levels of the class courses in one pass
+// over student_level_progress.
+const classStudentStatisticsQuery = `
+SELECT
+  s.user_id AS student_id,
+  (SELECT COUNT(*) FROM class_courses cc
+     JOIN chapters ch ON ch.course_id = cc.course_id
+     JOIN levels l ON l.chapter_id = ch.id
+   WHERE cc.class_id = ?) AS level_count,
+  COUNT(slp.id) AS completed_levels,
+  COALESCE(SUM(slp.stars), 0) AS stars,
+  MAX(slp.first_completed_at) AS last_completed_at
+FROM students s
+LEFT JOIN student_level_progress slp
+  ON slp.student_id = s.user_id
+  AND slp.status = 'completed'
+  AND slp.level_id IN (
+    SELECT l.id FROM class_courses cc
+      JOIN chapters ch ON ch.course_id = cc.course_id
+      JOIN levels l ON l.chapter_id = ch.id
+    WHERE cc.class_id = ?)
+WHERE s.class_id = ?
+GROUP BY s.user_id
+ORDER BY s.user_id`
+
+// GetClassStatistics derives the statistics of a class from its roster, the
+// courses assigned in class_courses and student_level_progress.
+func (r *ClassRepository) GetClassStatistics(ctx context.Context, classID string) (*ClassStatistics, error) {
+	var students []ClassStudentStatistics
+	err := r.db.WithContext(ctx).
+		Raw(classStudentStatisticsQuery, classID, classID, classID).
+		Scan(&students).Error
 	if err != nil {
 		return nil, err
 	}
 
-	stats := map[string]interface{}{
-		"studentCount":       result.StudentCount,
-		"avgCompletedLevels": result.AvgCompletedLevels,
-		"avgStars":           result.AvgStars,
+	stats := &ClassStatistics{StudentCount: len(students), Students: students}
+	if len(students) == 0 {
+		return stats, nil
 	}
-
+	stats.LevelCount = students[0].LevelCount
+	if stats.LevelCount == 0 {
+		return stats, nil
+	}
+	completed, finished := 0, 0
+	for _, student := range students {
+		completed += student.CompletedLevels
+		if student.CompletedLevels >= stats.LevelCount {
+			finished++
+		}
+	}
+	stats.AverageProgress = int(math.Round(float64(completed) * 100 / float64(stats.LevelCount*len(students))))
+	stats.CompletionRate = int(math.Round(float64(finished) * 100 / float64(len(students))))
 	return stats, nil
-}
\ No newline at end of file
+}
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 
 	"gorm.io/gorm"
@@ -44,12 +45,12 @@ func (r *SandboxProjectRepository) FindByStudentID(ctx context.Context, studentI
 	return projects, err
 }
 
-// FindPublicProjects retrieves all public sandbox projects
+// FindPublicProjects retrieves all published sandbox projects
 func (r *SandboxProjectRepository) FindPublicProjects(ctx context.Context, limit int) ([]SandboxProject, error) {
 	var projects []SandboxProject
 	query := r.db.WithContext(ctx).
-		Where("is_public = ?", true).
-		Order("created_at DESC")
+		Where("status = ?", "published").
+		Order("published_at DESC")
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -59,6 +60,24 @@ func (r *SandboxProjectRepository) FindPublicProjects(ctx context.Context, limit
 	return projects, err
 }
 
+// FindByClassAndStatus retrieves the sandbox projects of a class in a moderation status
+func (r *SandboxProjectRepository) FindByClassAndStatus(ctx context.Context, classID, status string) ([]SandboxProject, error) {
+	var projects []SandboxProject
+	err := r.db.WithContext(ctx).
+		Where("class_id = ? AND status = ?", classID, status).
+		Order("updated_at ASC").
+		Find(&projects).Error
+	return projects, err
+}
+
+// IncrementForkCount records a fork of a sandbox project
+func (r *SandboxProjectRepository) IncrementForkCount(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).
+		Model(&SandboxProject{}).
+		Where("id = ?", id).
+		UpdateColumn("fork_count", gorm.Expr("fork_count + 1")).Error
+}
+
 // Create creates a new sandbox project
 func (r *SandboxProjectRepository) Create(ctx context.Context, project *SandboxProject) error {
 	return r.db.WithContext(ctx).Create(project).Error
@@ -143,11 +162,59 @@ func (r *WorkSubmissionRepository) Create(ctx context.Context, submission *WorkS
 	return r.db.WithContext(ctx).Create(submission).Error
 }
 
+// FindByTarget retrieves a student's submission of a level or sandbox project
+func (r *WorkSubmissionRepository) FindByTarget(ctx context.Context, studentID, kind, targetID string) (*WorkSubmission, error) {
+	column := "level_id"
+	if kind == "project" {
+		column = "project_id"
+	}
+	var submission WorkSubmission
+	err := r.db.WithContext(ctx).
+		Where("student_id = ? AND kind = ? AND "+column+" = ?", studentID, kind, targetID).
+		First(&submission).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWorkSubmissionNotFound
+	}
+	return &submission, err
+}
+
+// Resubmit replaces the content of a submission and puts it back into review
+func (r *WorkSubmissionRepository) Resubmit(ctx context.Context, submission *WorkSubmission) error {
+	result := r.db.WithContext(ctx).Model(&WorkSubmission{}).
+		Where("id = ?", submission.ID).
+		Updates(map[string]interface{}{
+			"title":            submission.Title,
+			"content":          submission.Content,
+			"result":           submission.Result,
+			"status":           "pending",
+			"teacher_feedback": nil,
+			"stars":            nil,
+			"reviewed_by":      nil,
+			"reviewed_at":      nil,
+			"version":          submission.Version,
+			"submitted_at":     submission.SubmittedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWorkSubmissionNotFound
+	}
+	return nil
+}
+
 // UpdateStatus updates the status of a submission
 func (r *WorkSubmissionRepository) UpdateStatus(ctx context.Context, id, status, feedback string) error {
+	return r.Review(ctx, id, status, feedback, sql.NullInt32{}, "")
+}
+
+// Review records a teacher's verdict, feedback, awarded stars and reviewer
+func (r *WorkSubmissionRepository) Review(ctx context.Context, id, status, feedback string, stars sql.NullInt32, reviewerID string) error {
 	updates := map[string]interface{}{
 		"status":           status,
 		"teacher_feedback": feedback,
+		"stars":            stars,
+		"reviewed_by":      sql.NullString{String: reviewerID, Valid: reviewerID != ""},
 	}
 	
 	if status == "approved" || status == "rejected" {
@@ -83,6 +83,24 @@ func (r *LevelRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// UpdateDisplayOrder sets display_order of a chapter's levels to their position in levelIDs
+func (r *LevelRepository) UpdateDisplayOrder(ctx context.Context, chapterID string, levelIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range levelIDs {
+			result := tx.Model(&Level{}).
+				Where("id = ? AND chapter_id = ?", id, chapterID).
+				Update("display_order", i+1)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrLevelNotFound
+			}
+		}
+		return nil
+	})
+}
+
 // ChapterRepository handles chapter data operations using GORM
 type ChapterRepository struct {
 	db *gorm.DB
@@ -155,6 +173,24 @@ func (r *ChapterRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// UpdateDisplayOrder sets display_order of a course's chapters to their position in chapterIDs
+func (r *ChapterRepository) UpdateDisplayOrder(ctx context.Context, courseID string, chapterIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range chapterIDs {
+			result := tx.Model(&Chapter{}).
+				Where("id = ? AND course_id = ?", id, courseID).
+				Update("display_order", i+1)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrChapterNotFound
+			}
+		}
+		return nil
+	})
+}
+
 // CourseRepository handles course data operations using GORM
 type CourseRepository struct {
 	db *gorm.DB
@@ -184,6 +220,16 @@ func (r *CourseRepository) FindAll(ctx context.Context) ([]Course, error) {
 	return courses, err
 }
 
+// FindVisibleToTeacher retrieves built-in courses and the courses authored by the teacher
+func (r *CourseRepository) FindVisibleToTeacher(ctx context.Context, teacherID string) ([]Course, error) {
+	var courses []Course
+	err := r.db.WithContext(ctx).
+		Where("owner_id IS NULL OR owner_id = ?", teacherID).
+		Order("display_order, id").
+		Find(&courses).Error
+	return courses, err
+}
+
 // FindAllWithChapters retrieves all courses with chapters
 func (r *CourseRepository) FindAllWithChapters(ctx context.Context) ([]Course, error) {
 	var courses []Course
@@ -0,0 +1,74 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLevelRevisionNotFound = errors.New("level revision not found")
+	ErrLevelRevisionNotDraft = errors.New("level revision is not a draft")
+)
+
+// LevelRevisionRepository handles immutable level snapshots using GORM
+type LevelRevisionRepository struct {
+	db *gorm.DB
+}
+
+// NewLevelRevisionRepository creates a new level revision repository
+func NewLevelRevisionRepository(db *gorm.DB) *LevelRevisionRepository {
+	return &LevelRevisionRepository{db: db}
+}
+
+// FindByLevelID retrieves all revisions of a level, oldest first
+func (r *LevelRevisionRepository) FindByLevelID(ctx context.Context, levelID string) ([]LevelRevision, error) {
+	var revisions []LevelRevision
+	err := r.db.WithContext(ctx).
+		Where("level_id = ?", levelID).
+		Order("revision").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// FindByRevision retrieves a specific revision of a level
+func (r *LevelRevisionRepository) FindByRevision(ctx context.Context, levelID string, revision int) (*LevelRevision, error) {
+	var record LevelRevision
+	err := r.db.WithContext(ctx).
+		Where("level_id = ? AND revision = ?", levelID, revision).
+		First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLevelRevisionNotFound
+	}
+	return &record, err
+}
+
+// SaveDraft stores a draft revision, replacing an existing draft with the same number
+func (r *LevelRevisionRepository) SaveDraft(ctx context.Context, revision *LevelRevision) error {
+	revision.Status = "draft"
+	return r.db.WithContext(ctx).Save(revision).Error
+}
+
+// Publish marks a draft revision as published and points the level at it
+func (r *LevelRevisionRepository) Publish(ctx context.Context, levelID string, revision int, level *Level) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&LevelRevision{}).
+			Where("level_id = ? AND revision = ? AND status = ?", levelID, revision, "draft").
+			Updates(map[string]interface{}{
+				"status":       "published",
+				"published_at": sql.NullTime{Time: time.Now(), Valid: true},
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLevelRevisionNotDraft
+		}
+
+		level.Revision = revision
+		return tx.Model(level).Where("id = ?", levelID).Updates(level).Error
+	})
+}
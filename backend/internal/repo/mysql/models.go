@@ -121,15 +121,23 @@ func (ParentChild) TableName() string {
 	return "parent_children"
 }
 
-// Class represents a teaching class
+// Class represents a teaching class. InviteMaxUses of zero allows any
+// number of joins.
 type Class struct {
-	ID         string    `gorm:"column:id;primaryKey;size:64" json:"id"`
-	Name       string    `gorm:"column:name;size:128;not null" json:"name"`
-	InviteCode string    `gorm:"column:invite_code;size:32;not null;uniqueIndex" json:"invite_code"`
-	TeacherID  string    `gorm:"column:teacher_id;size:64;not null;index" json:"teacher_id"`
-	HintLimit  int       `gorm:"column:hint_limit;default:3" json:"hint_limit"`
-	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
-	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	ID              string         `gorm:"column:id;primaryKey;size:64" json:"id"`
+	Name            string         `gorm:"column:name;size:128;not null" json:"name"`
+	InviteCode      string         `gorm:"column:invite_code;size:32;not null;uniqueIndex" json:"invite_code"`
+	InviteMaxUses   int            `gorm:"column:invite_max_uses;not null;default:0" json:"invite_max_uses"`
+	InviteUses      int            `gorm:"column:invite_uses;not null;default:0" json:"invite_uses"`
+	InviteExpiresAt sql.NullTime   `gorm:"column:invite_expires_at" json:"invite_expires_at,omitempty"`
+	InviteCreatedAt time.Time      `gorm:"column:invite_created_at;autoCreateTime" json:"invite_created_at"`
+	TeacherID       string         `gorm:"column:teacher_id;size:64;not null;index" json:"teacher_id"`
+	HintLimit       int            `gorm:"column:hint_limit;default:3" json:"hint_limit"`
+	HintWindow      int            `gorm:"column:hint_window_minutes;not null;default:30" json:"hint_window_minutes"`
+	ArchivedAt      sql.NullTime   `gorm:"column:archived_at" json:"archived_at,omitempty"`
+	UnlockPolicy    sql.NullString `gorm:"column:unlock_policy;type:json" json:"unlock_policy,omitempty"` // JSON object; NULL uses the course policies
+	CreatedAt       time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 }
 
 func (Class) TableName() string {
@@ -153,7 +161,9 @@ type Course struct {
 	ID           string         `gorm:"column:id;primaryKey;size:64" json:"id"`
 	Name         string         `gorm:"column:name;size:128;not null" json:"name"`
 	Description  sql.NullString `gorm:"column:description;type:text" json:"description,omitempty"`
+	OwnerID      sql.NullString `gorm:"column:owner_id;size:64;index" json:"owner_id,omitempty"` // NULL for built-in courses
 	DisplayOrder int            `gorm:"column:display_order;default:0;index" json:"display_order"`
+	UnlockPolicy sql.NullString `gorm:"column:unlock_policy;type:json" json:"unlock_policy,omitempty"` // JSON object; NULL unlocks sequentially
 	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 
@@ -200,6 +210,7 @@ type Level struct {
 	Hints         sql.NullString `gorm:"column:hints;type:json" json:"hints,omitempty"`                 // JSON array
 	AllowedBlocks sql.NullString `gorm:"column:allowed_blocks;type:json" json:"allowed_blocks,omitempty"` // JSON array
 	Rewards       sql.NullString `gorm:"column:rewards;type:json" json:"rewards,omitempty"`             // JSON object
+	Revision      int            `gorm:"column:revision;default:1" json:"revision"`                      // currently published revision
 	CreatedAt     time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 	UpdatedAt     time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 
@@ -211,6 +222,20 @@ func (Level) TableName() string {
 	return "levels"
 }
 
+// LevelRevision is an immutable snapshot of a level definition
+type LevelRevision struct {
+	LevelID     string       `gorm:"column:level_id;primaryKey;size:64" json:"level_id"`
+	Revision    int          `gorm:"column:revision;primaryKey" json:"revision"`
+	Status      string       `gorm:"column:status;type:enum('draft','published');default:draft;index" json:"status"`
+	Definition  string       `gorm:"column:definition;type:json;not null" json:"definition"` // JSON object
+	CreatedAt   time.Time    `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	PublishedAt sql.NullTime `gorm:"column:published_at" json:"published_at,omitempty"`
+}
+
+func (LevelRevision) TableName() string {
+	return "level_revisions"
+}
+
 // StudentLevelProgress represents student progress on a specific level
 type StudentLevelProgress struct {
 	ID               int            `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
@@ -223,6 +248,7 @@ type StudentLevelProgress struct {
 	HintsUsed        int            `gorm:"column:hints_used;default:0" json:"hints_used"`
 	TotalDuration    int            `gorm:"column:total_duration;default:0" json:"total_duration"`
 	Attempts         int            `gorm:"column:attempts;default:0" json:"attempts"`
+	LevelRevision    int            `gorm:"column:level_revision;default:1" json:"level_revision"`
 	LastReplayLog    sql.NullString `gorm:"column:last_replay_log;type:json" json:"last_replay_log,omitempty"` // JSON array
 	FirstCompletedAt sql.NullTime   `gorm:"column:first_completed_at" json:"first_completed_at,omitempty"`
 	LastUpdatedAt    time.Time      `gorm:"column:last_updated_at;autoUpdateTime" json:"last_updated_at"`
@@ -236,16 +262,57 @@ func (StudentLevelProgress) TableName() string {
 	return "student_level_progress"
 }
 
+// StudentLevelUnlock represents a level a teacher opened for one student
+type StudentLevelUnlock struct {
+	ID         int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	StudentID  string    `gorm:"column:student_id;size:64;not null;index:idx_student_level_unlock,unique" json:"student_id"`
+	LevelID    string    `gorm:"column:level_id;size:64;not null;index:idx_student_level_unlock,unique" json:"level_id"`
+	UnlockedBy string    `gorm:"column:unlocked_by;size:64;not null" json:"unlocked_by"`
+	UnlockedAt time.Time `gorm:"column:unlocked_at;autoCreateTime" json:"unlocked_at"`
+}
+
+func (StudentLevelUnlock) TableName() string {
+	return "student_level_unlocks"
+}
+
+// LevelAttempt represents a single program run by a student on a level
+type LevelAttempt struct {
+	ID             string         `gorm:"column:id;primaryKey;size:64" json:"id"`
+	StudentID      string         `gorm:"column:student_id;size:64;not null;index:idx_attempt_student_level" json:"student_id"`
+	LevelID        string         `gorm:"column:level_id;size:64;not null;index:idx_attempt_student_level" json:"level_id"`
+	Program        string         `gorm:"column:program;type:json;not null" json:"program"` // JSON array
+	Success        bool           `gorm:"column:success;default:false" json:"success"`
+	Stars          int            `gorm:"column:stars;default:0" json:"stars"`
+	Steps          int            `gorm:"column:steps;default:0" json:"steps"`
+	ErrorCode      sql.NullString `gorm:"column:error_code;size:32" json:"error_code,omitempty"`
+	Misconceptions sql.NullString `gorm:"column:misconceptions;type:json" json:"misconceptions,omitempty"` // JSON array
+	Revision       int            `gorm:"column:level_revision;default:1" json:"level_revision"`
+	Duration       int            `gorm:"column:duration;default:0" json:"duration"`
+	CreatedAt      time.Time      `gorm:"column:created_at;autoCreateTime;index" json:"created_at"`
+}
+
+func (LevelAttempt) TableName() string {
+	return "level_attempts"
+}
+
 // SandboxProject represents a student's sandbox project
 type SandboxProject struct {
-	ID           string         `gorm:"column:id;primaryKey;size:64" json:"id"`
-	StudentID    string         `gorm:"column:student_id;size:64;not null;index" json:"student_id"`
-	Title        string         `gorm:"column:title;size:128;default:Untitled Project" json:"title"`
-	Code         sql.NullString `gorm:"column:code;type:text" json:"code,omitempty"`
-	ThumbnailURL sql.NullString `gorm:"column:thumbnail_url;size:255" json:"thumbnail_url,omitempty"`
-	IsPublic     bool           `gorm:"column:is_public;default:false;index" json:"is_public"`
-	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	ID             string         `gorm:"column:id;primaryKey;size:64" json:"id"`
+	StudentID      string         `gorm:"column:student_id;size:64;not null;index" json:"student_id"`
+	ClassID        sql.NullString `gorm:"column:class_id;size:64;index:idx_sandbox_class_status" json:"class_id,omitempty"`
+	Title          string         `gorm:"column:title;size:128;default:Untitled Project" json:"title"`
+	LevelID        sql.NullString `gorm:"column:level_id;size:64" json:"level_id,omitempty"`
+	MapConfig      sql.NullString `gorm:"column:map_config;type:json" json:"map_config,omitempty"` // JSON object
+	Code           sql.NullString `gorm:"column:code;type:text" json:"code,omitempty"`              // JSON array of blocks
+	ThumbnailURL   sql.NullString `gorm:"column:thumbnail_url;size:255" json:"thumbnail_url,omitempty"`
+	ForkedFrom     sql.NullString `gorm:"column:forked_from;size:64;index" json:"forked_from,omitempty"`
+	ForkCount      int            `gorm:"column:fork_count;default:0" json:"fork_count"`
+	Status         string         `gorm:"column:status;size:16;default:private;index:idx_sandbox_class_status" json:"status"`
+	ModerationNote sql.NullString `gorm:"column:moderation_note;size:255" json:"moderation_note,omitempty"`
+	ModeratedBy    sql.NullString `gorm:"column:moderated_by;size:64" json:"moderated_by,omitempty"`
+	PublishedAt    sql.NullTime   `gorm:"column:published_at" json:"published_at,omitempty"`
+	CreatedAt      time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 }
 
 func (SandboxProject) TableName() string {
@@ -257,12 +324,21 @@ type WorkSubmission struct {
 	ID              string         `gorm:"column:id;primaryKey;size:64" json:"id"`
 	StudentID       string         `gorm:"column:student_id;size:64;not null;index" json:"student_id"`
 	ClassID         sql.NullString `gorm:"column:class_id;size:64;index" json:"class_id,omitempty"`
+	Kind            string         `gorm:"column:kind;type:enum('level','project');default:level" json:"kind"`
 	LevelID         sql.NullString `gorm:"column:level_id;size:64" json:"level_id,omitempty"`
+	ProjectID       sql.NullString `gorm:"column:project_id;size:64;index" json:"project_id,omitempty"`
+	AssignmentID    sql.NullString `gorm:"column:assignment_id;size:64;index" json:"assignment_id,omitempty"`
+	AssignmentItem  sql.NullString `gorm:"column:assignment_item_id;size:64" json:"assignment_item_id,omitempty"`
 	Title           sql.NullString `gorm:"column:title;size:128" json:"title,omitempty"`
 	Content         sql.NullString `gorm:"column:content;type:json" json:"content,omitempty"` // JSON object
+	Result          sql.NullString `gorm:"column:result;type:json" json:"result,omitempty"`   // JSON object
 	Status          string         `gorm:"column:status;type:enum('pending','approved','rejected');default:pending;index" json:"status"`
 	TeacherFeedback sql.NullString `gorm:"column:teacher_feedback;type:text" json:"teacher_feedback,omitempty"`
-	SubmittedAt     time.Time      `gorm:"column:submitted_at;autoCreateTime" json:"submitted_at"`
+	Stars           sql.NullInt32  `gorm:"column:stars" json:"stars,omitempty"`
+	ReviewedBy      sql.NullString `gorm:"column:reviewed_by;size:64" json:"reviewed_by,omitempty"`
+	Version         int            `gorm:"column:version;default:1" json:"version"`
+	CreatedAt       time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	SubmittedAt     time.Time      `gorm:"column:submitted_at" json:"submitted_at"`
 	ReviewedAt      sql.NullTime   `gorm:"column:reviewed_at" json:"reviewed_at,omitempty"`
 }
 
@@ -319,12 +395,19 @@ func (AssetRecord) TableName() string {
 type ExportRecord struct {
 	ID           string         `gorm:"column:id;primaryKey;size:64" json:"id"`
 	UserID       string         `gorm:"column:user_id;size:64;not null;index" json:"user_id"`
+	UserRole     string         `gorm:"column:user_role;type:enum('teacher','parent');not null" json:"user_role"`
 	ExportType   string         `gorm:"column:export_type;size:32;not null" json:"export_type"`
+	Format       string         `gorm:"column:export_format;type:enum('csv','xlsx');default:csv" json:"export_format"`
+	ClassID      sql.NullString `gorm:"column:class_id;size:64" json:"class_id,omitempty"`
+	StudentID    sql.NullString `gorm:"column:student_id;size:64" json:"student_id,omitempty"`
 	FilePath     sql.NullString `gorm:"column:file_path;size:512" json:"file_path,omitempty"`
+	FileName     sql.NullString `gorm:"column:file_name;size:255" json:"file_name,omitempty"`
+	FileSize     int            `gorm:"column:file_size;default:0" json:"file_size"`
 	Status       string         `gorm:"column:status;type:enum('pending','processing','completed','failed');default:pending;index" json:"status"`
 	ErrorMessage sql.NullString `gorm:"column:error_message;type:text" json:"error_message,omitempty"`
 	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 	CompletedAt  sql.NullTime   `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	ExpiresAt    sql.NullTime   `gorm:"column:expires_at;index" json:"expires_at,omitempty"`
 }
 
 func (ExportRecord) TableName() string {
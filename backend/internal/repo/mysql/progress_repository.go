@@ -110,6 +110,11 @@ func (r *ProgressRepository) UpsertProgress(ctx context.Context, progress *Stude
 		}
 	}
 
+	// Move to the newer level revision when progress is earned on it
+	if progress.LevelRevision > existing.LevelRevision {
+		updates["level_revision"] = progress.LevelRevision
+	}
+
 	// Set first_completed_at if not set
 	if !existing.FirstCompletedAt.Valid && progress.FirstCompletedAt.Valid {
 		updates["first_completed_at"] = progress.FirstCompletedAt
@@ -138,11 +143,40 @@ func (r *ProgressRepository) UpdateStatus(ctx context.Context, studentID, levelI
 	return nil
 }
 
+// FindOutdatedByLevel retrieves completed progress earned on a revision older than the given one
+func (r *ProgressRepository) FindOutdatedByLevel(ctx context.Context, levelID string, revision int) ([]StudentLevelProgress, error) {
+	var progress []StudentLevelProgress
+	err := r.db.WithContext(ctx).
+		Where("level_id = ? AND status = ? AND level_revision < ?", levelID, "completed", revision).
+		Order("student_id").
+		Find(&progress).Error
+	return progress, err
+}
+
+// MigrateRevision moves a student's progress on a level to a new revision with re-scored results
+func (r *ProgressRepository) MigrateRevision(ctx context.Context, studentID, levelID string, revision, stars, steps, bestDifference int) error {
+	result := r.db.WithContext(ctx).Model(&StudentLevelProgress{}).
+		Where("student_id = ? AND level_id = ?", studentID, levelID).
+		Updates(map[string]interface{}{
+			"level_revision":  revision,
+			"stars":           stars,
+			"best_steps":      steps,
+			"best_difference": bestDifference,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrProgressNotFound
+	}
+	return nil
+}
+
 // RecordCompletion records a level completion
 func (r *ProgressRepository) RecordCompletion(ctx context.Context, studentID, levelID string, stars, steps, hints, duration int, replayLog string) error {
 	// Get level's best_steps for difference calculation
 	var level Level
-	if err := r.db.WithContext(ctx).Select("best_steps").Where("id = ?", levelID).First(&level).Error; err != nil {
+	if err := r.db.WithContext(ctx).Select("best_steps", "revision").Where("id = ?", levelID).First(&level).Error; err != nil {
 		return err
 	}
 
@@ -158,6 +192,7 @@ func (r *ProgressRepository) RecordCompletion(ctx context.Context, studentID, le
 		HintsUsed:      hints,
 		TotalDuration:  duration,
 		Attempts:       1,
+		LevelRevision:  level.Revision,
 		LastReplayLog:  sql.NullString{String: replayLog, Valid: replayLog != ""},
 		FirstCompletedAt: sql.NullTime{Time: time.Now(), Valid: true},
 	}
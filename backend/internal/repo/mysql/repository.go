@@ -9,9 +9,11 @@ type Repositories struct {
 	Teacher           *TeacherRepository
 	Parent            *ParentRepository
 	Progress          *ProgressRepository
+	Attempt           *AttemptRepository
 	Course            *CourseRepository
 	Chapter           *ChapterRepository
 	Level             *LevelRepository
+	LevelRevision     *LevelRevisionRepository
 	Class             *ClassRepository
 	SandboxProject    *SandboxProjectRepository
 	WorkSubmission    *WorkSubmissionRepository
@@ -27,9 +29,11 @@ func NewRepositories(db *gorm.DB) *Repositories {
 		Teacher:           NewTeacherRepository(db),
 		Parent:            NewParentRepository(db),
 		Progress:          NewProgressRepository(db),
+		Attempt:           NewAttemptRepository(db),
 		Course:            NewCourseRepository(db),
 		Chapter:           NewChapterRepository(db),
 		Level:             NewLevelRepository(db),
+		LevelRevision:     NewLevelRevisionRepository(db),
 		Class:             NewClassRepository(db),
 		SandboxProject:    NewSandboxProjectRepository(db),
 		WorkSubmission:    NewWorkSubmissionRepository(db),
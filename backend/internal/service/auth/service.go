@@ -2,21 +2,24 @@ package auth
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
 )
 
 // Service orchestrates authentication use cases.
 type Service struct {
-	classrooms  map[string]classroom
+	classes     ClassDirectory
 	credentials map[string]map[string]credential
 }
 
-type classroom struct {
-	ClassID string
+// ClassDirectory admits students to classes by invite code. JoinClass returns
+// the class the student joined, or an empty ID when no class uses the code.
+type ClassDirectory interface {
+	JoinClass(ctx context.Context, inviteCode, studentID, name string) (string, error)
 }
 
 type credential struct {
@@ -37,25 +40,19 @@ type Profile struct {
 
 // ErrInvalidCredentials is returned when the identifier/password combination
 // does not match the expected record.
-var ErrInvalidCredentials = errors.New("invalid credentials")
+var ErrInvalidCredentials = apperr.New(apperr.KindUnauthorized, "auth.invalid_credentials")
 
 // ErrUnknownRole indicates an unsupported role was supplied.
-var ErrUnknownRole = errors.New("unknown role")
+var ErrUnknownRole = apperr.New(apperr.KindInvalid, "auth.unknown_role")
 
 // ErrInvalidInviteCode indicates the class invite code is not recognised.
-var ErrInvalidInviteCode = errors.New("invalid invite code")
+var ErrInvalidInviteCode = apperr.New(apperr.KindInvalid, "auth.invalid_invite_code")
 
 // New creates a new authentication service instance populated with demo data.
-func New() *Service {
+// Class invite codes are resolved by classes.
+func New(classes ClassDirectory) *Service {
 	return &Service{
-		classrooms: map[string]classroom{
-			normalizeInviteCode("ABC123"):    {ClassID: "class-1"},
-			normalizeInviteCode("DEF456"):    {ClassID: "class-2"},
-			normalizeInviteCode("CA-CLASS-1"): {ClassID: "class-1"},
-			normalizeInviteCode("CA-CLASS-2"): {ClassID: "class-2"},
-			normalizeInviteCode("CA-CLASS-3"): {ClassID: "class-3"},
-			normalizeInviteCode("A-CLASS-2"):  {ClassID: "class-2"},
-		},
+		classes: classes,
 		credentials: map[string]map[string]credential{
 			"teacher": {
 				"teacher-1": {
@@ -97,30 +94,29 @@ func (s *Service) GuestLogin(ctx context.Context, name string) (Profile, error)
 	}, nil
 }
 
-// ClassLogin validates the class invite code and returns a student profile.
+// ClassLogin redeems the class invite code and returns the profile of the
+// student who joined the class.
 func (s *Service) ClassLogin(ctx context.Context, inviteCode, name string) (Profile, error) {
-	class, ok := s.classrooms[normalizeInviteCode(inviteCode)]
-	if !ok {
-		return Profile{}, ErrInvalidInviteCode
-	}
 	trimmed := strings.TrimSpace(name)
 	if trimmed == "" {
 		trimmed = "匿名学员"
 	}
+	userID := fmt.Sprintf("student-%s", uuid.NewString())
+	classID, err := s.classes.JoinClass(ctx, inviteCode, userID, trimmed)
+	if err != nil {
+		return Profile{}, err
+	}
+	if classID == "" {
+		return Profile{}, ErrInvalidInviteCode
+	}
 	return Profile{
-		UserID:  fmt.Sprintf("student-%s", uuid.NewString()),
+		UserID:  userID,
 		Name:    trimmed,
 		Role:    "student",
-		ClassID: class.ClassID,
+		ClassID: classID,
 	}, nil
 }
 
-func normalizeInviteCode(code string) string {
-	trimmed := strings.TrimSpace(code)
-	upper := strings.ToUpper(trimmed)
-	return strings.ReplaceAll(upper, "-", "")
-}
-
 // CredentialLogin validates credentials for the provided role and returns the
 // associated profile when successful.
 func (s *Service) CredentialLogin(ctx context.Context, identifier, password, role string) (Profile, error) {
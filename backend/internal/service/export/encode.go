@@ -0,0 +1,138 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// encodeCSV writes the table as CSV. The UTF-8 byte order mark lets
+// spreadsheet programs detect the encoding of Chinese names, and text that
+// would start a formula is quoted with an apostrophe.
+func encodeCSV(data table) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(data.header); err != nil {
+		return nil, err
+	}
+	for _, row := range data.rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			switch value := cell.(type) {
+			case nil:
+			case int:
+				record[i] = strconv.Itoa(value)
+			case string:
+				if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+					value = "'" + value
+				}
+				record[i] = value
+			default:
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// xlsxParts are the fixed parts of a workbook with a single sheet.
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// encodeXLSX writes the table as a workbook with one sheet. Strings are
+// stored inline so no shared string table is needed.
+func encodeXLSX(data table) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, part := range xlsxParts {
+		if err := writePart(archive, part.name, []byte(part.body)); err != nil {
+			return nil, err
+		}
+	}
+
+	var workbook bytes.Buffer
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	xml.EscapeText(&workbook, []byte(data.sheet))
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	if err := writePart(archive, "xl/workbook.xml", workbook.Bytes()); err != nil {
+		return nil, err
+	}
+
+	var sheet bytes.Buffer
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	header := make([]any, len(data.header))
+	for i, title := range data.header {
+		header[i] = title
+	}
+	writeRow(&sheet, 1, header)
+	for i, row := range data.rows {
+		writeRow(&sheet, i+2, row)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	if err := writePart(archive, "xl/worksheets/sheet1.xml", sheet.Bytes()); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writePart(archive *zip.Writer, name string, body []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+func writeRow(buf *bytes.Buffer, number int, cells []any) {
+	fmt.Fprintf(buf, `<row r="%d">`, number)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(number)
+		switch value := cell.(type) {
+		case nil:
+		case int:
+			fmt.Fprintf(buf, `<c r="%s"><v>%d</v></c>`, ref, value)
+		default:
+			fmt.Fprintf(buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(buf, []byte(fmt.Sprint(value)))
+			buf.WriteString(`</t></is></c>`)
+		}
+	}
+	buf.WriteString(`</row>`)
+}
+
+// columnName returns the spreadsheet column letters for a zero-based index:
+// A, B, ..., Z, AA, AB and so on.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
@@ -0,0 +1,410 @@
+// Package export produces downloadable gradebooks, progress reports and
+// attempt histories. Exports are requested over HTTP, generated by a
+// background job and kept in a file store until they expire.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+	"github.com/codeadventurers/api-go/internal/platform/storage"
+	"github.com/codeadventurers/api-go/internal/service/parent"
+	"github.com/codeadventurers/api-go/internal/service/teacher"
+)
+
+// Type is what an export contains.
+type Type string
+
+const (
+	// TypeClassGradebook is the rubric gradebook of a class, teachers only.
+	TypeClassGradebook Type = "class_gradebook"
+	// TypeStudentProgress is the level progress of one student.
+	TypeStudentProgress Type = "student_progress"
+	// TypeAttemptHistory is every recorded run of one student.
+	TypeAttemptHistory Type = "attempt_history"
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// Status follows an export through the background job. The values match the
+// status column of export_records.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+)
+
+// Role is who requested an export; it decides which data the user may see.
+type Role string
+
+const (
+	RoleTeacher Role = "teacher"
+	RoleParent  Role = "parent"
+)
+
+// Limits for exports. A user may have maxActiveExports exports waiting for
+// the worker at once; records are forgotten recordRetention after they
+// expire.
+const (
+	maxActiveExports = 5
+	recordRetention  = 7 * 24 * time.Hour
+	purgeInterval    = time.Hour
+)
+
+var (
+	// ErrExportInvalid indicates an export request with a missing or unknown
+	// field.
+	ErrExportInvalid = apperr.New(apperr.KindInvalid, "export.invalid")
+	// ErrExportNotFound indicates the export does not exist for the user.
+	ErrExportNotFound = apperr.New(apperr.KindNotFound, "export.not_found")
+	// ErrExportNotReady indicates the export has not completed.
+	ErrExportNotReady = apperr.New(apperr.KindConflict, "export.not_ready")
+	// ErrExportExpired indicates the export file has been removed.
+	ErrExportExpired = apperr.New(apperr.KindGone, "export.expired")
+	// ErrTooManyExports indicates the user already has too many exports
+	// waiting.
+	ErrTooManyExports = apperr.New(apperr.KindTooManyRequests, "export.too_many")
+	// ErrExportUnavailable indicates the export could not be queued.
+	ErrExportUnavailable = apperr.New(apperr.KindInternal, "export.unavailable")
+)
+
+// Queue hands exports to the background worker.
+type Queue interface {
+	EnqueueExport(ctx context.Context, exportID string) error
+}
+
+// Request asks for an export. ClassID is required for teachers; StudentID
+// is required for student exports and names the child for parents.
+type Request struct {
+	Type      Type   `json:"type" binding:"required"`
+	Format    Format `json:"format"`
+	ClassID   string `json:"classId"`
+	StudentID string `json:"studentId"`
+}
+
+// Record tracks one export. Error holds the error code of a failed export.
+// ExpiresAt is set once the file is written; it can be downloaded until then.
+type Record struct {
+	ID          string `json:"id"`
+	UserID      string `json:"userId"`
+	Role        Role   `json:"role"`
+	Type        Type   `json:"type"`
+	Format      Format `json:"format"`
+	ClassID     string `json:"classId,omitempty"`
+	StudentID   string `json:"studentId,omitempty"`
+	Status      Status `json:"status"`
+	FileName    string `json:"fileName,omitempty"`
+	Size        int    `json:"size,omitempty"`
+	Error       string `json:"error,omitempty"`
+	CreatedAt   int64  `json:"createdAt"`
+	CompletedAt int64  `json:"completedAt,omitempty"`
+	ExpiresAt   int64  `json:"expiresAt,omitempty"`
+
+	// filePath is the name of the file in the store, cleared once the file
+	// is removed.
+	filePath string
+}
+
+// ContentType returns the MIME type of the export file.
+func (r Record) ContentType() string {
+	if r.Format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Service keeps export records and generates their files.
+type Service struct {
+	mu       sync.RWMutex
+	records  map[string]*Record
+	teachers *teacher.Service
+	parents  *parent.Service
+	files    storage.FileStore
+	queue    Queue
+	ttl      time.Duration
+}
+
+// New creates the export service. Files are kept in files for ttl after
+// they are written.
+func New(teachers *teacher.Service, parents *parent.Service, files storage.FileStore, queue Queue, ttl time.Duration) *Service {
+	return &Service{
+		records:  make(map[string]*Record),
+		teachers: teachers,
+		parents:  parents,
+		files:    files,
+		queue:    queue,
+		ttl:      ttl,
+	}
+}
+
+// Create records an export requested by a user and queues it for the
+// worker. Access to the class or student is checked now and again when the
+// export runs.
+func (s *Service) Create(ctx context.Context, role Role, userID string, req Request) (Record, error) {
+	if req.Format == "" {
+		req.Format = FormatCSV
+	}
+	if err := s.validate(ctx, role, userID, req); err != nil {
+		return Record{}, err
+	}
+
+	s.mu.Lock()
+	active := 0
+	for _, record := range s.records {
+		if record.UserID == userID && (record.Status == StatusPending || record.Status == StatusProcessing) {
+			active++
+		}
+	}
+	if active >= maxActiveExports {
+		s.mu.Unlock()
+		return Record{}, ErrTooManyExports.With("limit", maxActiveExports)
+	}
+	record := &Record{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Role:      role,
+		Type:      req.Type,
+		Format:    req.Format,
+		ClassID:   req.ClassID,
+		StudentID: req.StudentID,
+		Status:    StatusPending,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	if role == RoleParent {
+		record.ClassID = ""
+	}
+	s.records[record.ID] = record
+	s.mu.Unlock()
+
+	if err := s.queue.EnqueueExport(ctx, record.ID); err != nil {
+		s.fail(record.ID, ErrExportUnavailable)
+		return Record{}, ErrExportUnavailable.Wrap(err)
+	}
+	return s.Get(ctx, role, userID, record.ID)
+}
+
+// List returns the exports of a user, newest first.
+func (s *Service) List(ctx context.Context, role Role, userID string) []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]Record, 0)
+	for _, record := range s.records {
+		if record.UserID == userID && record.Role == role {
+			records = append(records, *record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt > records[j].CreatedAt })
+	return records
+}
+
+// Get returns one export of a user.
+func (s *Service) Get(ctx context.Context, role Role, userID, exportID string) (Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, err := s.owned(role, userID, exportID)
+	if err != nil {
+		return Record{}, err
+	}
+	return *record, nil
+}
+
+// Open returns a completed export of a user with a reader for its file.
+// Callers must close the reader.
+func (s *Service) Open(ctx context.Context, role Role, userID, exportID string) (Record, io.ReadCloser, error) {
+	s.mu.RLock()
+	record, err := s.owned(role, userID, exportID)
+	if err != nil {
+		s.mu.RUnlock()
+		return Record{}, nil, err
+	}
+	snapshot := *record
+	s.mu.RUnlock()
+
+	if snapshot.Status != StatusCompleted {
+		return Record{}, nil, ErrExportNotReady.With("status", snapshot.Status)
+	}
+	if snapshot.filePath == "" || time.Now().UnixMilli() >= snapshot.ExpiresAt {
+		return Record{}, nil, ErrExportExpired.With("exportId", exportID)
+	}
+	file, err := s.files.Open(ctx, snapshot.filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Record{}, nil, ErrExportExpired.With("exportId", exportID)
+	}
+	if err != nil {
+		return Record{}, nil, err
+	}
+	return snapshot, file, nil
+}
+
+// Process generates the file of a pending export. It is run by the
+// background worker; failures are recorded on the export and returned.
+func (s *Service) Process(ctx context.Context, exportID string) error {
+	s.mu.Lock()
+	record, ok := s.records[exportID]
+	if !ok {
+		s.mu.Unlock()
+		return ErrExportNotFound.With("exportId", exportID)
+	}
+	if record.Status != StatusPending {
+		s.mu.Unlock()
+		return nil
+	}
+	record.Status = StatusProcessing
+	snapshot := *record
+	s.mu.Unlock()
+
+	data, err := s.render(ctx, snapshot)
+	if err != nil {
+		s.fail(exportID, err)
+		return err
+	}
+	now := time.Now()
+	filePath := snapshot.ID + "." + string(snapshot.Format)
+	if err := s.files.Put(ctx, filePath, data); err != nil {
+		s.fail(exportID, err)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.Status = StatusCompleted
+	record.FileName = fileName(snapshot, now)
+	record.Size = len(data)
+	record.CompletedAt = now.UnixMilli()
+	record.ExpiresAt = now.Add(s.ttl).UnixMilli()
+	record.filePath = filePath
+	return nil
+}
+
+// Run removes expired export files until the context is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.purge(ctx, now)
+		}
+	}
+}
+
+// purge deletes the files of expired exports and forgets exports that
+// expired more than recordRetention ago.
+func (s *Service) purge(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	expired := make([]string, 0)
+	for id, record := range s.records {
+		finished := record.ExpiresAt
+		if record.Status == StatusFailed {
+			finished = record.CompletedAt
+		}
+		if finished == 0 || now.UnixMilli() < finished {
+			continue
+		}
+		if record.filePath != "" {
+			expired = append(expired, record.filePath)
+			record.filePath = ""
+		}
+		if now.Sub(time.UnixMilli(finished)) > recordRetention {
+			delete(s.records, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, filePath := range expired {
+		_ = s.files.Delete(ctx, filePath)
+	}
+}
+
+// validate checks the request and that the user may export the class or
+// student: parents their children, teachers the classes they own.
+func (s *Service) validate(ctx context.Context, role Role, userID string, req Request) error {
+	switch req.Format {
+	case FormatCSV, FormatXLSX:
+	default:
+		return ErrExportInvalid.With("field", "format")
+	}
+	switch req.Type {
+	case TypeClassGradebook:
+		if role != RoleTeacher {
+			return ErrExportInvalid.With("field", "type")
+		}
+	case TypeStudentProgress, TypeAttemptHistory:
+		if req.StudentID == "" {
+			return ErrExportInvalid.With("field", "studentId")
+		}
+	default:
+		return ErrExportInvalid.With("field", "type")
+	}
+
+	if role == RoleParent {
+		return s.parents.CheckChild(ctx, userID, req.StudentID)
+	}
+	if req.ClassID == "" {
+		return ErrExportInvalid.With("field", "classId")
+	}
+	return s.teachers.CheckClassMember(ctx, userID, req.ClassID, req.StudentID)
+}
+
+// owned returns an export of the user. Callers must hold the lock.
+func (s *Service) owned(role Role, userID, exportID string) (*Record, error) {
+	record, ok := s.records[exportID]
+	if !ok || record.UserID != userID || record.Role != role {
+		return nil, ErrExportNotFound.With("exportId", exportID)
+	}
+	return record, nil
+}
+
+// fail marks an export as failed with the error code of err. Causes that
+// are not domain errors are recorded as internal.
+func (s *Service) fail(exportID string, err error) {
+	code := apperr.ErrInternal.Code
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		code = appErr.Code
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[exportID]; ok {
+		record.Status = StatusFailed
+		record.Error = code
+		record.CompletedAt = time.Now().UnixMilli()
+	}
+}
+
+// fileName names the download after its content and the day it was made.
+func fileName(record Record, now time.Time) string {
+	var subject string
+	switch record.Type {
+	case TypeClassGradebook:
+		subject = record.ClassID + "-gradebook"
+	case TypeStudentProgress:
+		subject = record.StudentID + "-progress"
+	default:
+		subject = record.StudentID + "-attempts"
+	}
+	return fmt.Sprintf("%s-%s.%s", subject, now.Format("20060102"), record.Format)
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// timeLayout formats timestamps in exported cells.
+const timeLayout = "2006-01-02 15:04:05"
+
+// table is the content of an export before it is encoded. Cells hold a
+// string, an int, or nil for an empty cell.
+type table struct {
+	sheet  string
+	header []string
+	rows   [][]any
+}
+
+// render collects the data of an export and encodes it in its format.
+// Access is checked again since the roster may have changed in between.
+func (s *Service) render(ctx context.Context, record Record) ([]byte, error) {
+	data, err := s.collect(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if record.Format == FormatXLSX {
+		return encodeXLSX(data)
+	}
+	return encodeCSV(data)
+}
+
+func (s *Service) collect(ctx context.Context, record Record) (table, error) {
+	switch record.Type {
+	case TypeClassGradebook:
+		book, err := s.teachers.Gradebook(ctx, record.UserID, record.ClassID)
+		if err != nil {
+			return table{}, err
+		}
+		return gradebookTable(book), nil
+	case TypeStudentProgress:
+		var rows []student.LevelProgressRow
+		var err error
+		if record.Role == RoleParent {
+			rows, err = s.parents.ProgressRows(ctx, record.UserID, record.StudentID)
+		} else {
+			rows, err = s.teachers.StudentProgressRows(ctx, record.UserID, record.ClassID, record.StudentID)
+		}
+		if err != nil {
+			return table{}, err
+		}
+		return progressTable(rows), nil
+	case TypeAttemptHistory:
+		var attempts []student.AttemptSummary
+		var err error
+		if record.Role == RoleParent {
+			attempts, err = s.parents.AttemptHistory(ctx, record.UserID, record.StudentID)
+		} else {
+			attempts, err = s.teachers.StudentAttemptHistory(ctx, record.UserID, record.ClassID, record.StudentID)
+		}
+		if err != nil {
+			return table{}, err
+		}
+		return attemptTable(attempts), nil
+	}
+	return table{}, ErrExportInvalid.With("field", "type")
+}
+
+// gradebookTable has one row per student with a column per rubric, like the
+// gradebook CSV download. Rubrics without a reviewed score are left empty.
+func gradebookTable(book student.Gradebook) table {
+	header := []string{"studentId", "name"}
+	for _, rubric := range book.Rubrics {
+		header = append(header, fmt.Sprintf("%s (%d)", rubric.Title, rubric.MaxPoints()))
+	}
+	header = append(header, "total", "maxPoints")
+
+	rows := make([][]any, 0, len(book.Rows))
+	for _, row := range book.Rows {
+		cells := []any{row.ID, row.Name}
+		for _, cell := range row.Cells {
+			if cell.Total != nil {
+				cells = append(cells, *cell.Total)
+			} else {
+				cells = append(cells, nil)
+			}
+		}
+		cells = append(cells, row.Total, row.MaxPoints)
+		rows = append(rows, cells)
+	}
+	return table{sheet: "Gradebook", header: header, rows: rows}
+}
+
+// progressTable has one row per level the student has progress on.
+func progressTable(progress []student.LevelProgressRow) table {
+	header := []string{"levelId", "level", "stars", "steps", "hints", "duration", "bestDifference", "revision", "completedAt"}
+	rows := make([][]any, 0, len(progress))
+	for _, row := range progress {
+		var best any
+		if row.BestDifference != nil {
+			best = *row.BestDifference
+		}
+		rows = append(rows, []any{
+			row.LevelID, row.LevelName, row.Stars, row.Steps, row.Hints, row.Duration, best, row.Revision, formatTime(row.CompletedAt),
+		})
+	}
+	return table{sheet: "Progress", header: header, rows: rows}
+}
+
+// attemptTable has one row per run, newest first.
+func attemptTable(attempts []student.AttemptSummary) table {
+	header := []string{"attemptId", "levelId", "success", "stars", "steps", "errorCode", "misconceptions", "revision", "duration", "createdAt"}
+	rows := make([][]any, 0, len(attempts))
+	for _, attempt := range attempts {
+		misconceptions := make([]string, 0, len(attempt.Misconceptions))
+		for _, kind := range attempt.Misconceptions {
+			misconceptions = append(misconceptions, string(kind))
+		}
+		rows = append(rows, []any{
+			attempt.ID, attempt.LevelID, fmt.Sprint(attempt.Success), attempt.Stars, attempt.Steps, attempt.ErrorCode,
+			strings.Join(misconceptions, ";"), attempt.Revision, attempt.Duration, formatTime(attempt.CreatedAt),
+		})
+	}
+	return table{sheet: "Attempts", header: header, rows: rows}
+}
+
+func formatTime(millis int64) any {
+	if millis == 0 {
+		return nil
+	}
+	return time.UnixMilli(millis).Format(timeLayout)
+}
@@ -0,0 +1,331 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+	"github.com/codeadventurers/api-go/internal/ws"
+)
+
+// Monitor defaults. A student is stuck after stuckAfter failed runs in a row
+// on a level and idle once nothing happened for idleAfter.
+const (
+	stuckAfter     = 3
+	idleAfter      = 5 * time.Minute
+	idleCheckEvery = 30 * time.Second
+	publishTimeout = 5 * time.Second
+	eventBuffer    = 256
+)
+
+// Publisher delivers payloads to the subscribers of a topic.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+}
+
+// StudentStatus summarises what a student is doing right now.
+type StudentStatus string
+
+const (
+	StatusInactive  StudentStatus = "inactive"
+	StatusWorking   StudentStatus = "working"
+	StatusStuck     StudentStatus = "stuck"
+	StatusCompleted StudentStatus = "completed"
+	StatusIdle      StudentStatus = "idle"
+)
+
+// EventType names a message sent on a class monitor topic.
+type EventType string
+
+const (
+	EventSnapshot EventType = "snapshot"
+	EventRun      EventType = "run"
+	EventHint     EventType = "hint"
+	EventComplete EventType = "complete"
+	EventIdle     EventType = "idle"
+	EventControls EventType = "controls"
+)
+
+// StudentState is the live state of a student on the level they are working
+// on. Attempts is the number of runs on the level, FailedRuns the number of
+// failed runs since the last successful one.
+type StudentState struct {
+	StudentID   string        `json:"studentId"`
+	Name        string        `json:"name"`
+	LevelID     string        `json:"levelId,omitempty"`
+	Attempts    int           `json:"attempts"`
+	FailedRuns  int           `json:"failedRuns"`
+	Hints       int           `json:"hints"`
+	LastError   string        `json:"lastError,omitempty"`
+	Stars       int           `json:"stars"`
+	Status      StudentStatus `json:"status"`
+	LastEventAt int64         `json:"lastEventAt,omitempty"`
+}
+
+// Event is a message sent to the teacher dashboard of a class. Snapshots
+// carry the whole class in Students, controls events the new classroom
+// controls and all other events the changed student. Controls events are
+// also sent to the students of the class.
+type Event struct {
+	Type     EventType              `json:"type"`
+	ClassID  string                 `json:"classId"`
+	At       int64                  `json:"at"`
+	Student  *StudentState          `json:"student,omitempty"`
+	Students []StudentState         `json:"students,omitempty"`
+	Controls *student.ClassControls `json:"controls,omitempty"`
+}
+
+// Member is a student on a class roster.
+type Member struct {
+	ID   string
+	Name string
+}
+
+// Service keeps the live state of the students of every class from the
+// activity events of the student service and publishes changes to the
+// monitor topic of the class. Pending controls are kept per class, so a
+// burst of changes is published as the latest controls of each class.
+type Service struct {
+	mu            sync.Mutex
+	classes       map[string]map[string]*StudentState
+	studentOf     map[string]string
+	events        chan student.ActivityEvent
+	controls      map[string]student.ClassControls
+	controlsReady chan struct{}
+	dropped       atomic.Int64
+	publisher     Publisher
+	log           *zap.Logger
+}
+
+// New creates a monitor publishing through publisher. Run must be started for
+// events to be processed.
+func New(publisher Publisher, log *zap.Logger) *Service {
+	return &Service{
+		classes:       make(map[string]map[string]*StudentState),
+		studentOf:     make(map[string]string),
+		events:        make(chan student.ActivityEvent, eventBuffer),
+		controls:      make(map[string]student.ClassControls),
+		controlsReady: make(chan struct{}, 1),
+		publisher:     publisher,
+		log:           log,
+	}
+}
+
+// Observe queues a student activity event. It never blocks: while the queue
+// is full the event is dropped, logged and counted in Dropped. The next event
+// of the student carries the current attempt and hint counts again.
+func (s *Service) Observe(event student.ActivityEvent) {
+	if event.ClassID == "" {
+		return
+	}
+	select {
+	case s.events <- event:
+	default:
+		dropped := s.dropped.Add(1)
+		s.log.Warn("monitor queue full, activity event dropped",
+			zap.String("class_id", event.ClassID),
+			zap.String("student_id", event.StudentID),
+			zap.String("type", string(event.Type)),
+			zap.Int64("dropped", dropped))
+	}
+}
+
+// Dropped returns the number of activity events dropped because the queue
+// was full.
+func (s *Service) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// ObserveControls records a change to the controls of a class. It never
+// blocks and never loses a change: controls not yet published are replaced
+// by the newer ones.
+func (s *Service) ObserveControls(classID string, controls student.ClassControls) {
+	s.mu.Lock()
+	s.controls[classID] = controls
+	s.mu.Unlock()
+
+	select {
+	case s.controlsReady <- struct{}{}:
+	default:
+	}
+}
+
+// ControlsEvent is the message announcing the controls of a class.
+func ControlsEvent(classID string, controls student.ClassControls) Event {
+	return Event{Type: EventControls, ClassID: classID, At: controls.UpdatedAt, Controls: &controls}
+}
+
+// Run processes queued events and marks idle students until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(idleCheckEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case activity := <-s.events:
+			s.publish(ctx, s.apply(activity))
+		case <-s.controlsReady:
+			s.publish(ctx, s.takeControls()...)
+		case now := <-ticker.C:
+			s.publish(ctx, s.markIdle(now)...)
+		}
+	}
+}
+
+// Snapshot returns the live state of the students of a class in roster
+// order. Students without activity since the server started are inactive.
+func (s *Service) Snapshot(ctx context.Context, classID string, roster []Member) Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	students := make([]StudentState, 0, len(roster))
+	for _, member := range roster {
+		state := s.state(classID, member.ID)
+		state.Name = member.Name
+		students = append(students, *state)
+	}
+	return Event{Type: EventSnapshot, ClassID: classID, At: time.Now().UnixMilli(), Students: students}
+}
+
+// apply updates the state of a student from an activity event.
+func (s *Service) apply(activity student.ActivityEvent) Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state(activity.ClassID, activity.StudentID)
+	if state.LevelID != activity.LevelID {
+		state.LevelID = activity.LevelID
+		state.FailedRuns = 0
+		state.LastError = ""
+		state.Stars = 0
+	}
+	state.Attempts = activity.Attempts
+	state.Hints = activity.Hints
+	state.LastEventAt = activity.At
+
+	var eventType EventType
+	switch activity.Type {
+	case student.ActivityRun:
+		eventType = EventRun
+		if activity.Success {
+			state.FailedRuns = 0
+			state.Stars = activity.Stars
+		} else {
+			state.FailedRuns++
+			state.LastError = activity.ErrorCode
+		}
+		state.Status = runStatus(state)
+	case student.ActivityHint:
+		eventType = EventHint
+		state.Status = runStatus(state)
+	case student.ActivityComplete:
+		eventType = EventComplete
+		state.FailedRuns = 0
+		state.Stars = activity.Stars
+		state.Status = StatusCompleted
+	}
+
+	snapshot := *state
+	return Event{Type: eventType, ClassID: activity.ClassID, At: activity.At, Student: &snapshot}
+}
+
+// takeControls returns a controls event per class with pending controls and
+// clears them.
+func (s *Service) takeControls() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]Event, 0, len(s.controls))
+	for classID, controls := range s.controls {
+		events = append(events, ControlsEvent(classID, controls))
+	}
+	clear(s.controls)
+	return events
+}
+
+// markIdle marks students without activity for idleAfter as idle and returns
+// an event per newly idle student.
+func (s *Service) markIdle(now time.Time) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := now.Add(-idleAfter).UnixMilli()
+	events := make([]Event, 0)
+	for classID, students := range s.classes {
+		for _, state := range students {
+			if state.Status != StatusWorking && state.Status != StatusStuck {
+				continue
+			}
+			if state.LastEventAt > cutoff {
+				continue
+			}
+			state.Status = StatusIdle
+			snapshot := *state
+			events = append(events, Event{Type: EventIdle, ClassID: classID, At: now.UnixMilli(), Student: &snapshot})
+		}
+	}
+	return events
+}
+
+// state returns the state of a student in a class, moving it over when the
+// student changed class. Callers must hold the lock.
+func (s *Service) state(classID, studentID string) *StudentState {
+	if previous, ok := s.studentOf[studentID]; ok && previous != classID {
+		if state, ok := s.classes[previous][studentID]; ok {
+			delete(s.classes[previous], studentID)
+			s.classes[classID] = ensureClass(s.classes[classID])
+			s.classes[classID][studentID] = state
+		}
+	}
+	s.studentOf[studentID] = classID
+
+	students := ensureClass(s.classes[classID])
+	s.classes[classID] = students
+	state, ok := students[studentID]
+	if !ok {
+		state = &StudentState{StudentID: studentID, Status: StatusInactive}
+		students[studentID] = state
+	}
+	return state
+}
+
+// publish sends events to the monitor topics of their classes, and controls
+// events to the students as well. Failed writes are left to the read loops of
+// the connections, which drop closed ones.
+func (s *Service) publish(ctx context.Context, events ...Event) {
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		topics := []string{ws.ClassMonitorTopic(event.ClassID)}
+		if event.Type == EventControls {
+			topics = append(topics, ws.ClassTopic(event.ClassID))
+		}
+		for _, topic := range topics {
+			publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+			_ = s.publisher.Publish(publishCtx, topic, payload)
+			cancel()
+		}
+	}
+}
+
+func runStatus(state *StudentState) StudentStatus {
+	if state.FailedRuns >= stuckAfter {
+		return StatusStuck
+	}
+	return StatusWorking
+}
+
+func ensureClass(students map[string]*StudentState) map[string]*StudentState {
+	if students == nil {
+		return make(map[string]*StudentState)
+	}
+	return students
+}
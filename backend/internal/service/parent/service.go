@@ -2,16 +2,19 @@ package parent
 
 import (
 	"context"
-	"errors"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+	"github.com/codeadventurers/api-go/internal/service/student"
 )
 
 // Service exposes parent dashboard capabilities backed by in-memory demo data.
 type Service struct {
 	mu              sync.RWMutex
+	students        *student.Service
 	states          map[string]*parentState
 	defaultParentID string
 }
@@ -56,6 +59,10 @@ type ChildOverview struct {
 	TotalDuration   int           `json:"totalDuration"`
 	LastActiveAt    int64         `json:"lastActiveAt"`
 	WeeklyReport    *WeeklyReport `json:"weeklyReport,omitempty"`
+	// Activity is the child's streak and practice time; ReminderDue is set
+	// once the reminder time has passed on a day without practice.
+	Activity    *student.ActivitySummary `json:"activity,omitempty"`
+	ReminderDue bool                     `json:"reminderDue"`
 }
 
 // ChildSummary is a lightweight representation used in pickers.
@@ -64,7 +71,11 @@ type ChildSummary struct {
 	Name string `json:"name"`
 }
 
-// WeeklyReport summarises learning progress for a child.
+// reportWindow is the period the common mistakes of a weekly report cover.
+const reportWindow = 7 * 24 * time.Hour
+
+// WeeklyReport summarises learning progress for a child. CommonMistakes are
+// the misconceptions found in the child's failed runs of the last week.
 type WeeklyReport struct {
 	ChildID         string   `json:"childId"`
 	GeneratedAt     int64    `json:"generatedAt"`
@@ -88,20 +99,19 @@ type ProgressRecord struct {
 
 var (
 	// ErrParentNotFound indicates the requested parent data does not exist.
-	ErrParentNotFound = errors.New("parent profile not found")
+	ErrParentNotFound = apperr.New(apperr.KindNotFound, "parent.not_found")
 	// ErrChildNotFound indicates the requested child data cannot be located.
-	ErrChildNotFound = errors.New("child profile not found")
+	ErrChildNotFound = apperr.New(apperr.KindNotFound, "child.not_found")
 )
 
 // New constructs the parent service with demo data aligned to the frontend expectations.
-func New() *Service {
+func New(students *student.Service) *Service {
 	now := time.Now()
 	firstWeekReport := WeeklyReport{
 		ChildID:         "student-1",
 		GeneratedAt:     now.Add(-24 * time.Hour).UnixMilli(),
 		Summary:         "小明本周保持了稳步的练习节奏，开始接触循环与条件判断。",
 		ConceptsLearned: []string{"顺序执行", "基础循环"},
-		CommonMistakes:  []string{"忘记在终点前转向", "循环次数设置过多"},
 		Recommendations: []string{"多尝试使用条件积木优化路径", "挑战循环岛屿章节的第二关"},
 	}
 
@@ -141,7 +151,6 @@ func New() *Service {
 					GeneratedAt:     now.Add(-3 * 24 * time.Hour).UnixMilli(),
 					Summary:         "小红完成了首章所有课程，需要更多练习提高星级。",
 					ConceptsLearned: []string{"转向判断"},
-					CommonMistakes:  []string{"部分关卡未使用提示导致重复错误"},
 					Recommendations: []string{"使用提示功能了解最佳路径", "尝试重温直角挑战关卡"},
 				},
 				ProgressItems: []ProgressRecord{
@@ -158,6 +167,7 @@ func New() *Service {
 	}
 
 	return &Service{
+		students: students,
 		states: map[string]*parentState{
 			"parent-1":    state,
 			"parent-demo": state,
@@ -176,13 +186,23 @@ func (s *Service) Overview(ctx context.Context, parentID string) (Overview, erro
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	now := time.Now()
 	children := make([]ChildOverview, 0, len(state.Children))
 	for _, child := range state.Children {
 		clone := child.Overview
 		if child.WeeklyReport.ChildID != "" {
-			report := child.WeeklyReport
+			report := s.weeklyReport(ctx, child, now)
 			clone.WeeklyReport = &report
 		}
+		activeToday := false
+		if activity, ok := s.students.ActivitySummary(ctx, clone.ID); ok {
+			clone.Activity = &activity
+			if activity.LastActiveAt > clone.LastActiveAt {
+				clone.LastActiveAt = activity.LastActiveAt
+			}
+			activeToday = activity.ActiveToday
+		}
+		clone.ReminderDue = !activeToday && reminderPassed(state.Settings.ReminderTime, now)
 		children = append(children, clone)
 	}
 
@@ -216,7 +236,15 @@ func (s *Service) WeeklyReport(ctx context.Context, parentID, childID string) (W
 	}
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return child.WeeklyReport, nil
+	return s.weeklyReport(ctx, child, time.Now()), nil
+}
+
+// weeklyReport returns the report of a child with its common mistakes taken
+// from the misconception analyzer. Callers must hold the lock.
+func (s *Service) weeklyReport(ctx context.Context, child *childState, now time.Time) WeeklyReport {
+	report := child.WeeklyReport
+	report.CommonMistakes = s.students.StudentMisconceptionTexts(ctx, child.Overview.ID, student.AnalyticsRange{From: now.Add(-reportWindow), To: now.Add(time.Millisecond)})
+	return report
 }
 
 // Progress returns the historical progress for a child.
@@ -234,6 +262,60 @@ func (s *Service) Progress(ctx context.Context, parentID, childID string) ([]Pro
 	return progress, nil
 }
 
+// Attempts lists a child's attempts on a level, newest first.
+func (s *Service) Attempts(ctx context.Context, parentID, childID, levelID string) ([]student.AttemptSummary, error) {
+	if _, err := s.childState(parentID, childID); err != nil {
+		return nil, err
+	}
+	return s.students.Attempts(ctx, childID, levelID)
+}
+
+// AttemptHistory lists a child's attempts on all levels, newest first.
+func (s *Service) AttemptHistory(ctx context.Context, parentID, childID string) ([]student.AttemptSummary, error) {
+	if _, err := s.childState(parentID, childID); err != nil {
+		return nil, err
+	}
+	return s.students.AttemptHistory(ctx, childID), nil
+}
+
+// ProgressRows lists the recorded level progress of a child.
+func (s *Service) ProgressRows(ctx context.Context, parentID, childID string) ([]student.LevelProgressRow, error) {
+	if _, err := s.childState(parentID, childID); err != nil {
+		return nil, err
+	}
+	return s.students.ProgressRows(ctx, childID), nil
+}
+
+// CheckChild reports whether the child belongs to the parent.
+func (s *Service) CheckChild(ctx context.Context, parentID, childID string) error {
+	_, err := s.childState(parentID, childID)
+	return err
+}
+
+// Attempt returns a single attempt of a child including its program.
+func (s *Service) Attempt(ctx context.Context, parentID, childID, attemptID string) (student.Attempt, error) {
+	if _, err := s.childState(parentID, childID); err != nil {
+		return student.Attempt{}, err
+	}
+	return s.students.Attempt(ctx, childID, attemptID)
+}
+
+// Replay re-simulates an attempt of a child and returns a page of frames.
+func (s *Service) Replay(ctx context.Context, parentID, childID, attemptID string, offset, limit int) (student.Replay, error) {
+	if _, err := s.childState(parentID, childID); err != nil {
+		return student.Replay{}, err
+	}
+	return s.students.Replay(ctx, childID, attemptID, offset, limit)
+}
+
+// Activity returns the streak and activity calendar of a child.
+func (s *Service) Activity(ctx context.Context, parentID, childID string, days int) (student.ActivityCalendar, error) {
+	if _, err := s.childState(parentID, childID); err != nil {
+		return student.ActivityCalendar{}, err
+	}
+	return s.students.Activity(ctx, childID, days), nil
+}
+
 // Settings returns the notification preferences for the parent.
 func (s *Service) Settings(ctx context.Context, parentID string) (Settings, error) {
 	state, err := s.parentState(parentID)
@@ -277,6 +359,17 @@ func (s *Service) UpdateSettings(ctx context.Context, parentID string, update Se
 	return state.Settings, nil
 }
 
+// reminderPassed reports whether the "HH:MM" reminder time has passed today.
+// Malformed times never pass.
+func reminderPassed(reminderTime string, now time.Time) bool {
+	at, err := time.ParseInLocation("15:04", reminderTime, now.Location())
+	if err != nil {
+		return false
+	}
+	year, month, day := now.Date()
+	return !now.Before(time.Date(year, month, day, at.Hour(), at.Minute(), 0, 0, now.Location()))
+}
+
 func (s *Service) parentState(parentID string) (*parentState, error) {
 	id := parentID
 	if strings.TrimSpace(id) == "" {
@@ -0,0 +1,247 @@
+package student
+
+import (
+	"context"
+	"time"
+)
+
+// Activity is tracked per calendar day. Every run, hint, completion and
+// heartbeat is an activity event; the time between two events at most
+// activityIdleGap apart counts as active time, and an event after a longer
+// pause starts a new session credited with activitySessionCredit.
+const (
+	activityIdleGap       = 5 * time.Minute
+	activitySessionCredit = time.Minute
+)
+
+// A day with any activity extends the streak. Reaching every
+// streakFreezeEvery-th day of a streak earns a streak freeze, up to
+// maxStreakFreezes. Freezes are spent automatically to cover missed days when
+// the student comes back.
+const (
+	streakFreezeEvery = 7
+	maxStreakFreezes  = 2
+)
+
+// Calendar lengths accepted by Activity.
+const (
+	DefaultActivityDays = 28
+	MaxActivityDays     = 366
+)
+
+const activityDateLayout = "2006-01-02"
+
+// ActivityDay is one day of a student's activity calendar. Frozen days were
+// missed but covered by a streak freeze.
+type ActivityDay struct {
+	Date          string `json:"date"`
+	ActiveMinutes int    `json:"activeMinutes"`
+	Frozen        bool   `json:"frozen,omitempty"`
+}
+
+// ActivitySummary is a student's streak and practice time as of now.
+// CurrentStreak is kept while the missed days since the last active day can
+// still be covered by freezes.
+type ActivitySummary struct {
+	CurrentStreak    int   `json:"currentStreak"`
+	LongestStreak    int   `json:"longestStreak"`
+	FreezesAvailable int   `json:"freezesAvailable"`
+	ActiveToday      bool  `json:"activeToday"`
+	TodayMinutes     int   `json:"todayMinutes"`
+	WeekMinutes      int   `json:"weekMinutes"`
+	LastActiveAt     int64 `json:"lastActiveAt"`
+}
+
+// ActivityCalendar is the summary with the most recent days, oldest first.
+type ActivityCalendar struct {
+	ActivitySummary
+	Days []ActivityDay `json:"days"`
+}
+
+// HeartbeatResult reports the activity after a heartbeat and the streak
+// badges it earned.
+type HeartbeatResult struct {
+	ActivitySummary
+	NewBadges []EarnedBadge `json:"newBadges"`
+}
+
+type activityDay struct {
+	active time.Duration
+	frozen bool
+}
+
+type activityState struct {
+	days        map[string]*activityDay
+	lastEventAt time.Time
+	lastDay     string
+	streak      int
+	longest     int
+	freezes     int
+}
+
+// Heartbeat records that the student is active, e.g. while reading a level
+// or building a program, and evaluates the badge rules.
+func (s *Service) Heartbeat(ctx context.Context, userID string) HeartbeatResult {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.recordActivity(profile.ID, now)
+	return HeartbeatResult{
+		ActivitySummary: s.activitySummary(profile.ID, now),
+		NewBadges:       s.awardAchievements(profile, now).NewBadges,
+	}
+}
+
+// Activity returns the activity calendar of a student covering the given
+// number of days up to today. days is clamped to [1, MaxActivityDays] and
+// defaults to DefaultActivityDays when zero.
+func (s *Service) Activity(ctx context.Context, studentID string, days int) ActivityCalendar {
+	switch {
+	case days == 0:
+		days = DefaultActivityDays
+	case days < 1:
+		days = 1
+	case days > MaxActivityDays:
+		days = MaxActivityDays
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	calendar := ActivityCalendar{
+		ActivitySummary: s.activitySummary(studentID, now),
+		Days:            make([]ActivityDay, 0, days),
+	}
+	state := s.activity[studentID]
+	today := activityDate(now)
+	for i := days - 1; i >= 0; i-- {
+		date := today.AddDate(0, 0, -i).Format(activityDateLayout)
+		entry := ActivityDay{Date: date}
+		if state != nil {
+			if day, ok := state.days[date]; ok {
+				entry.ActiveMinutes = int(day.active / time.Minute)
+				entry.Frozen = day.frozen
+			}
+		}
+		calendar.Days = append(calendar.Days, entry)
+	}
+	return calendar
+}
+
+// ActivitySummary returns the streak and practice time of a student. It
+// reports false when the student has no recorded activity.
+func (s *Service) ActivitySummary(ctx context.Context, studentID string) (ActivitySummary, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.activity[studentID] == nil {
+		return ActivitySummary{}, false
+	}
+	return s.activitySummary(studentID, time.Now()), true
+}
+
+// recordActivity adds an activity event. Callers must hold the write lock.
+func (s *Service) recordActivity(studentID string, at time.Time) {
+	state := s.activity[studentID]
+	if state == nil {
+		state = &activityState{days: make(map[string]*activityDay)}
+		s.activity[studentID] = state
+	}
+
+	date := activityDate(at).Format(activityDateLayout)
+	day := state.days[date]
+	if day == nil {
+		day = &activityDay{}
+		state.days[date] = day
+	}
+	day.frozen = false
+	if gap := at.Sub(state.lastEventAt); !state.lastEventAt.IsZero() && gap >= 0 && gap <= activityIdleGap {
+		day.active += gap
+	} else {
+		day.active += activitySessionCredit
+	}
+	if at.After(state.lastEventAt) {
+		state.lastEventAt = at
+	}
+
+	if date == state.lastDay {
+		return
+	}
+	switch missed := daysBetween(state.lastDay, date) - 1; {
+	case state.lastDay == "" || missed > state.freezes:
+		state.streak = 1
+	case missed < 0:
+		// An event for an earlier day, e.g. from a clock change; the streak
+		// was counted already.
+		return
+	default:
+		last, _ := time.Parse(activityDateLayout, state.lastDay)
+		for i := 1; i <= missed; i++ {
+			state.days[last.AddDate(0, 0, i).Format(activityDateLayout)] = &activityDay{frozen: true}
+		}
+		state.freezes -= missed
+		state.streak++
+	}
+	state.lastDay = date
+	if state.streak > state.longest {
+		state.longest = state.streak
+	}
+	if state.streak%streakFreezeEvery == 0 && state.freezes < maxStreakFreezes {
+		state.freezes++
+	}
+}
+
+// activitySummary computes the summary as of now. Callers must hold the lock.
+func (s *Service) activitySummary(studentID string, now time.Time) ActivitySummary {
+	state := s.activity[studentID]
+	if state == nil {
+		return ActivitySummary{}
+	}
+
+	today := activityDate(now)
+	summary := ActivitySummary{
+		LongestStreak:    state.longest,
+		FreezesAvailable: state.freezes,
+		LastActiveAt:     state.lastEventAt.UnixMilli(),
+	}
+	for i := 0; i < 7; i++ {
+		day, ok := state.days[today.AddDate(0, 0, -i).Format(activityDateLayout)]
+		if !ok {
+			continue
+		}
+		minutes := int(day.active / time.Minute)
+		summary.WeekMinutes += minutes
+		if i == 0 {
+			summary.TodayMinutes = minutes
+			summary.ActiveToday = day.active > 0
+		}
+	}
+	if missed := daysBetween(state.lastDay, today.Format(activityDateLayout)) - 1; missed <= state.freezes {
+		summary.CurrentStreak = state.streak
+	}
+	return summary
+}
+
+// activityDate returns midnight of the local day t falls on.
+func activityDate(t time.Time) time.Time {
+	year, month, day := t.In(time.Local).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// daysBetween counts the calendar days from one date to another. An empty
+// from date counts as infinitely long ago.
+func daysBetween(from, to string) int {
+	start, err := time.Parse(activityDateLayout, from)
+	if err != nil {
+		return 1 << 30
+	}
+	end, err := time.Parse(activityDateLayout, to)
+	if err != nil {
+		return 1 << 30
+	}
+	return int(end.Sub(start).Hours() / 24)
+}
@@ -0,0 +1,324 @@
+package student
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// maxErrorCodesPerLevel bounds the error codes reported for one level.
+const maxErrorCodesPerLevel = 5
+
+// AnalyticsRange limits analytics to events from From up to but excluding To.
+type AnalyticsRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func (r AnalyticsRange) contains(at int64) bool {
+	return at >= r.From.UnixMilli() && at < r.To.UnixMilli()
+}
+
+// ErrorCount is how often runs of a level failed with an error code.
+type ErrorCount struct {
+	Code  string `json:"code"`
+	Count int    `json:"count"`
+}
+
+// LevelAnalytics aggregates what a group of students did on one level in a
+// date range. Attempted counts the students who ran the level or completed
+// it; the averages are per attempting student. Stars is indexed by the best
+// result of each attempting student, 0 meaning not completed.
+type LevelAnalytics struct {
+	LevelID      string       `json:"levelId"`
+	Name         string       `json:"name"`
+	ChapterID    string       `json:"chapterId"`
+	Students     int          `json:"students"`
+	Attempted    int          `json:"attempted"`
+	Completed    int          `json:"completed"`
+	Perfect      int          `json:"perfect"`
+	Attempts     int          `json:"attempts"`
+	AvgAttempts  float64      `json:"avgAttempts"`
+	Hints        int          `json:"hints"`
+	AvgHints     float64      `json:"avgHints"`
+	Stars        [4]int       `json:"stars"`
+	TotalSeconds int          `json:"totalSeconds"`
+	AvgSeconds   float64      `json:"avgSeconds"`
+	Errors       []ErrorCount `json:"errors"`
+}
+
+// StudentLevelAnalytics is one student's activity on a level in a date
+// range. Duration is the run time reported with the runs, in seconds.
+type StudentLevelAnalytics struct {
+	LevelID  string `json:"levelId"`
+	Stars    int    `json:"stars"`
+	Attempts int    `json:"attempts"`
+	Hints    int    `json:"hints"`
+	Duration int    `json:"duration"`
+}
+
+// StudentAnalytics is the activity of one student in a date range, for the
+// levels they ran or completed.
+type StudentAnalytics struct {
+	StudentID string                  `json:"studentId"`
+	Completed int                     `json:"completed"`
+	Stars     int                     `json:"stars"`
+	Entries   []StudentLevelAnalytics `json:"entries"`
+}
+
+// AnalyticsDay is the activity of a group of students on one day.
+// CompletedLevels is the running total of levels the group has completed by
+// the end of the day.
+type AnalyticsDay struct {
+	Date            string `json:"date"`
+	ActiveStudents  int    `json:"activeStudents"`
+	ActiveMinutes   int    `json:"activeMinutes"`
+	Attempts        int    `json:"attempts"`
+	Completions     int    `json:"completions"`
+	CompletedLevels int    `json:"completedLevels"`
+}
+
+// LevelAnalytics aggregates the students' activity on every level of the
+// chapters visible to the given classes, in course order.
+func (s *Service) LevelAnalytics(ctx context.Context, classIDs, studentIDs []string, rng AnalyticsRange) []LevelAnalytics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]LevelAnalytics, 0)
+	for _, chapter := range s.chapters {
+		visible := false
+		for _, classID := range classIDs {
+			visible = visible || chapterVisible(chapter, classID)
+		}
+		if !visible {
+			continue
+		}
+		for _, level := range chapter.Levels {
+			result = append(result, s.levelAnalytics(level, studentIDs, rng))
+		}
+	}
+	return result
+}
+
+// StudentAnalytics returns each student's activity per level, in the order
+// of studentIDs.
+func (s *Service) StudentAnalytics(ctx context.Context, studentIDs []string, rng AnalyticsRange) []StudentAnalytics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]StudentAnalytics, 0, len(studentIDs))
+	for _, studentID := range studentIDs {
+		entry := StudentAnalytics{StudentID: studentID, Entries: make([]StudentLevelAnalytics, 0)}
+		levelIDs := make(map[string]bool)
+		for levelID := range s.attempts[studentID] {
+			levelIDs[levelID] = true
+		}
+		if profile, ok := s.profiles[studentID]; ok {
+			for levelID := range profile.Progress {
+				levelIDs[levelID] = true
+			}
+		}
+		for levelID := range levelIDs {
+			stats, active := s.studentLevelAnalytics(studentID, levelID, rng)
+			if !active {
+				continue
+			}
+			if stats.Stars > 0 {
+				entry.Completed++
+				entry.Stars += stats.Stars
+			}
+			entry.Entries = append(entry.Entries, stats)
+		}
+		sort.Slice(entry.Entries, func(i, j int) bool { return entry.Entries[i].LevelID < entry.Entries[j].LevelID })
+		result = append(result, entry)
+	}
+	return result
+}
+
+// DailyAnalytics returns the students' activity per day of the range.
+// Completions counts the levels completed for the first time on the day.
+func (s *Service) DailyAnalytics(ctx context.Context, studentIDs []string, rng AnalyticsRange) []AnalyticsDay {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	days := make([]AnalyticsDay, 0)
+	index := make(map[string]int)
+	for day := rng.From; day.Before(rng.To); day = day.AddDate(0, 0, 1) {
+		date := day.Format(activityDateLayout)
+		index[date] = len(days)
+		days = append(days, AnalyticsDay{Date: date})
+	}
+	dayOf := func(at int64) *AnalyticsDay {
+		date := time.UnixMilli(at).In(rng.From.Location()).Format(activityDateLayout)
+		if i, ok := index[date]; ok {
+			return &days[i]
+		}
+		return nil
+	}
+
+	completedBefore := 0
+	for _, studentID := range studentIDs {
+		active := make(map[string]bool)
+		for _, attempts := range s.attempts[studentID] {
+			for _, attempt := range attempts {
+				if !rng.contains(attempt.CreatedAt) {
+					continue
+				}
+				if day := dayOf(attempt.CreatedAt); day != nil {
+					day.Attempts++
+					active[day.Date] = true
+				}
+			}
+		}
+		for _, completedAt := range s.completionTimes(studentID) {
+			switch {
+			case completedAt < rng.From.UnixMilli():
+				completedBefore++
+			case rng.contains(completedAt):
+				if day := dayOf(completedAt); day != nil {
+					day.Completions++
+					active[day.Date] = true
+				}
+			}
+		}
+		if state, ok := s.activity[studentID]; ok {
+			for date, day := range state.days {
+				if i, ok := index[date]; ok && day.active > 0 {
+					days[i].ActiveMinutes += int(day.active / time.Minute)
+					active[date] = true
+				}
+			}
+		}
+		for date := range active {
+			days[index[date]].ActiveStudents++
+		}
+	}
+
+	total := completedBefore
+	for i := range days {
+		total += days[i].Completions
+		days[i].CompletedLevels = total
+	}
+	return days
+}
+
+// completionTimes returns when the student first completed each level: the
+// first successful run, or the recorded completion when it is earlier or the
+// level was completed without a run. Callers must hold the lock.
+func (s *Service) completionTimes(studentID string) map[string]int64 {
+	times := make(map[string]int64)
+	for levelID, attempts := range s.attempts[studentID] {
+		for _, attempt := range attempts {
+			if attempt.Success {
+				times[levelID] = attempt.CreatedAt
+				break
+			}
+		}
+	}
+	if profile, ok := s.profiles[studentID]; ok {
+		for levelID, progress := range profile.Progress {
+			if progress.Stars == 0 || progress.CompletedAt == 0 {
+				continue
+			}
+			if at, ok := times[levelID]; !ok || progress.CompletedAt < at {
+				times[levelID] = progress.CompletedAt
+			}
+		}
+	}
+	return times
+}
+
+// levelAnalytics aggregates the students' activity on one level. Callers
+// must hold the lock.
+func (s *Service) levelAnalytics(level LevelDefinition, studentIDs []string, rng AnalyticsRange) LevelAnalytics {
+	stats := LevelAnalytics{LevelID: level.ID, Name: level.Name, ChapterID: level.ChapterID, Students: len(studentIDs), Errors: make([]ErrorCount, 0)}
+	errors := make(map[string]int)
+	for _, studentID := range studentIDs {
+		entry, active := s.studentLevelAnalytics(studentID, level.ID, rng)
+		if !active {
+			continue
+		}
+		stats.Attempted++
+		stats.Attempts += entry.Attempts
+		stats.Hints += entry.Hints
+		stats.TotalSeconds += entry.Duration
+		stats.Stars[entry.Stars]++
+		if entry.Stars > 0 {
+			stats.Completed++
+		}
+		if entry.Stars == 3 {
+			stats.Perfect++
+		}
+		for _, attempt := range s.attempts[studentID][level.ID] {
+			if rng.contains(attempt.CreatedAt) && !attempt.Success && attempt.ErrorCode != "" {
+				errors[attempt.ErrorCode]++
+			}
+		}
+	}
+	if stats.Attempted > 0 {
+		stats.AvgAttempts = roundTenth(float64(stats.Attempts) / float64(stats.Attempted))
+		stats.AvgHints = roundTenth(float64(stats.Hints) / float64(stats.Attempted))
+		stats.AvgSeconds = roundTenth(float64(stats.TotalSeconds) / float64(stats.Attempted))
+	}
+	for code, count := range errors {
+		stats.Errors = append(stats.Errors, ErrorCount{Code: code, Count: count})
+	}
+	sort.Slice(stats.Errors, func(i, j int) bool {
+		if stats.Errors[i].Count != stats.Errors[j].Count {
+			return stats.Errors[i].Count > stats.Errors[j].Count
+		}
+		return stats.Errors[i].Code < stats.Errors[j].Code
+	})
+	if len(stats.Errors) > maxErrorCodesPerLevel {
+		stats.Errors = stats.Errors[:maxErrorCodesPerLevel]
+	}
+	return stats
+}
+
+// studentLevelAnalytics sums a student's runs and hints on a level in the
+// range. Stars is the best result of those runs, or of the recorded progress
+// when the level was completed in the range some other way, such as an
+// approved submission. It reports false when the student did nothing on the
+// level in the range. Callers must hold the lock.
+func (s *Service) studentLevelAnalytics(studentID, levelID string, rng AnalyticsRange) (StudentLevelAnalytics, bool) {
+	stats := StudentLevelAnalytics{LevelID: levelID}
+	active := false
+	for _, attempt := range s.attempts[studentID][levelID] {
+		if !rng.contains(attempt.CreatedAt) {
+			continue
+		}
+		active = true
+		stats.Attempts++
+		stats.Duration += attempt.Duration
+		if attempt.Success && attempt.Stars > stats.Stars {
+			stats.Stars = attempt.Stars
+		}
+	}
+	if state, ok := s.hints[studentID][levelID]; ok {
+		for _, at := range state.history {
+			if rng.contains(at.UnixMilli()) {
+				stats.Hints++
+				active = true
+			}
+		}
+	}
+	if profile, ok := s.profiles[studentID]; ok {
+		if progress := profile.Progress[levelID]; progress.Stars > 0 && rng.contains(progress.CompletedAt) {
+			active = true
+			if progress.Stars > stats.Stars {
+				stats.Stars = progress.Stars
+			}
+			if stats.Duration == 0 {
+				stats.Duration = progress.Duration
+			}
+		}
+	}
+	if stats.Stars > 3 {
+		stats.Stars = 3
+	}
+	return stats, active
+}
+
+func roundTenth(value float64) float64 {
+	return float64(int(value*10+0.5)) / 10
+}
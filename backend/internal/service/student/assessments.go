@@ -0,0 +1,525 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Limits of the assessment settings.
+const (
+	maxAssessmentMinutes = 24 * 60
+	maxAssessmentRuns    = 100
+)
+
+// Errors returned by assessments.
+var (
+	ErrAssessmentNotFound      = apperr.New(apperr.KindNotFound, "assessment.not_found")
+	ErrAssessmentNotStarted    = apperr.New(apperr.KindConflict, "assessment.not_started")
+	ErrAssessmentEnded         = apperr.New(apperr.KindConflict, "assessment.ended")
+	ErrAssessmentRunLimit      = apperr.New(apperr.KindConflict, "assessment.run_limit")
+	ErrAssessmentInProgress    = apperr.New(apperr.KindConflict, "assessment.in_progress")
+	ErrAssessmentHintsDisabled = apperr.New(apperr.KindForbidden, "assessment.hints_disabled")
+	ErrAssessmentNotEnded      = apperr.New(apperr.KindConflict, "assessment.not_ended")
+	ErrAssessmentReleased      = apperr.New(apperr.KindConflict, "assessment.released")
+)
+
+// Assessment turns an assignment into a quiz on its levels. Each student
+// starts it once between StartAt and DueAt and then has TimeLimitMinutes, at
+// most until DueAt, and MaxRuns runs per level; zero means no limit. While the
+// assignment is open its levels can only be played through the assessment:
+// hints, the sandbox and completions are off and runs report no result. The
+// program a student submits last for each level is scored by the simulator,
+// and the scores stay hidden until the teacher releases them.
+type Assessment struct {
+	TimeLimitMinutes int   `json:"timeLimitMinutes"`
+	MaxRuns          int   `json:"maxRuns"`
+	ReleasedAt       int64 `json:"releasedAt,omitempty"`
+}
+
+// AssessmentStatus is where a student is with an assessment.
+type AssessmentStatus string
+
+const (
+	AssessmentNotStarted AssessmentStatus = "not_started"
+	AssessmentInProgress AssessmentStatus = "in_progress"
+	AssessmentEnded      AssessmentStatus = "ended"
+)
+
+// AssessmentSubmission is the last program a student submitted for a level of
+// an assessment, with the simulator's verdict on it.
+type AssessmentSubmission struct {
+	LevelID     string        `json:"levelId"`
+	Program     []Instruction `json:"program"`
+	Result      WorkResult    `json:"result"`
+	SubmittedAt int64         `json:"submittedAt"`
+}
+
+// AssessmentLevel is a student's state on one level of an assessment. Result
+// is only set once the results are released.
+type AssessmentLevel struct {
+	LevelID       string                `json:"levelId"`
+	Runs          int                   `json:"runs"`
+	RunsRemaining *int                  `json:"runsRemaining,omitempty"`
+	SubmittedAt   int64                 `json:"submittedAt,omitempty"`
+	Result        *AssessmentSubmission `json:"result,omitempty"`
+}
+
+// StudentAssessment is an assessment as a student sees it. RemainingSeconds
+// counts down while it is in progress, and Score is only set once the results
+// are released.
+type StudentAssessment struct {
+	AssignmentID     string            `json:"assignmentId"`
+	Status           AssessmentStatus  `json:"status"`
+	TimeLimitMinutes int               `json:"timeLimitMinutes"`
+	MaxRuns          int               `json:"maxRuns"`
+	StartedAt        int64             `json:"startedAt,omitempty"`
+	EndsAt           int64             `json:"endsAt,omitempty"`
+	RemainingSeconds int               `json:"remainingSeconds"`
+	Released         bool              `json:"released"`
+	Score            *int              `json:"score,omitempty"`
+	MaxScore         int               `json:"maxScore"`
+	Levels           []AssessmentLevel `json:"levels"`
+}
+
+// AssessmentResultRow is one student's assessment as the teacher sees it.
+// Score is the sum of the stars of their submissions.
+type AssessmentResultRow struct {
+	ClassMember
+	Status      AssessmentStatus       `json:"status"`
+	StartedAt   int64                  `json:"startedAt,omitempty"`
+	EndedAt     int64                  `json:"endedAt,omitempty"`
+	Runs        int                    `json:"runs"`
+	Score       int                    `json:"score"`
+	Submissions []AssessmentSubmission `json:"submissions"`
+}
+
+// AssessmentResults are the results of every student of a class on an
+// assessment. MaxScore is three stars per level.
+type AssessmentResults struct {
+	Assignment Assignment            `json:"assignment"`
+	MaxScore   int                   `json:"maxScore"`
+	Rows       []AssessmentResultRow `json:"rows"`
+}
+
+// assessmentSession is a student's attempt at an assessment.
+type assessmentSession struct {
+	startedAt   int64
+	endsAt      int64
+	finishedAt  int64
+	runs        map[string]int
+	submissions map[string]*AssessmentSubmission
+}
+
+// StartAssessment starts the countdown of a student on an assessment.
+// Starting again returns the running or ended assessment unchanged.
+func (s *Service) StartAssessment(ctx context.Context, userID, assignmentID string) (StudentAssessment, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	assignment, err := s.studentAssessment(profile, assignmentID, now)
+	if err != nil {
+		return StudentAssessment{}, err
+	}
+	if s.assessments[assignment.ID][profile.ID] == nil {
+		if assignment.Status == AssignmentClosed || now >= assignment.DueAt {
+			return StudentAssessment{}, ErrAssessmentEnded.With("assignmentId", assignment.ID)
+		}
+		session := &assessmentSession{
+			startedAt:   now,
+			endsAt:      assignment.DueAt,
+			runs:        make(map[string]int),
+			submissions: make(map[string]*AssessmentSubmission),
+		}
+		if limit := assignment.Assessment.TimeLimitMinutes; limit > 0 {
+			session.endsAt = min(session.endsAt, now+int64(limit)*time.Minute.Milliseconds())
+		}
+		if s.assessments[assignment.ID] == nil {
+			s.assessments[assignment.ID] = make(map[string]*assessmentSession)
+		}
+		s.assessments[assignment.ID][profile.ID] = session
+	}
+	return s.assessmentView(assignment, profile.ID, now), nil
+}
+
+// StudentAssessment returns the state of a student on an assessment.
+func (s *Service) StudentAssessment(ctx context.Context, userID, assignmentID string) (StudentAssessment, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now().UnixMilli()
+	assignment, err := s.studentAssessment(profile, assignmentID, now)
+	if err != nil {
+		return StudentAssessment{}, err
+	}
+	return s.assessmentView(assignment, profile.ID, now), nil
+}
+
+// SubmitAssessment records the program a student hands in for a level of an
+// assessment, replacing an earlier one, and scores it with the simulator.
+// Submitting does not use up a run.
+func (s *Service) SubmitAssessment(ctx context.Context, userID, assignmentID, levelID string, program []Instruction) (StudentAssessment, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	assignment, session, err := s.runningAssessment(profile, assignmentID, now)
+	if err != nil {
+		return StudentAssessment{}, err
+	}
+	if !assessmentCovers(assignment, levelID) {
+		return StudentAssessment{}, ErrLevelNotFound.With("levelId", levelID)
+	}
+	level, ok := s.levels[levelID]
+	if !ok {
+		return StudentAssessment{}, ErrLevelNotFound.With("levelId", levelID)
+	}
+	if err := validateProgram(level, program); err != nil {
+		return StudentAssessment{}, err
+	}
+
+	result := newSimulator(level).run(program)
+	session.submissions[levelID] = &AssessmentSubmission{
+		LevelID: levelID,
+		Program: append([]Instruction(nil), program...),
+		Result: WorkResult{
+			Success:   result.Success,
+			Steps:     result.Steps,
+			Stars:     result.Stars,
+			ErrorCode: result.ErrorCode,
+		},
+		SubmittedAt: now,
+	}
+	return s.assessmentView(assignment, profile.ID, now), nil
+}
+
+// FinishAssessment hands an assessment in before the time is up.
+func (s *Service) FinishAssessment(ctx context.Context, userID, assignmentID string) (StudentAssessment, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	assignment, session, err := s.runningAssessment(profile, assignmentID, now)
+	if err != nil {
+		return StudentAssessment{}, err
+	}
+	session.finishedAt = now
+	return s.assessmentView(assignment, profile.ID, now), nil
+}
+
+// AssessmentResults returns the submissions and scores of each student on the
+// roster, whether or not they are released.
+func (s *Service) AssessmentResults(ctx context.Context, classID, assignmentID string, roster []ClassMember) (AssessmentResults, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	assignment, err := s.classAssessment(classID, assignmentID)
+	if err != nil {
+		return AssessmentResults{}, err
+	}
+	now := time.Now().UnixMilli()
+	results := AssessmentResults{
+		Assignment: assignmentCopy(assignment),
+		MaxScore:   3 * len(assignment.Items),
+		Rows:       make([]AssessmentResultRow, 0, len(roster)),
+	}
+	for _, member := range roster {
+		row := AssessmentResultRow{ClassMember: member, Status: AssessmentNotStarted, Submissions: make([]AssessmentSubmission, 0)}
+		if session := s.assessments[assignment.ID][member.ID]; session != nil {
+			row.Status = assessmentStatus(assignment, session, now)
+			row.StartedAt = session.startedAt
+			if row.Status == AssessmentEnded {
+				row.EndedAt = sessionEndedAt(assignment, session)
+			}
+			for _, item := range assignment.Items {
+				row.Runs += session.runs[item.LevelID]
+				if submission := session.submissions[item.LevelID]; submission != nil {
+					row.Score += submission.Result.Stars
+					row.Submissions = append(row.Submissions, submissionCopy(submission))
+				}
+			}
+		}
+		results.Rows = append(results.Rows, row)
+	}
+	return results, nil
+}
+
+// ReleaseAssessment shows students their assessment results and notifies
+// those who took part. The assessment must be closed or past due.
+func (s *Service) ReleaseAssessment(ctx context.Context, classID, assignmentID string) (Assignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignment, err := s.classAssessment(classID, assignmentID)
+	if err != nil {
+		return Assignment{}, err
+	}
+	if assignment.Assessment.ReleasedAt != 0 {
+		return Assignment{}, ErrAssessmentReleased.With("assignmentId", assignmentID)
+	}
+	now := time.Now()
+	if assignment.Status != AssignmentClosed && now.UnixMilli() < assignment.DueAt {
+		return Assignment{}, ErrAssessmentNotEnded.With("assignmentId", assignmentID)
+	}
+
+	assessment := *assignment.Assessment
+	assessment.ReleasedAt = now.UnixMilli()
+	assignment.Assessment = &assessment
+	maxScore := 3 * len(assignment.Items)
+	for studentID, session := range s.assessments[assignment.ID] {
+		score := 0
+		for _, submission := range session.submissions {
+			score += submission.Result.Stars
+		}
+		s.notify(studentID, Notification{
+			Kind:  "assessment.released",
+			Ref:   assignment.ID,
+			Title: assignment.Title,
+			Body:  fmt.Sprintf("得分 %d / %d", score, maxScore),
+		}, now)
+	}
+	return assignmentCopy(assignment), nil
+}
+
+// validateAssessment checks the assessment settings of an assignment and
+// clears its release.
+func validateAssessment(assignment *Assignment, fail func(path, code string, params ...any)) {
+	assessment := *assignment.Assessment
+	assessment.ReleasedAt = 0
+	assignment.Assessment = &assessment
+	if assessment.TimeLimitMinutes < 0 || assessment.TimeLimitMinutes > maxAssessmentMinutes {
+		fail("assessment.timeLimitMinutes", "out_of_range", "min", 0, "max", maxAssessmentMinutes)
+	}
+	if assessment.MaxRuns < 0 || assessment.MaxRuns > maxAssessmentRuns {
+		fail("assessment.maxRuns", "out_of_range", "min", 0, "max", maxAssessmentRuns)
+	}
+	levels := make(map[string]bool, len(assignment.Items))
+	for i, item := range assignment.Items {
+		if item.Kind != WorkLevel {
+			fail(fmt.Sprintf("items[%d].kind", i), "not_level")
+			return
+		}
+		if levels[item.LevelID] {
+			fail(fmt.Sprintf("items[%d].levelId", i), "duplicate", "id", item.LevelID)
+		}
+		levels[item.LevelID] = true
+	}
+}
+
+// studentAssessment returns an assessment the student can see. Callers must
+// hold the lock.
+func (s *Service) studentAssessment(profile *StudentProfile, assignmentID string, now int64) (*Assignment, error) {
+	assignment, err := s.studentAssignment(profile, assignmentID, now)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.Assessment == nil {
+		return nil, ErrAssessmentNotFound.With("assignmentId", assignmentID)
+	}
+	return assignment, nil
+}
+
+// classAssessment returns an assessment of a class. Callers must hold the
+// lock.
+func (s *Service) classAssessment(classID, assignmentID string) (*Assignment, error) {
+	assignment, ok := s.assignments[assignmentID]
+	if !ok || assignment.ClassID != classID {
+		return nil, ErrAssignmentNotFound.With("assignmentId", assignmentID)
+	}
+	if assignment.Assessment == nil {
+		return nil, ErrAssessmentNotFound.With("assignmentId", assignmentID)
+	}
+	return assignment, nil
+}
+
+// runningAssessment returns an assessment the student is taking right now.
+// Callers must hold the lock.
+func (s *Service) runningAssessment(profile *StudentProfile, assignmentID string, now int64) (*Assignment, *assessmentSession, error) {
+	assignment, err := s.studentAssessment(profile, assignmentID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	session := s.assessments[assignment.ID][profile.ID]
+	if session == nil {
+		return nil, nil, ErrAssessmentNotStarted.With("assignmentId", assignment.ID)
+	}
+	if assessmentStatus(assignment, session, now) != AssessmentInProgress {
+		return nil, nil, ErrAssessmentEnded.With("assignmentId", assignment.ID)
+	}
+	return assignment, session, nil
+}
+
+// levelAssessment returns the open assessment of the student's class that
+// covers a level, with the student's session on it if they started it.
+// Callers must hold the lock.
+func (s *Service) levelAssessment(profile *StudentProfile, levelID string, now int64) (*Assignment, *assessmentSession) {
+	for _, assignment := range s.assignments {
+		if assignment.Assessment == nil || assignment.ClassID != profile.ClassID || assignment.Status == AssignmentClosed {
+			continue
+		}
+		if now < assignment.StartAt || now >= assignment.DueAt || !assessmentCovers(assignment, levelID) {
+			continue
+		}
+		return assignment, s.assessments[assignment.ID][profile.ID]
+	}
+	return nil, nil
+}
+
+// checkAssessmentRun decides whether a student may run a level that an open
+// assessment covers and counts the run. It returns the session the run
+// belongs to, or nil when no assessment covers the level. Callers must hold
+// the write lock.
+func (s *Service) checkAssessmentRun(profile *StudentProfile, levelID string, now int64) (*Assignment, *assessmentSession, error) {
+	assignment, session := s.levelAssessment(profile, levelID, now)
+	if assignment == nil {
+		return nil, nil, nil
+	}
+	if session == nil {
+		return nil, nil, ErrAssessmentNotStarted.With("assignmentId", assignment.ID)
+	}
+	if assessmentStatus(assignment, session, now) != AssessmentInProgress {
+		return nil, nil, ErrAssessmentEnded.With("assignmentId", assignment.ID)
+	}
+	if limit := assignment.Assessment.MaxRuns; limit > 0 && session.runs[levelID] >= limit {
+		return nil, nil, ErrAssessmentRunLimit.With("assignmentId", assignment.ID).With("maxRuns", limit)
+	}
+	session.runs[levelID]++
+	return assignment, session, nil
+}
+
+// checkAssessmentFeedback refuses requests that would give a student feedback
+// on a level while an open assessment covers it. Callers must hold the lock.
+func (s *Service) checkAssessmentFeedback(profile *StudentProfile, levelID string, now int64, err *apperr.Error) error {
+	if assignment, _ := s.levelAssessment(profile, levelID, now); assignment != nil {
+		return err.With("assignmentId", assignment.ID)
+	}
+	return nil
+}
+
+// assessmentView builds what a student sees of an assessment. Callers must
+// hold the lock.
+func (s *Service) assessmentView(assignment *Assignment, studentID string, now int64) StudentAssessment {
+	assessment := assignment.Assessment
+	view := StudentAssessment{
+		AssignmentID:     assignment.ID,
+		Status:           AssessmentNotStarted,
+		TimeLimitMinutes: assessment.TimeLimitMinutes,
+		MaxRuns:          assessment.MaxRuns,
+		Released:         assessment.ReleasedAt != 0,
+		MaxScore:         3 * len(assignment.Items),
+		Levels:           make([]AssessmentLevel, 0, len(assignment.Items)),
+	}
+	session := s.assessments[assignment.ID][studentID]
+	if session != nil {
+		view.Status = assessmentStatus(assignment, session, now)
+		view.StartedAt, view.EndsAt = session.startedAt, session.endsAt
+		if view.Status == AssessmentInProgress {
+			view.RemainingSeconds = int((session.endsAt - now + 999) / 1000)
+		}
+	}
+	score := 0
+	for _, item := range assignment.Items {
+		level := AssessmentLevel{LevelID: item.LevelID}
+		if session != nil {
+			level.Runs = session.runs[item.LevelID]
+			if submission := session.submissions[item.LevelID]; submission != nil {
+				level.SubmittedAt = submission.SubmittedAt
+				if view.Released {
+					result := submissionCopy(submission)
+					level.Result = &result
+					score += submission.Result.Stars
+				}
+			}
+		}
+		if assessment.MaxRuns > 0 {
+			remaining := max(assessment.MaxRuns-level.Runs, 0)
+			level.RunsRemaining = &remaining
+		}
+		view.Levels = append(view.Levels, level)
+	}
+	if view.Released {
+		view.Score = &score
+	}
+	return view
+}
+
+// hideAssessmentStars removes the stars of unreleased assessment submissions
+// from a student's completion.
+func hideAssessmentStars(assignment *Assignment, completion *AssignmentCompletion) {
+	if assignment.Assessment == nil || assignment.Assessment.ReleasedAt != 0 {
+		return
+	}
+	for i := range completion.Items {
+		completion.Items[i].Stars = 0
+	}
+}
+
+// hiddenResult strips a run of everything that tells whether it worked.
+func hiddenResult(result SimulationResult, assignment *Assignment, session *assessmentSession, levelID string) SimulationResult {
+	hidden := SimulationResult{
+		AttemptID: result.AttemptID,
+		Log:       []SimulationStep{},
+		Metadata:  result.Metadata,
+		Hidden:    true,
+	}
+	if limit := assignment.Assessment.MaxRuns; limit > 0 {
+		remaining := max(limit-session.runs[levelID], 0)
+		hidden.RunsRemaining = &remaining
+	}
+	return hidden
+}
+
+func assessmentStatus(assignment *Assignment, session *assessmentSession, now int64) AssessmentStatus {
+	if session.finishedAt != 0 || now >= session.endsAt || assignment.Status == AssignmentClosed {
+		return AssessmentEnded
+	}
+	return AssessmentInProgress
+}
+
+// sessionEndedAt is when an ended session stopped: when it was handed in, the
+// time ran out or the assignment was closed, whichever came first.
+func sessionEndedAt(assignment *Assignment, session *assessmentSession) int64 {
+	endedAt := session.endsAt
+	if session.finishedAt != 0 {
+		endedAt = min(endedAt, session.finishedAt)
+	}
+	if assignment.Status == AssignmentClosed {
+		endedAt = min(endedAt, assignment.ClosedAt)
+	}
+	return endedAt
+}
+
+// submission returns the submission for a level, or nil when the session is
+// nil or has none.
+func (session *assessmentSession) submission(levelID string) *AssessmentSubmission {
+	if session == nil {
+		return nil
+	}
+	return session.submissions[levelID]
+}
+
+func assessmentCovers(assignment *Assignment, levelID string) bool {
+	for _, item := range assignment.Items {
+		if item.Kind == WorkLevel && item.LevelID == levelID {
+			return true
+		}
+	}
+	return false
+}
+
+func submissionCopy(submission *AssessmentSubmission) AssessmentSubmission {
+	clone := *submission
+	clone.Program = append([]Instruction(nil), submission.Program...)
+	return clone
+}
@@ -0,0 +1,464 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Errors returned by assignments.
+var (
+	ErrAssignmentNotFound = apperr.New(apperr.KindNotFound, "assignment.not_found")
+	ErrAssignmentInvalid  = apperr.New(apperr.KindUnprocessable, "assignment.invalid")
+	ErrAssignmentClosed   = apperr.New(apperr.KindConflict, "assignment.closed")
+)
+
+// maxAssignmentItems bounds the number of tasks in one assignment.
+const maxAssignmentItems = 50
+
+// AssignmentStatus is whether an assignment still accepts work.
+type AssignmentStatus string
+
+const (
+	AssignmentOpen   AssignmentStatus = "open"
+	AssignmentClosed AssignmentStatus = "closed"
+)
+
+// AssignmentItem is one task of an assignment: reaching MinStars on a level,
+// or handing in a sandbox project for the task described by Title.
+type AssignmentItem struct {
+	ID       string   `json:"id"`
+	Kind     WorkKind `json:"kind"`
+	LevelID  string   `json:"levelId,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	MinStars int      `json:"minStars,omitempty"`
+}
+
+// Assignment is a set of tasks given to a class with a schedule. Students see
+// it from StartAt; work finished after DueAt is late, and tasks still open when
+// the assignment is past due or closed are missing. Assignments with an
+// Assessment are quizzes, whose level tasks are done by submitting a program
+// during the assessment.
+type Assignment struct {
+	ID          string           `json:"id"`
+	ClassID     string           `json:"classId"`
+	OwnerID     string           `json:"ownerId,omitempty"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Items       []AssignmentItem `json:"items"`
+	Assessment  *Assessment      `json:"assessment,omitempty"`
+	StartAt     int64            `json:"startAt"`
+	DueAt       int64            `json:"dueAt"`
+	Status      AssignmentStatus `json:"status"`
+	CreatedAt   int64            `json:"createdAt"`
+	ClosedAt    int64            `json:"closedAt,omitempty"`
+}
+
+// AssignmentInput is what a teacher fills in to create an assignment.
+// StartAt defaults to now; Assessment makes it a quiz.
+type AssignmentInput struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Items       []AssignmentItem `json:"items"`
+	Assessment  *Assessment      `json:"assessment"`
+	StartAt     int64            `json:"startAt"`
+	DueAt       int64            `json:"dueAt"`
+}
+
+// CompletionStatus is how far a student is with an assignment or a task.
+type CompletionStatus string
+
+const (
+	CompletionDone       CompletionStatus = "done"
+	CompletionLate       CompletionStatus = "late"
+	CompletionMissing    CompletionStatus = "missing"
+	CompletionInProgress CompletionStatus = "in_progress"
+)
+
+// ItemCompletion is a student's state on one task. Stars is the student's
+// best result on a level task; WorkID is the submission of a sandbox task.
+type ItemCompletion struct {
+	ItemID      string           `json:"itemId"`
+	Status      CompletionStatus `json:"status"`
+	Stars       int              `json:"stars,omitempty"`
+	WorkID      string           `json:"workId,omitempty"`
+	CompletedAt int64            `json:"completedAt,omitempty"`
+}
+
+// AssignmentCompletion is a student's state on a whole assignment. Status is
+// missing when any task is missing, late when every task is finished but some
+// late, and done when every task was finished on time.
+type AssignmentCompletion struct {
+	Status    CompletionStatus `json:"status"`
+	Completed int              `json:"completed"`
+	Total     int              `json:"total"`
+	Items     []ItemCompletion `json:"items"`
+}
+
+// StudentAssignment is an assignment as a student sees it.
+type StudentAssignment struct {
+	Assignment
+	Completion AssignmentCompletion `json:"completion"`
+}
+
+// AssignmentMatrixRow is one student's completion in the matrix.
+type AssignmentMatrixRow struct {
+	ClassMember
+	AssignmentCompletion
+}
+
+// AssignmentMatrix is the completion of every student of a class, with the
+// number of students per overall status.
+type AssignmentMatrix struct {
+	Assignment Assignment               `json:"assignment"`
+	Rows       []AssignmentMatrixRow    `json:"rows"`
+	Summary    map[CompletionStatus]int `json:"summary"`
+}
+
+// CreateAssignment validates and stores an assignment for a class.
+func (s *Service) CreateAssignment(ctx context.Context, classID, ownerID string, input AssignmentInput) (Assignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	assignment := Assignment{
+		ID:          uuid.NewString(),
+		ClassID:     classID,
+		OwnerID:     ownerID,
+		Title:       strings.TrimSpace(input.Title),
+		Description: strings.TrimSpace(input.Description),
+		Items:       append([]AssignmentItem(nil), input.Items...),
+		Assessment:  input.Assessment,
+		StartAt:     input.StartAt,
+		DueAt:       input.DueAt,
+		Status:      AssignmentOpen,
+		CreatedAt:   now,
+	}
+	if assignment.StartAt == 0 {
+		assignment.StartAt = now
+	}
+
+	var problems []apperr.Problem
+	fail := func(path, code string, params ...any) {
+		problems = append(problems, apperr.NewProblem(path, code, params...))
+	}
+	if assignment.Title == "" {
+		fail("title", "required")
+	}
+	if assignment.DueAt == 0 {
+		fail("dueAt", "required")
+	} else if assignment.DueAt <= assignment.StartAt {
+		fail("dueAt", "before_start")
+	}
+	if len(assignment.Items) == 0 {
+		fail("items", "required")
+	}
+	if len(assignment.Items) > maxAssignmentItems {
+		fail("items", "too_many", "max", maxAssignmentItems)
+	}
+	seen := make(map[string]bool, len(assignment.Items))
+	for i := range assignment.Items {
+		item := &assignment.Items[i]
+		if item.ID == "" {
+			item.ID = fmt.Sprintf("t%d", i+1)
+		}
+		path := fmt.Sprintf("items[%d]", i)
+		if seen[item.ID] {
+			fail(path+".id", "duplicate", "id", item.ID)
+		}
+		seen[item.ID] = true
+		item.Title = strings.TrimSpace(item.Title)
+		switch item.Kind {
+		case WorkLevel:
+			if _, ok := s.levels[item.LevelID]; !ok {
+				fail(path+".levelId", "not_found", "id", item.LevelID)
+			}
+			if item.MinStars == 0 {
+				item.MinStars = 1
+			}
+			if item.MinStars < 1 || item.MinStars > 3 {
+				fail(path+".minStars", "out_of_range", "min", 1, "max", 3)
+			}
+		case WorkProject:
+			item.LevelID, item.MinStars = "", 0
+			if item.Title == "" {
+				fail(path+".title", "required")
+			}
+		default:
+			fail(path+".kind", "unknown", "value", string(item.Kind))
+		}
+	}
+	if assignment.Assessment != nil {
+		validateAssessment(&assignment, fail)
+	}
+	if len(problems) > 0 {
+		return Assignment{}, ErrAssignmentInvalid.With("problems", problems)
+	}
+
+	s.assignments[assignment.ID] = &assignment
+	return assignmentCopy(&assignment), nil
+}
+
+// ClassAssignments lists the assignments of a class in a status, or all of
+// them when status is empty, soonest due first.
+func (s *Service) ClassAssignments(ctx context.Context, classID string, status AssignmentStatus) []Assignment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterAssignments(func(assignment *Assignment) bool {
+		return assignment.ClassID == classID && (status == "" || assignment.Status == status)
+	})
+}
+
+// CloseAssignment stops an assignment from accepting work. Tasks finished
+// after closing do not count.
+func (s *Service) CloseAssignment(ctx context.Context, classID, assignmentID string) (Assignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignment, ok := s.assignments[assignmentID]
+	if !ok || assignment.ClassID != classID {
+		return Assignment{}, ErrAssignmentNotFound.With("assignmentId", assignmentID)
+	}
+	if assignment.Status == AssignmentClosed {
+		return Assignment{}, ErrAssignmentClosed.With("assignmentId", assignmentID)
+	}
+	assignment.Status = AssignmentClosed
+	assignment.ClosedAt = time.Now().UnixMilli()
+	return assignmentCopy(assignment), nil
+}
+
+// AssignmentMatrix returns the completion of each student on the roster.
+func (s *Service) AssignmentMatrix(ctx context.Context, classID, assignmentID string, roster []ClassMember) (AssignmentMatrix, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	assignment, ok := s.assignments[assignmentID]
+	if !ok || assignment.ClassID != classID {
+		return AssignmentMatrix{}, ErrAssignmentNotFound.With("assignmentId", assignmentID)
+	}
+	now := time.Now().UnixMilli()
+	matrix := AssignmentMatrix{
+		Assignment: assignmentCopy(assignment),
+		Rows:       make([]AssignmentMatrixRow, 0, len(roster)),
+		Summary:    map[CompletionStatus]int{CompletionDone: 0, CompletionLate: 0, CompletionMissing: 0, CompletionInProgress: 0},
+	}
+	for _, member := range roster {
+		completion := s.assignmentCompletion(assignment, member.ID, now)
+		matrix.Summary[completion.Status]++
+		matrix.Rows = append(matrix.Rows, AssignmentMatrixRow{ClassMember: member, AssignmentCompletion: completion})
+	}
+	return matrix, nil
+}
+
+// StudentAssignments lists the started assignments of the student's class,
+// open ones first and then soonest due.
+func (s *Service) StudentAssignments(ctx context.Context, userID string) []StudentAssignment {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now().UnixMilli()
+	assignments := s.filterAssignments(func(assignment *Assignment) bool {
+		return assignment.ClassID == profile.ClassID && assignment.StartAt <= now
+	})
+	sort.SliceStable(assignments, func(i, j int) bool {
+		return assignments[i].Status == AssignmentOpen && assignments[j].Status != AssignmentOpen
+	})
+	result := make([]StudentAssignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		completion := s.assignmentCompletion(s.assignments[assignment.ID], profile.ID, now)
+		hideAssessmentStars(&assignment, &completion)
+		result = append(result, StudentAssignment{Assignment: assignment, Completion: completion})
+	}
+	return result
+}
+
+// StudentAssignment returns one started assignment of the student's class.
+func (s *Service) StudentAssignment(ctx context.Context, userID, assignmentID string) (StudentAssignment, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now().UnixMilli()
+	assignment, err := s.studentAssignment(profile, assignmentID, now)
+	if err != nil {
+		return StudentAssignment{}, err
+	}
+	completion := s.assignmentCompletion(assignment, profile.ID, now)
+	hideAssessmentStars(assignment, &completion)
+	return StudentAssignment{Assignment: assignmentCopy(assignment), Completion: completion}, nil
+}
+
+// studentAssignment returns an assignment the student can see. Callers must
+// hold the lock.
+func (s *Service) studentAssignment(profile *StudentProfile, assignmentID string, now int64) (*Assignment, error) {
+	assignment, ok := s.assignments[assignmentID]
+	if !ok || assignment.ClassID != profile.ClassID || assignment.StartAt > now {
+		return nil, ErrAssignmentNotFound.With("assignmentId", assignmentID)
+	}
+	return assignment, nil
+}
+
+// assignmentCompletion works out a student's state on an assignment from
+// their progress and submissions, or from their assessment submissions for a
+// quiz. Callers must hold the lock.
+func (s *Service) assignmentCompletion(assignment *Assignment, studentID string, now int64) AssignmentCompletion {
+	cutoff := now
+	if assignment.Status == AssignmentClosed {
+		cutoff = assignment.ClosedAt
+	}
+	completion := AssignmentCompletion{Status: CompletionDone, Total: len(assignment.Items), Items: make([]ItemCompletion, 0, len(assignment.Items))}
+	late, missing := false, false
+	for _, item := range assignment.Items {
+		entry := ItemCompletion{ItemID: item.ID, Status: CompletionInProgress}
+		var completedAt int64
+		var done bool
+		switch item.Kind {
+		case WorkLevel:
+			if assignment.Assessment != nil {
+				if submission := s.assessments[assignment.ID][studentID].submission(item.LevelID); submission != nil {
+					entry.Stars = submission.Result.Stars
+					completedAt, done = submission.SubmittedAt, true
+				}
+				break
+			}
+			if profile, ok := s.profiles[studentID]; ok {
+				entry.Stars = profile.Progress[item.LevelID].Stars
+			}
+			completedAt, done = s.levelReachedAt(studentID, item.LevelID, item.MinStars)
+		case WorkProject:
+			if work := s.assignmentWork(studentID, assignment.ID, item.ID); work != nil {
+				entry.WorkID = work.ID
+				completedAt, done = work.SubmittedAt, work.Status != WorkRejected
+			}
+		}
+		if done && completedAt > cutoff {
+			done = false
+		}
+		switch {
+		case done && completedAt > assignment.DueAt:
+			entry.Status, entry.CompletedAt = CompletionLate, completedAt
+			late = true
+		case done:
+			entry.Status, entry.CompletedAt = CompletionDone, completedAt
+		case cutoff > assignment.DueAt || assignment.Status == AssignmentClosed:
+			entry.Status = CompletionMissing
+			missing = true
+		default:
+			completion.Status = CompletionInProgress
+		}
+		if done {
+			completion.Completed++
+		}
+		completion.Items = append(completion.Items, entry)
+	}
+	switch {
+	case missing:
+		completion.Status = CompletionMissing
+	case completion.Status == CompletionInProgress:
+	case late:
+		completion.Status = CompletionLate
+	}
+	return completion
+}
+
+// levelReachedAt returns when the student first reached minStars on a level,
+// taken from the star entries of their ledger. Progress that predates the
+// ledger counts from its completion time. Callers must hold the lock.
+func (s *Service) levelReachedAt(studentID, levelID string, minStars int) (int64, bool) {
+	profile, ok := s.profiles[studentID]
+	if !ok {
+		return 0, false
+	}
+	progress := profile.Progress[levelID]
+	if progress.Stars < minStars {
+		return 0, false
+	}
+	prefix := "level:" + levelID + ":stars:"
+	var reachedAt int64
+	for _, entry := range s.ledgers[studentID] {
+		stars, err := strconv.Atoi(strings.TrimPrefix(entry.Key, prefix))
+		if !strings.HasPrefix(entry.Key, prefix) || err != nil || stars < minStars {
+			continue
+		}
+		if reachedAt == 0 || entry.CreatedAt < reachedAt {
+			reachedAt = entry.CreatedAt
+		}
+	}
+	if reachedAt == 0 {
+		reachedAt = progress.CompletedAt
+	}
+	return reachedAt, true
+}
+
+// assignmentWork returns the student's submission for a sandbox task.
+// Callers must hold the lock.
+func (s *Service) assignmentWork(studentID, assignmentID, itemID string) *WorkSubmission {
+	for _, work := range s.works {
+		if work.OwnerID == studentID && work.AssignmentID == assignmentID && work.ItemID == itemID {
+			return work
+		}
+	}
+	return nil
+}
+
+// bindAssignmentWork checks that a submission can be handed in for a task of
+// an assignment and records the task on it. Callers must hold the lock.
+func (s *Service) bindAssignmentWork(profile *StudentProfile, work *WorkSubmission, assignmentID, itemID string) error {
+	assignment, err := s.studentAssignment(profile, assignmentID, time.Now().UnixMilli())
+	if err != nil {
+		return err
+	}
+	if assignment.Status == AssignmentClosed {
+		return ErrAssignmentClosed.With("assignmentId", assignmentID)
+	}
+	for _, item := range assignment.Items {
+		if item.ID != itemID {
+			continue
+		}
+		if item.Kind != work.Kind || (item.Kind == WorkLevel && item.LevelID != work.LevelID) {
+			return ErrWorkInvalid.With("problems", []apperr.Problem{apperr.NewProblem("itemId", "mismatch", "id", itemID)})
+		}
+		work.AssignmentID, work.ItemID = assignment.ID, item.ID
+		return nil
+	}
+	return ErrWorkInvalid.With("problems", []apperr.Problem{apperr.NewProblem("itemId", "not_found", "id", itemID)})
+}
+
+// filterAssignments returns copies of the matching assignments, soonest due
+// first. Callers must hold the lock.
+func (s *Service) filterAssignments(match func(*Assignment) bool) []Assignment {
+	assignments := make([]Assignment, 0)
+	for _, assignment := range s.assignments {
+		if match(assignment) {
+			assignments = append(assignments, assignmentCopy(assignment))
+		}
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		if assignments[i].DueAt != assignments[j].DueAt {
+			return assignments[i].DueAt < assignments[j].DueAt
+		}
+		return assignments[i].ID < assignments[j].ID
+	})
+	return assignments
+}
+
+func assignmentCopy(assignment *Assignment) Assignment {
+	clone := *assignment
+	clone.Items = append([]AssignmentItem(nil), assignment.Items...)
+	if assignment.Assessment != nil {
+		assessment := *assignment.Assessment
+		clone.Assessment = &assessment
+	}
+	return clone
+}
@@ -0,0 +1,199 @@
+package student
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Attempt retention limits. Older attempts beyond either bound are pruned
+// whenever a new attempt is recorded for the same student and level.
+const (
+	maxAttemptsPerLevel = 50
+	attemptRetention    = 90 * 24 * time.Hour
+)
+
+// ErrAttemptNotFound indicates the requested attempt does not exist for the student.
+var ErrAttemptNotFound = apperr.New(apperr.KindNotFound, "attempt.not_found")
+
+// Attempt is a single persisted run of a program against a level.
+// Misconceptions are those the analyzer found behind a failed run.
+type Attempt struct {
+	ID             string          `json:"id"`
+	StudentID      string          `json:"studentId"`
+	LevelID        string          `json:"levelId"`
+	Program        []Instruction   `json:"program"`
+	Success        bool            `json:"success"`
+	Stars          int             `json:"stars"`
+	Steps          int             `json:"steps"`
+	ErrorCode      string          `json:"errorCode,omitempty"`
+	Misconceptions []Misconception `json:"misconceptions,omitempty"`
+	Revision       int             `json:"revision"`
+	Duration       int             `json:"duration"`
+	CreatedAt      int64           `json:"createdAt"`
+}
+
+// AttemptSummary is the list representation of an attempt without its program.
+type AttemptSummary struct {
+	ID             string          `json:"id"`
+	LevelID        string          `json:"levelId"`
+	Success        bool            `json:"success"`
+	Stars          int             `json:"stars"`
+	Steps          int             `json:"steps"`
+	ErrorCode      string          `json:"errorCode,omitempty"`
+	Misconceptions []Misconception `json:"misconceptions,omitempty"`
+	Revision       int             `json:"revision"`
+	Duration       int             `json:"duration"`
+	CreatedAt      int64           `json:"createdAt"`
+}
+
+// RunRequest describes a program execution submitted by a student. Source
+// holds the program in text form and is used when Program is empty.
+type RunRequest struct {
+	Program  []Instruction
+	Source   string
+	Duration *int
+}
+
+// Attempts lists a student's attempts on a level, newest first.
+func (s *Service) Attempts(ctx context.Context, studentID, levelID string) ([]AttemptSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempts := s.attempts[studentID][levelID]
+	summaries := make([]AttemptSummary, 0, len(attempts))
+	for i := len(attempts) - 1; i >= 0; i-- {
+		summaries = append(summaries, attempts[i].summary())
+	}
+	return summaries, nil
+}
+
+// AttemptHistory lists a student's attempts on all levels, newest first.
+func (s *Service) AttemptHistory(ctx context.Context, studentID string) []AttemptSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := make([]AttemptSummary, 0)
+	for _, attempts := range s.attempts[studentID] {
+		for _, attempt := range attempts {
+			summaries = append(summaries, attempt.summary())
+		}
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].CreatedAt > summaries[j].CreatedAt })
+	return summaries
+}
+
+// Attempt returns a single attempt of a student including its program.
+func (s *Service) Attempt(ctx context.Context, studentID, attemptID string) (Attempt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, attempts := range s.attempts[studentID] {
+		for _, attempt := range attempts {
+			if attempt.ID == attemptID {
+				return attempt.clone(), nil
+			}
+		}
+	}
+	return Attempt{}, ErrAttemptNotFound
+}
+
+// recordAttempt stores the outcome of a run and applies the retention limits.
+// Callers must hold the write lock.
+func (s *Service) recordAttempt(studentID string, level LevelDefinition, req RunRequest, result SimulationResult, now time.Time) Attempt {
+	levelID := level.ID
+	attempt := Attempt{
+		ID:        uuid.NewString(),
+		StudentID: studentID,
+		LevelID:   levelID,
+		Program:   cloneInstructions(req.Program),
+		Success:   result.Success,
+		Stars:     result.Stars,
+		Steps:     result.Steps,
+		ErrorCode: result.ErrorCode,
+		Revision:  level.Revision,
+		CreatedAt: now.UnixMilli(),
+	}
+	if req.Duration != nil && *req.Duration > 0 {
+		attempt.Duration = *req.Duration
+	}
+
+	levels, ok := s.attempts[studentID]
+	if !ok {
+		levels = make(map[string][]Attempt)
+		s.attempts[studentID] = levels
+	}
+	levels[levelID] = pruneAttempts(append(levels[levelID], attempt), now)
+	return attempt
+}
+
+// tagAttempt records the misconceptions behind a failed attempt. Attempts
+// pruned in the meantime are skipped.
+func (s *Service) tagAttempt(studentID, levelID, attemptID string, kinds []Misconception) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.attempts[studentID][levelID]
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if attempts[i].ID == attemptID {
+			attempts[i].Misconceptions = kinds
+			return
+		}
+	}
+}
+
+func pruneAttempts(attempts []Attempt, now time.Time) []Attempt {
+	cutoff := now.Add(-attemptRetention).UnixMilli()
+	start := sort.Search(len(attempts), func(i int) bool { return attempts[i].CreatedAt > cutoff })
+	if len(attempts)-start > maxAttemptsPerLevel {
+		start = len(attempts) - maxAttemptsPerLevel
+	}
+	if start == 0 {
+		return attempts
+	}
+	return append([]Attempt(nil), attempts[start:]...)
+}
+
+func (a Attempt) summary() AttemptSummary {
+	return AttemptSummary{
+		ID:             a.ID,
+		LevelID:        a.LevelID,
+		Success:        a.Success,
+		Stars:          a.Stars,
+		Steps:          a.Steps,
+		ErrorCode:      a.ErrorCode,
+		Revision:       a.Revision,
+		Misconceptions: append([]Misconception(nil), a.Misconceptions...),
+		Duration:       a.Duration,
+		CreatedAt:      a.CreatedAt,
+	}
+}
+
+func (a Attempt) clone() Attempt {
+	clone := a
+	clone.Program = cloneInstructions(a.Program)
+	clone.Misconceptions = append([]Misconception(nil), a.Misconceptions...)
+	return clone
+}
+
+func cloneInstructions(program []Instruction) []Instruction {
+	if program == nil {
+		return nil
+	}
+	cloned := make([]Instruction, len(program))
+	for i, instruction := range program {
+		cloned[i] = instruction
+		cloned[i].Body = cloneInstructions(instruction.Body)
+		cloned[i].Truthy = cloneInstructions(instruction.Truthy)
+		cloned[i].Falsy = cloneInstructions(instruction.Falsy)
+		if instruction.Condition != nil {
+			condition := *instruction.Condition
+			cloned[i].Condition = &condition
+		}
+	}
+	return cloned
+}
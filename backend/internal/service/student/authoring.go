@@ -0,0 +1,309 @@
+package student
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Errors returned by the authoring operations.
+var (
+	ErrChapterNotFound = apperr.New(apperr.KindNotFound, "chapter.not_found")
+	ErrChapterExists   = apperr.New(apperr.KindConflict, "chapter.exists")
+	ErrLevelExists     = apperr.New(apperr.KindConflict, "level.exists")
+	ErrInvalidOrder    = apperr.New(apperr.KindInvalid, "order.invalid")
+)
+
+// CreateChapter adds an authored chapter after the existing ones. The chapter
+// stays hidden from students until it is assigned to a class.
+func (s *Service) CreateChapter(ctx context.Context, ownerID string, chapter ChapterDefinition) (ChapterDefinition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if locales := unsupportedLocales(chapter.Translations); len(locales) > 0 {
+		return ChapterDefinition{}, ErrUnsupportedLanguage.With("language", locales[0])
+	}
+	if chapter.ID == "" {
+		chapter.ID = "chapter-" + uuid.NewString()
+	} else if existing, _ := s.findChapter(chapter.ID); existing != nil {
+		return ChapterDefinition{}, ErrChapterExists
+	}
+
+	order := 0
+	for _, existing := range s.chapters {
+		if existing.Order > order {
+			order = existing.Order
+		}
+	}
+	chapter.Order = order + 1
+	chapter.OwnerID = ownerID
+	chapter.ClassIDs = nil
+	chapter.Levels = []LevelDefinition{}
+
+	s.chapters = append(s.chapters, chapter)
+	return chapter, nil
+}
+
+// UpdateChapter changes the title, summary and translations of a chapter.
+func (s *Service) UpdateChapter(ctx context.Context, chapterID, title, summary string, translations map[string]ChapterText) (ChapterDefinition, error) {
+	if locales := unsupportedLocales(translations); len(locales) > 0 {
+		return ChapterDefinition{}, ErrUnsupportedLanguage.With("language", locales[0])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chapter, _ := s.findChapter(chapterID)
+	if chapter == nil {
+		return ChapterDefinition{}, ErrChapterNotFound
+	}
+	chapter.Title = title
+	chapter.Summary = summary
+	chapter.Translations = translations
+	return *chapter, nil
+}
+
+// DeleteChapter removes a chapter together with its levels and revisions.
+func (s *Service) DeleteChapter(ctx context.Context, chapterID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chapter, idx := s.findChapter(chapterID)
+	if chapter == nil {
+		return ErrChapterNotFound
+	}
+	for _, level := range chapter.Levels {
+		delete(s.levels, level.ID)
+		delete(s.revisions, level.ID)
+	}
+	for id, entry := range s.compendium {
+		if entry.ChapterID == chapterID {
+			delete(s.compendium, id)
+		}
+	}
+	s.chapters = append(s.chapters[:idx], s.chapters[idx+1:]...)
+	return nil
+}
+
+// ReorderChapters rearranges the given chapters in the listed order, reusing
+// the positions they already occupy among all chapters.
+func (s *Service) ReorderChapters(ctx context.Context, chapterIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]int, 0, len(chapterIDs))
+	chapters := make([]*ChapterDefinition, 0, len(chapterIDs))
+	seen := make(map[string]struct{}, len(chapterIDs))
+	for _, id := range chapterIDs {
+		chapter, _ := s.findChapter(id)
+		if chapter == nil {
+			return ErrChapterNotFound
+		}
+		if _, dup := seen[id]; dup {
+			return ErrInvalidOrder
+		}
+		seen[id] = struct{}{}
+		chapters = append(chapters, chapter)
+		orders = append(orders, chapter.Order)
+	}
+	sort.Ints(orders)
+	for i, chapter := range chapters {
+		chapter.Order = orders[i]
+	}
+	return nil
+}
+
+// AssignChapters makes authored chapters visible to the students of a class.
+func (s *Service) AssignChapters(ctx context.Context, classID string, chapterIDs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range chapterIDs {
+		chapter, _ := s.findChapter(id)
+		if chapter == nil || contains(chapter.ClassIDs, classID) {
+			continue
+		}
+		chapter.ClassIDs = append(chapter.ClassIDs, classID)
+	}
+}
+
+// CreateLevel validates a level and appends it to a chapter as its first
+// published revision. A missing best step count is taken from the solver.
+func (s *Service) CreateLevel(ctx context.Context, chapterID string, level LevelDefinition) (LevelDefinition, error) {
+	check, err := validateLevel(level)
+	if err != nil {
+		return LevelDefinition{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chapter, _ := s.findChapter(chapterID)
+	if chapter == nil {
+		return LevelDefinition{}, ErrChapterNotFound
+	}
+	if level.ID == "" {
+		level.ID = "level-" + uuid.NewString()
+	} else if _, ok := s.levels[level.ID]; ok {
+		return LevelDefinition{}, ErrLevelExists
+	}
+	if level.BestSteps == 0 {
+		level.BestSteps = check.ShortestSteps
+	}
+
+	level = normalizeLevel(level)
+	level.ChapterID = chapterID
+	level.Revision = 1
+	level.Order = 1
+	if n := len(chapter.Levels); n > 0 {
+		level.Order = chapter.Levels[n-1].Order + 1
+	}
+
+	now := time.Now().UnixMilli()
+	chapter.Levels = append(chapter.Levels, level)
+	s.levels[level.ID] = level
+	s.revisions[level.ID] = []LevelRevision{{
+		LevelID:     level.ID,
+		Revision:    1,
+		Status:      RevisionStatusPublished,
+		Level:       level,
+		CreatedAt:   now,
+		PublishedAt: now,
+	}}
+	return level, nil
+}
+
+// UpdateLevel validates the definition and publishes it as a new revision in
+// one step, replacing any open draft.
+func (s *Service) UpdateLevel(ctx context.Context, levelID string, level LevelDefinition) (LevelDefinition, error) {
+	check, err := validateLevel(level)
+	if err != nil {
+		return LevelDefinition{}, err
+	}
+	if level.BestSteps == 0 {
+		level.BestSteps = check.ShortestSteps
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.levels[levelID]; !ok {
+		return LevelDefinition{}, ErrLevelNotFound
+	}
+	draft := s.saveDraft(levelID, level)
+	published, err := s.publishRevision(levelID, draft.Revision)
+	if err != nil {
+		return LevelDefinition{}, err
+	}
+	return published.Level, nil
+}
+
+// DeleteLevel removes a level and its revisions. Student progress on the
+// level is kept for history but no longer counts towards totals.
+func (s *Service) DeleteLevel(ctx context.Context, levelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	level, ok := s.levels[levelID]
+	if !ok {
+		return ErrLevelNotFound
+	}
+	if chapter, _ := s.findChapter(level.ChapterID); chapter != nil {
+		if idx := s.findLevelIndex(*chapter, levelID); idx >= 0 {
+			chapter.Levels = append(chapter.Levels[:idx], chapter.Levels[idx+1:]...)
+		}
+	}
+	delete(s.levels, levelID)
+	delete(s.revisions, levelID)
+	return nil
+}
+
+// ReorderLevels sets the play order of a chapter's levels. levelIDs must list
+// every level of the chapter exactly once.
+func (s *Service) ReorderLevels(ctx context.Context, chapterID string, levelIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chapter, _ := s.findChapter(chapterID)
+	if chapter == nil {
+		return ErrChapterNotFound
+	}
+	if len(levelIDs) != len(chapter.Levels) {
+		return ErrInvalidOrder
+	}
+	positions := make(map[string]int, len(levelIDs))
+	for i, id := range levelIDs {
+		if _, dup := positions[id]; dup || s.findLevelIndex(*chapter, id) < 0 {
+			return ErrInvalidOrder
+		}
+		positions[id] = i + 1
+	}
+
+	for i := range chapter.Levels {
+		chapter.Levels[i].Order = positions[chapter.Levels[i].ID]
+		level := s.levels[chapter.Levels[i].ID]
+		level.Order = chapter.Levels[i].Order
+		s.levels[level.ID] = level
+	}
+	sort.Slice(chapter.Levels, func(i, j int) bool { return chapter.Levels[i].Order < chapter.Levels[j].Order })
+	return nil
+}
+
+// normalizeLevel replaces missing lists so authored levels serialise like the
+// built-in ones.
+func normalizeLevel(level LevelDefinition) LevelDefinition {
+	if level.Tiles == nil {
+		level.Tiles = []Tile{}
+	}
+	if level.Hints == nil {
+		level.Hints = []string{}
+	}
+	if level.AllowedBlocks == nil {
+		level.AllowedBlocks = []string{}
+	}
+	return level
+}
+
+// chapterVisible reports whether students of the class can see the chapter.
+func chapterVisible(chapter ChapterDefinition, classID string) bool {
+	return chapter.OwnerID == "" || contains(chapter.ClassIDs, classID)
+}
+
+// levelVisible reports whether the level belongs to a chapter visible to the
+// student. Callers must hold the lock.
+func (s *Service) levelVisible(profile *StudentProfile, level LevelDefinition) bool {
+	chapter, _ := s.findChapter(level.ChapterID)
+	return chapter != nil && chapterVisible(*chapter, profile.ClassID)
+}
+
+// PublishedLevel returns the live definition of a level.
+func (s *Service) PublishedLevel(ctx context.Context, levelID string) (LevelDefinition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	level, ok := s.levels[levelID]
+	if !ok {
+		return LevelDefinition{}, ErrLevelNotFound
+	}
+	return level, nil
+}
+
+// Chapter returns a chapter with its levels.
+func (s *Service) Chapter(ctx context.Context, chapterID string) (ChapterDefinition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chapter, _ := s.findChapter(chapterID)
+	if chapter == nil {
+		return ChapterDefinition{}, ErrChapterNotFound
+	}
+	clone := *chapter
+	clone.Levels = append([]LevelDefinition(nil), chapter.Levels...)
+	clone.ClassIDs = append([]string(nil), chapter.ClassIDs...)
+	clone.Translations = cloneTranslations(chapter.Translations)
+	return clone, nil
+}
@@ -0,0 +1,561 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Errors returned by the badge operations.
+var (
+	ErrBadgeNotFound = apperr.New(apperr.KindNotFound, "badge.not_found")
+	ErrBadgeInvalid  = apperr.New(apperr.KindUnprocessable, "badge.invalid")
+)
+
+// BadgeRuleType selects what a badge rule measures.
+type BadgeRuleType string
+
+const (
+	// BadgeRuleLevelsCompleted counts completed levels.
+	BadgeRuleLevelsCompleted BadgeRuleType = "levels_completed"
+	// BadgeRulePerfectLevels counts levels completed with three stars.
+	BadgeRulePerfectLevels BadgeRuleType = "perfect_levels"
+	// BadgeRuleStars sums the best stars of all completed levels.
+	BadgeRuleStars BadgeRuleType = "stars"
+	// BadgeRuleChapterCompleted counts chapters whose every level is
+	// completed, or checks a single chapter when ChapterID is set.
+	BadgeRuleChapterCompleted BadgeRuleType = "chapter_completed"
+	// BadgeRuleNoHintLevels counts levels completed without using a hint.
+	BadgeRuleNoHintLevels BadgeRuleType = "no_hint_levels"
+	// BadgeRuleCompletionStreak measures the run of most recent completions
+	// that meet MinStars and, with NoHints, used no hint.
+	BadgeRuleCompletionStreak BadgeRuleType = "completion_streak"
+	// BadgeRuleBlockUsage counts completed levels whose latest successful
+	// program uses Block, e.g. REPEAT.
+	BadgeRuleBlockUsage BadgeRuleType = "block_usage"
+	// BadgeRuleDailyStreak measures the longest run of active days.
+	BadgeRuleDailyStreak BadgeRuleType = "daily_streak"
+	// BadgeRuleAll holds when every rule in Rules holds.
+	BadgeRuleAll BadgeRuleType = "all"
+)
+
+// BadgeRule is the condition under which a badge is earned. Counting rules
+// hold once their count reaches Threshold, which defaults to 1.
+type BadgeRule struct {
+	Type      BadgeRuleType `json:"type"`
+	Threshold int           `json:"threshold,omitempty"`
+	// ChapterID limits counting rules to the levels of one chapter.
+	ChapterID string      `json:"chapterId,omitempty"`
+	MinStars  int         `json:"minStars,omitempty"`
+	NoHints   bool        `json:"noHints,omitempty"`
+	Block     string      `json:"block,omitempty"`
+	Rules     []BadgeRule `json:"rules,omitempty"`
+}
+
+// BadgeText is the translatable text of a badge in one locale.
+type BadgeText struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// BadgeDefinition is a badge students can earn. Built-in badges have no
+// owner; badges created by teachers record the teacher as OwnerID.
+type BadgeDefinition struct {
+	ID           string               `json:"id"`
+	Name         string               `json:"name"`
+	Description  string               `json:"description,omitempty"`
+	Icon         string               `json:"icon,omitempty"`
+	Rule         BadgeRule            `json:"rule"`
+	OwnerID      string               `json:"ownerId,omitempty"`
+	Translations map[string]BadgeText `json:"translations,omitempty"`
+}
+
+// EarnedBadge is a badge a student holds, with text in the student's
+// language.
+type EarnedBadge struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	EarnedAt    int64  `json:"earnedAt"`
+}
+
+// CompleteResult reports what a level completion earned.
+type CompleteResult struct {
+	NewBadges     []EarnedBadge     `json:"newBadges"`
+	NewCompendium []CompendiumEntry `json:"newCompendium"`
+}
+
+// completionEvent is one call to Complete, kept for streak rules.
+type completionEvent struct {
+	levelID string
+	stars   int
+	hints   int
+	at      time.Time
+}
+
+// maxCompletionEvents bounds the completion history kept per student.
+const maxCompletionEvents = 200
+
+// maxRuleDepth bounds the nesting of "all" rules.
+const maxRuleDepth = 3
+
+// Badges lists all badge definitions ordered by ID.
+func (s *Service) Badges(ctx context.Context) []BadgeDefinition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	badges := make([]BadgeDefinition, 0, len(s.badges))
+	for _, badge := range s.badges {
+		badges = append(badges, badge)
+	}
+	sort.Slice(badges, func(i, j int) bool { return badges[i].ID < badges[j].ID })
+	return badges
+}
+
+// Badge returns a single badge definition.
+func (s *Service) Badge(ctx context.Context, badgeID string) (BadgeDefinition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	badge, ok := s.badges[badgeID]
+	if !ok {
+		return BadgeDefinition{}, ErrBadgeNotFound
+	}
+	return badge, nil
+}
+
+// SaveBadge validates a badge definition and creates or replaces it. Badges
+// are evaluated from the next completion on; badges already earned are kept.
+func (s *Service) SaveBadge(ctx context.Context, badge BadgeDefinition) (BadgeDefinition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var problems []apperr.Problem
+	if strings.TrimSpace(badge.ID) == "" {
+		problems = append(problems, apperr.NewProblem("id", "required"))
+	}
+	if strings.TrimSpace(badge.Name) == "" {
+		problems = append(problems, apperr.NewProblem("name", "required"))
+	}
+	for _, locale := range unsupportedLocales(badge.Translations) {
+		problems = append(problems, apperr.NewProblem("translations", "unsupported_locale", "locale", locale))
+	}
+	problems = append(problems, s.checkBadgeRule(badge.Rule, "rule", 1)...)
+	if len(problems) > 0 {
+		return BadgeDefinition{}, ErrBadgeInvalid.With("problems", problems)
+	}
+
+	s.badges[badge.ID] = badge
+	return badge, nil
+}
+
+// DeleteBadge removes a badge definition. Students who earned it keep it.
+func (s *Service) DeleteBadge(ctx context.Context, badgeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.badges[badgeID]; !ok {
+		return ErrBadgeNotFound
+	}
+	delete(s.badges, badgeID)
+	return nil
+}
+
+// checkBadgeRule lists the problems of a rule. Callers must hold the lock.
+func (s *Service) checkBadgeRule(rule BadgeRule, path string, depth int) []apperr.Problem {
+	var problems []apperr.Problem
+	fail := func(field, code string, params ...any) {
+		problems = append(problems, apperr.NewProblem(path+"."+field, code, params...))
+	}
+	if rule.Threshold < 0 {
+		fail("threshold", "negative")
+	}
+	if rule.ChapterID != "" {
+		if chapter, _ := s.findChapter(rule.ChapterID); chapter == nil {
+			fail("chapterId", "not_found", "id", rule.ChapterID)
+		}
+	}
+
+	switch rule.Type {
+	case BadgeRuleLevelsCompleted, BadgeRulePerfectLevels, BadgeRuleStars, BadgeRuleChapterCompleted, BadgeRuleNoHintLevels, BadgeRuleDailyStreak:
+	case BadgeRuleCompletionStreak:
+		if rule.MinStars < 0 || rule.MinStars > 3 {
+			fail("minStars", "out_of_range", "min", 0, "max", 3)
+		}
+	case BadgeRuleBlockUsage:
+		if !contains(knownBlocks, rule.Block) {
+			fail("block", "unknown_block", "block", rule.Block)
+		}
+	case BadgeRuleAll:
+		if len(rule.Rules) == 0 {
+			fail("rules", "required")
+		}
+		if depth >= maxRuleDepth {
+			fail("rules", "too_deep", "max", maxRuleDepth)
+			break
+		}
+		for i, child := range rule.Rules {
+			problems = append(problems, s.checkBadgeRule(child, fmt.Sprintf("%s.rules[%d]", path, i), depth+1)...)
+		}
+	default:
+		fail("type", "unknown", "value", string(rule.Type))
+	}
+	return problems
+}
+
+// badgeFacts is what badge rules are evaluated against: the student's
+// completed levels among those visible to them, and their recent
+// completions.
+type badgeFacts struct {
+	s        *Service
+	profile  *StudentProfile
+	chapters []ChapterDefinition
+	events   []completionEvent
+	activity *activityState
+}
+
+// completed returns the progress of completed visible levels, optionally
+// limited to one chapter.
+func (f badgeFacts) completed(chapterID string) map[string]StudentLevelProgress {
+	levels := make(map[string]StudentLevelProgress)
+	for _, chapter := range f.chapters {
+		if chapterID != "" && chapter.ID != chapterID {
+			continue
+		}
+		for _, level := range chapter.Levels {
+			if progress, ok := f.profile.Progress[level.ID]; ok && progress.Stars > 0 {
+				levels[level.ID] = progress
+			}
+		}
+	}
+	return levels
+}
+
+func (f badgeFacts) measure(rule BadgeRule) int {
+	switch rule.Type {
+	case BadgeRuleLevelsCompleted:
+		return len(f.completed(rule.ChapterID))
+	case BadgeRulePerfectLevels:
+		return countProgress(f.completed(rule.ChapterID), func(p StudentLevelProgress) bool { return p.Stars >= 3 })
+	case BadgeRuleStars:
+		total := 0
+		for _, progress := range f.completed(rule.ChapterID) {
+			total += progress.Stars
+		}
+		return total
+	case BadgeRuleNoHintLevels:
+		return countProgress(f.completed(rule.ChapterID), func(p StudentLevelProgress) bool { return p.Hints == 0 })
+	case BadgeRuleChapterCompleted:
+		chapters := 0
+		for _, chapter := range f.chapters {
+			if rule.ChapterID != "" && chapter.ID != rule.ChapterID {
+				continue
+			}
+			if len(chapter.Levels) > 0 && len(f.completed(chapter.ID)) == len(chapter.Levels) {
+				chapters++
+			}
+		}
+		return chapters
+	case BadgeRuleCompletionStreak:
+		minStars := rule.MinStars
+		if minStars < 1 {
+			minStars = 1
+		}
+		streak := 0
+		for i := len(f.events) - 1; i >= 0; i-- {
+			event := f.events[i]
+			if event.stars < minStars || (rule.NoHints && event.hints > 0) {
+				break
+			}
+			if rule.ChapterID == "" || f.s.levels[event.levelID].ChapterID == rule.ChapterID {
+				streak++
+			}
+		}
+		return streak
+	case BadgeRuleDailyStreak:
+		if f.activity == nil {
+			return 0
+		}
+		return f.activity.longest
+	case BadgeRuleBlockUsage:
+		count := 0
+		for levelID := range f.completed(rule.ChapterID) {
+			if program := f.s.lastSuccessfulProgram(f.profile.ID, levelID); programUsesBlock(program, rule.Block) {
+				count++
+			}
+		}
+		return count
+	}
+	return 0
+}
+
+func (f badgeFacts) holds(rule BadgeRule) bool {
+	if rule.Type == BadgeRuleAll {
+		for _, child := range rule.Rules {
+			if !f.holds(child) {
+				return false
+			}
+		}
+		return len(rule.Rules) > 0
+	}
+	threshold := rule.Threshold
+	if threshold < 1 {
+		threshold = 1
+	}
+	return f.measure(rule) >= threshold
+}
+
+func countProgress(levels map[string]StudentLevelProgress, match func(StudentLevelProgress) bool) int {
+	count := 0
+	for _, progress := range levels {
+		if match(progress) {
+			count++
+		}
+	}
+	return count
+}
+
+// lastSuccessfulProgram returns the program of the student's most recent
+// successful run of a level. Callers must hold the lock.
+func (s *Service) lastSuccessfulProgram(studentID, levelID string) []Instruction {
+	attempts := s.attempts[studentID][levelID]
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if attempts[i].Success {
+			return attempts[i].Program
+		}
+	}
+	return nil
+}
+
+func programUsesBlock(program []Instruction, block string) bool {
+	for _, instr := range program {
+		if blockCodeForInstruction(instr) == block ||
+			programUsesBlock(instr.Body, block) ||
+			programUsesBlock(instr.Truthy, block) ||
+			programUsesBlock(instr.Falsy, block) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordCompletion appends to the completion history used by streak rules.
+// Callers must hold the write lock.
+func (s *Service) recordCompletion(studentID string, event completionEvent) {
+	events := append(s.completions[studentID], event)
+	if len(events) > maxCompletionEvents {
+		events = events[len(events)-maxCompletionEvents:]
+	}
+	s.completions[studentID] = events
+}
+
+// awardAchievements evaluates every badge the student does not hold yet and
+// collects the compendium entries of completed chapters. Callers must hold
+// the write lock.
+func (s *Service) awardAchievements(profile *StudentProfile, at time.Time) CompleteResult {
+	facts := badgeFacts{s: s, profile: profile, chapters: s.visibleChapters(profile), events: s.completions[profile.ID], activity: s.activity[profile.ID]}
+	result := CompleteResult{NewBadges: []EarnedBadge{}, NewCompendium: []CompendiumEntry{}}
+
+	earned := s.earnedBadges[profile.ID]
+	if earned == nil {
+		earned = make(map[string]int64)
+		s.earnedBadges[profile.ID] = earned
+	}
+	ids := make([]string, 0, len(s.badges))
+	for id := range s.badges {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if _, ok := earned[id]; ok || !facts.holds(s.badges[id].Rule) {
+			continue
+		}
+		earned[id] = at.UnixMilli()
+		s.postLedger(profile.ID, LedgerEntry{Key: "badge:" + id, Kind: LedgerEarn, Currency: CurrencyCoins, Amount: coinsPerBadge, Reason: id}, at)
+		result.NewBadges = append(result.NewBadges, s.earnedBadge(id, earned[id], profile.Settings.Language))
+	}
+
+	completedChapters := make(map[string]bool, len(facts.chapters))
+	allCompleted := true
+	for _, chapter := range facts.chapters {
+		done := len(chapter.Levels) > 0 && len(facts.completed(chapter.ID)) == len(chapter.Levels)
+		completedChapters[chapter.ID] = done
+		allCompleted = allCompleted && done
+	}
+	for _, entry := range s.sortedCompendium() {
+		if contains(profile.Achievements.Compendium, entry.ID) {
+			continue
+		}
+		if (entry.ChapterID == "" && allCompleted && len(facts.chapters) > 0) || completedChapters[entry.ChapterID] {
+			profile.Achievements.Compendium = append(profile.Achievements.Compendium, entry.ID)
+			result.NewCompendium = append(result.NewCompendium, entry)
+		}
+	}
+
+	profile.Achievements.Badges = s.earnedBadgeIDs(profile.ID)
+	return result
+}
+
+// visibleChapters returns the chapters the student can see. Callers must
+// hold the lock.
+func (s *Service) visibleChapters(profile *StudentProfile) []ChapterDefinition {
+	chapters := make([]ChapterDefinition, 0, len(s.chapters))
+	for _, chapter := range s.chapters {
+		if chapterVisible(chapter, profile.ClassID) {
+			chapters = append(chapters, chapter)
+		}
+	}
+	return chapters
+}
+
+func (s *Service) sortedCompendium() []CompendiumEntry {
+	entries := make([]CompendiumEntry, 0, len(s.compendium))
+	for _, entry := range s.compendium {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// earnedBadgeIDs lists the badges a student holds in the order earned.
+// Callers must hold the lock.
+func (s *Service) earnedBadgeIDs(studentID string) []string {
+	earned := s.earnedBadges[studentID]
+	ids := make([]string, 0, len(earned))
+	for id := range earned {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if earned[ids[i]] != earned[ids[j]] {
+			return earned[ids[i]] < earned[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// earnedBadge describes a held badge in the locale. Badges whose definition
+// was deleted keep their ID as name. Callers must hold the lock.
+func (s *Service) earnedBadge(badgeID string, earnedAt int64, locale string) EarnedBadge {
+	badge, ok := s.badges[badgeID]
+	if !ok {
+		return EarnedBadge{ID: badgeID, Name: badgeID, EarnedAt: earnedAt}
+	}
+	name, description := badge.Name, badge.Description
+	if text, ok := lookupTranslation(badge.Translations, locale); ok && !isDefaultLocale(locale) {
+		if text.Name != "" {
+			name = text.Name
+		}
+		if text.Description != "" {
+			description = text.Description
+		}
+	}
+	return EarnedBadge{ID: badge.ID, Name: name, Description: description, Icon: badge.Icon, EarnedAt: earnedAt}
+}
+
+// EarnedBadges lists the badges a student holds in the order earned.
+func (s *Service) EarnedBadges(ctx context.Context, userID string) []EarnedBadge {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := s.earnedBadgeIDs(profile.ID)
+	badges := make([]EarnedBadge, 0, len(ids))
+	for _, id := range ids {
+		badges = append(badges, s.earnedBadge(id, s.earnedBadges[profile.ID][id], profile.Settings.Language))
+	}
+	return badges
+}
+
+// BadgeStatus is a badge as shown in a student's badge list, earned or not.
+type BadgeStatus struct {
+	EarnedBadge
+	Earned bool `json:"earned"`
+}
+
+// BadgeStatuses lists every badge with whether the student earned it. Earned
+// badges come first in the order earned.
+func (s *Service) BadgeStatuses(ctx context.Context, userID string) []BadgeStatus {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	earned := s.earnedBadges[profile.ID]
+	statuses := make([]BadgeStatus, 0, len(s.badges)+len(earned))
+	for _, id := range s.earnedBadgeIDs(profile.ID) {
+		statuses = append(statuses, BadgeStatus{EarnedBadge: s.earnedBadge(id, earned[id], profile.Settings.Language), Earned: true})
+	}
+	ids := make([]string, 0, len(s.badges))
+	for id := range s.badges {
+		if _, ok := earned[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		statuses = append(statuses, BadgeStatus{EarnedBadge: s.earnedBadge(id, 0, profile.Settings.Language)})
+	}
+	return statuses
+}
+
+func defaultBadges() map[string]BadgeDefinition {
+	badges := []BadgeDefinition{
+		{
+			ID: "first-clear", Name: "初次通关", Description: "完成第一个关卡",
+			Rule:         BadgeRule{Type: BadgeRuleLevelsCompleted, Threshold: 1},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "First Clear", Description: "Complete your first level"}},
+		},
+		{
+			ID: "adventurer", Name: "冒险旅人", Description: "完成 5 个关卡",
+			Rule:         BadgeRule{Type: BadgeRuleLevelsCompleted, Threshold: 5},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "Seasoned Adventurer", Description: "Complete 5 levels"}},
+		},
+		{
+			ID: "perfectionist", Name: "完美主义者", Description: "3 个关卡获得三星",
+			Rule:         BadgeRule{Type: BadgeRulePerfectLevels, Threshold: 3},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "Perfectionist", Description: "Earn three stars on 3 levels"}},
+		},
+		{
+			ID: "chapter-master", Name: "章节掌控者", Description: "完成一个章节的全部关卡",
+			Rule:         BadgeRule{Type: BadgeRuleChapterCompleted, Threshold: 1},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "Chapter Master", Description: "Complete every level of a chapter"}},
+		},
+		{
+			ID: "star-collector", Name: "星星收藏家", Description: "累计获得 30 颗星",
+			Rule:         BadgeRule{Type: BadgeRuleStars, Threshold: 30},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "Star Collector", Description: "Collect 30 stars"}},
+		},
+		{
+			ID: "steady-learner", Name: "坚持不懈", Description: "连续 3 天练习",
+			Rule:         BadgeRule{Type: BadgeRuleDailyStreak, Threshold: 3},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "Steady Learner", Description: "Practice 3 days in a row"}},
+		},
+		{
+			ID: "week-streak", Name: "一周不间断", Description: "连续 7 天练习",
+			Rule:         BadgeRule{Type: BadgeRuleDailyStreak, Threshold: 7},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "Week Streak", Description: "Practice 7 days in a row"}},
+		},
+		{
+			ID: "independent-thinker", Name: "独立思考者", Description: "连续 3 次不用提示完成关卡",
+			Rule:         BadgeRule{Type: BadgeRuleCompletionStreak, Threshold: 3, NoHints: true},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "Independent Thinker", Description: "Complete 3 levels in a row without hints"}},
+		},
+		{
+			ID: "loop-explorer", Name: "循环探索家", Description: "用重复积木完成 2 个关卡",
+			Rule:         BadgeRule{Type: BadgeRuleBlockUsage, Threshold: 2, Block: "REPEAT"},
+			Translations: map[string]BadgeText{apperr.LanguageEnglish: {Name: "Loop Explorer", Description: "Complete 2 levels using a repeat block"}},
+		},
+	}
+	index := make(map[string]BadgeDefinition, len(badges))
+	for _, badge := range badges {
+		index[badge.ID] = badge
+	}
+	return index
+}
@@ -0,0 +1,87 @@
+package student
+
+import (
+	"context"
+	"sort"
+)
+
+// CompendiumEntry is a collectible encyclopedia card attached to a chapter.
+type CompendiumEntry struct {
+	ID          string `json:"id"`
+	ChapterID   string `json:"chapterId,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+}
+
+// CompendiumEntries lists the entries attached to the given chapters.
+func (s *Service) CompendiumEntries(ctx context.Context, chapterIDs []string) []CompendiumEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]CompendiumEntry, 0)
+	for _, entry := range s.compendium {
+		if contains(chapterIDs, entry.ChapterID) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// SaveCompendiumEntry creates or replaces a compendium entry.
+func (s *Service) SaveCompendiumEntry(ctx context.Context, entry CompendiumEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.compendium[entry.ID] = entry
+}
+
+// CompendiumEntry returns a single entry.
+func (s *Service) CompendiumEntry(ctx context.Context, entryID string) (CompendiumEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.compendium[entryID]
+	return entry, ok
+}
+
+// CompendiumCard is a compendium entry as shown to a student.
+type CompendiumCard struct {
+	CompendiumEntry
+	Collected bool `json:"collected"`
+}
+
+// StudentCompendium lists the entries of the chapters visible to a student.
+// An entry is collected once every level of its chapter is completed.
+func (s *Service) StudentCompendium(ctx context.Context, userID string) []CompendiumCard {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	visible := make(map[string]bool)
+	for _, chapter := range s.visibleChapters(profile) {
+		visible[chapter.ID] = true
+	}
+	cards := make([]CompendiumCard, 0, len(s.compendium))
+	for _, entry := range s.sortedCompendium() {
+		if entry.ChapterID != "" && !visible[entry.ChapterID] {
+			continue
+		}
+		cards = append(cards, CompendiumCard{CompendiumEntry: entry, Collected: contains(profile.Achievements.Compendium, entry.ID)})
+	}
+	return cards
+}
+
+func defaultCompendium() map[string]CompendiumEntry {
+	entries := []CompendiumEntry{
+		{ID: "entry-compass-robot", ChapterID: "chapter-1", Name: "罗盘机器人", Description: "只会按顺序执行指令的小机器人，是每位冒险家的第一个伙伴。"},
+		{ID: "entry-loop-turtle", ChapterID: "chapter-2", Name: "循环海龟", Description: "住在循环岛屿的海龟，总是把同样的动作重复很多次。"},
+	}
+	index := make(map[string]CompendiumEntry, len(entries))
+	for _, entry := range entries {
+		index[entry.ID] = entry
+	}
+	return index
+}
@@ -0,0 +1,132 @@
+package student
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// maxBannerLength caps the message banner of a class, in characters.
+const maxBannerLength = 200
+
+// Errors returned when classroom controls refuse a request.
+var (
+	ErrClassFrozen     = apperr.New(apperr.KindConflict, "class.frozen")
+	ErrLevelRestricted = apperr.New(apperr.KindForbidden, "class.level_restricted")
+	ErrSandboxDisabled = apperr.New(apperr.KindForbidden, "class.sandbox_disabled")
+	ErrInvalidControls = apperr.New(apperr.KindUnprocessable, "controls.invalid")
+)
+
+// ClassControls are the live controls a teacher applies to a class. Frozen
+// stops all runs, ChapterID and LevelID restrict students to one chapter or
+// level, SandboxDisabled turns off sandbox runs and Banner is shown to every
+// student of the class. The zero value applies no control.
+type ClassControls struct {
+	Frozen          bool   `json:"frozen"`
+	ChapterID       string `json:"chapterId,omitempty"`
+	LevelID         string `json:"levelId,omitempty"`
+	SandboxDisabled bool   `json:"sandboxDisabled"`
+	Banner          string `json:"banner,omitempty"`
+	UpdatedAt       int64  `json:"updatedAt,omitempty"`
+}
+
+// ControlsObserver receives the controls of a class whenever they change. It
+// is called while the service holds its lock, so it must return quickly and
+// not call back into the service.
+type ControlsObserver func(classID string, controls ClassControls)
+
+// ObserveControls registers an observer for changes to classroom controls.
+func (s *Service) ObserveControls(ctx context.Context, observer ControlsObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onControls = append(s.onControls, observer)
+}
+
+// ClassControls returns the controls in effect for a class.
+func (s *Service) ClassControls(ctx context.Context, classID string) ClassControls {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.controls[classID]
+}
+
+// StudentControls returns the class of a student and the controls in effect
+// for it. Students without a class get no class and no controls.
+func (s *Service) StudentControls(ctx context.Context, studentID string) (string, ClassControls) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	classID := s.enrollments[studentID]
+	if profile, ok := s.profiles[studentID]; ok {
+		classID = profile.ClassID
+	}
+	return classID, s.controls[classID]
+}
+
+// SetClassControls replaces the controls of a class and notifies the
+// observers. The chapter and level must be visible to the class, and the
+// level must belong to the chapter when both are set.
+func (s *Service) SetClassControls(ctx context.Context, classID string, controls ClassControls) (ClassControls, error) {
+	controls.Banner = strings.TrimSpace(controls.Banner)
+	if utf8.RuneCountInString(controls.Banner) > maxBannerLength {
+		return ClassControls{}, ErrInvalidControls.With("field", "banner").With("max", maxBannerLength)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if controls.ChapterID != "" {
+		chapter, _ := s.findChapter(controls.ChapterID)
+		if chapter == nil || !chapterVisible(*chapter, classID) {
+			return ClassControls{}, ErrInvalidControls.With("field", "chapterId")
+		}
+	}
+	if controls.LevelID != "" {
+		level, ok := s.levels[controls.LevelID]
+		if !ok {
+			return ClassControls{}, ErrInvalidControls.With("field", "levelId")
+		}
+		chapter, _ := s.findChapter(level.ChapterID)
+		if chapter == nil || !chapterVisible(*chapter, classID) {
+			return ClassControls{}, ErrInvalidControls.With("field", "levelId")
+		}
+		if controls.ChapterID != "" && level.ChapterID != controls.ChapterID {
+			return ClassControls{}, ErrInvalidControls.With("field", "levelId")
+		}
+	}
+
+	controls.UpdatedAt = time.Now().UnixMilli()
+	s.controls[classID] = controls
+	for _, observer := range s.onControls {
+		observer(classID, controls)
+	}
+	return controls, nil
+}
+
+// checkLevelRestriction refuses levels outside the chapter or level the
+// class of a student is restricted to. Callers must hold the lock.
+func (s *Service) checkLevelRestriction(profile *StudentProfile, level LevelDefinition) error {
+	controls := s.controls[profile.ClassID]
+	if controls.LevelID != "" && level.ID != controls.LevelID {
+		return ErrLevelRestricted.With("levelId", controls.LevelID)
+	}
+	if controls.ChapterID != "" && level.ChapterID != controls.ChapterID {
+		return ErrLevelRestricted.With("chapterId", controls.ChapterID)
+	}
+	return nil
+}
+
+// checkRunAllowed refuses runs while the class of a student is frozen and,
+// for sandbox runs, while the sandbox is disabled. Callers must hold the lock.
+func (s *Service) checkRunAllowed(profile *StudentProfile, sandbox bool) error {
+	controls := s.controls[profile.ClassID]
+	if controls.Frozen {
+		return ErrClassFrozen.With("classId", profile.ClassID)
+	}
+	if sandbox && controls.SandboxDisabled {
+		return ErrSandboxDisabled.With("classId", profile.ClassID)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package student
+
+import (
+	"context"
+	"time"
+)
+
+// ActivityType names what a student did.
+type ActivityType string
+
+const (
+	ActivityRun      ActivityType = "run"
+	ActivityHint     ActivityType = "hint"
+	ActivityComplete ActivityType = "complete"
+)
+
+// ActivityEvent reports a run, hint or completion of a student as it
+// happens. Attempts and Hints are the student's totals on the level so far.
+type ActivityEvent struct {
+	Type      ActivityType `json:"type"`
+	StudentID string       `json:"studentId"`
+	ClassID   string       `json:"classId"`
+	LevelID   string       `json:"levelId"`
+	Success   bool         `json:"success"`
+	Stars     int          `json:"stars"`
+	ErrorCode string       `json:"errorCode,omitempty"`
+	Attempts  int          `json:"attempts"`
+	Hints     int          `json:"hints"`
+	At        int64        `json:"at"`
+}
+
+// ActivityObserver receives activity events. It is called while the service
+// holds its lock, so it must return quickly and not call back into the
+// service.
+type ActivityObserver func(ActivityEvent)
+
+// ObserveActivity registers an observer for the runs, hints and completions
+// of all students.
+func (s *Service) ObserveActivity(ctx context.Context, observer ActivityObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, observer)
+}
+
+// emitActivity fills in the class and level totals of an event and hands it
+// to the observers. Callers must hold the write lock.
+func (s *Service) emitActivity(profile *StudentProfile, event ActivityEvent, at time.Time) {
+	if len(s.observers) == 0 {
+		return
+	}
+	event.StudentID = profile.ID
+	event.ClassID = profile.ClassID
+	event.Attempts = len(s.attempts[profile.ID][event.LevelID])
+	if state, ok := s.hints[profile.ID][event.LevelID]; ok {
+		event.Hints = state.used
+	}
+	event.At = at.UnixMilli()
+	for _, observer := range s.observers {
+		observer(event)
+	}
+}
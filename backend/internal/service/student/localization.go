@@ -0,0 +1,250 @@
+package student
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// DefaultLocale is the language the base text fields of levels and chapters
+// are written in. Translations into other locales are optional; any field a
+// translation leaves empty falls back to the base text.
+const DefaultLocale = apperr.DefaultLanguage
+
+// ErrUnsupportedLanguage is returned for a language or translation locale that
+// neither content nor messages are available in.
+var ErrUnsupportedLanguage = apperr.New(apperr.KindInvalid, "language.unsupported")
+
+// LevelText is the translatable text of a level in one locale.
+type LevelText struct {
+	Name  string   `json:"name,omitempty"`
+	Hints []string `json:"hints,omitempty"`
+	Comic string   `json:"comic,omitempty"`
+}
+
+// ChapterText is the translatable text of a chapter in one locale.
+type ChapterText struct {
+	Title   string `json:"title,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// localeChain lists the translation keys tried for a locale, most specific
+// first: "en-US" tries "en-US", then "en".
+func localeChain(locale string) []string {
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		return nil
+	}
+	chain := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok && base != "" {
+		chain = append(chain, base)
+	}
+	return chain
+}
+
+// isDefaultLocale reports whether text in the locale is the base text.
+func isDefaultLocale(locale string) bool {
+	return locale == "" || apperr.MatchLanguage(locale) == apperr.MatchLanguage(DefaultLocale)
+}
+
+// IsTranslationLocale reports whether content may be translated into the
+// locale: it must be supported and differ from the base language.
+func IsTranslationLocale(locale string) bool {
+	return apperr.MatchLanguage(locale) != "" && !isDefaultLocale(locale)
+}
+
+// unsupportedLocales lists the translation keys that are not translation
+// locales, sorted.
+func unsupportedLocales[T any](translations map[string]T) []string {
+	var locales []string
+	for locale := range translations {
+		if !IsTranslationLocale(locale) {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func cloneTranslations[T any](translations map[string]T) map[string]T {
+	if translations == nil {
+		return nil
+	}
+	clone := make(map[string]T, len(translations))
+	for locale, text := range translations {
+		clone[locale] = text
+	}
+	return clone
+}
+
+func lookupTranslation[T any](translations map[string]T, locale string) (T, bool) {
+	for _, key := range localeChain(locale) {
+		if text, ok := translations[key]; ok {
+			return text, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// localizeLevel returns the level with its text replaced by the translation
+// for the locale. The translations themselves are dropped since students only
+// ever see one language.
+func localizeLevel(level LevelDefinition, locale string) LevelDefinition {
+	text, ok := lookupTranslation(level.Translations, locale)
+	level.Translations = nil
+	if !ok || isDefaultLocale(locale) {
+		return level
+	}
+	if text.Name != "" {
+		level.Name = text.Name
+	}
+	if len(text.Hints) > 0 {
+		level.Hints = text.Hints
+	}
+	if text.Comic != "" {
+		level.Comic = text.Comic
+	}
+	return level
+}
+
+// localizeChapter returns the chapter title and summary for the locale.
+func localizeChapter(chapter ChapterDefinition, locale string) (title, summary string) {
+	title, summary = chapter.Title, chapter.Summary
+	text, ok := lookupTranslation(chapter.Translations, locale)
+	if !ok || isDefaultLocale(locale) {
+		return title, summary
+	}
+	if text.Title != "" {
+		title = text.Title
+	}
+	if text.Summary != "" {
+		summary = text.Summary
+	}
+	return title, summary
+}
+
+// builtInText holds the text the service itself produces, such as generic
+// hints and misconception descriptions, per supported language.
+var builtInText = map[string]map[string]string{
+	apperr.LanguageChinese: {
+		"hint.try_running":    "尝试运行你的方案，看看会发生什么！",
+		"hint.E_COLLIDE":      "前方可能有障碍，试着调整转向积木。",
+		"hint.E_STEP_LIMIT":   "步数有点多，考虑使用重复积木。",
+		"hint.E_GOAL_NOT_MET": "别忘了达成所有目标，再检查一下程序。",
+		"hint.E_LOOP_DEPTH":   "循环层级太深了，简化一下结构吧。",
+		"hint.rethink":        "检查一下积木的顺序，也许要换个思路。",
+
+		"hint.misconception.repeat_off_by_one":    "数一数需要走几次：重复积木的次数是不是多了一次或少了一次？",
+		"hint.misconception.left_right_confusion": "想象自己站在机器人的位置上，它要向左转还是向右转？",
+		"hint.misconception.collect_wrong_tile":   "只有站在宝物上时收集才有用，先走到宝物那一格再收集。",
+		"hint.misconception.over_nesting":         "重复积木套得太多了，试试只用一层重复。",
+		"misconception.repeat_off_by_one":         "重复次数多一次或少一次",
+		"misconception.left_right_confusion":      "左转与右转混淆",
+		"misconception.collect_wrong_tile":        "在没有宝物的格子上收集",
+		"misconception.over_nesting":              "积木嵌套层级过多",
+	},
+	apperr.LanguageEnglish: {
+		"hint.try_running":    "Try running your plan and see what happens!",
+		"hint.E_COLLIDE":      "Something may be in the way. Try adjusting your turn blocks.",
+		"hint.E_STEP_LIMIT":   "That takes a lot of steps. Consider using a repeat block.",
+		"hint.E_GOAL_NOT_MET": "Remember to reach every goal, then check your program again.",
+		"hint.E_LOOP_DEPTH":   "The loops are nested too deeply. Try a simpler structure.",
+		"hint.rethink":        "Check the order of your blocks. Maybe try a different approach.",
+
+		"hint.misconception.repeat_off_by_one":    "Count how many times you need to go: does the repeat block run once too often or once too few?",
+		"hint.misconception.left_right_confusion": "Imagine standing where the robot stands. Does it need to turn left or right?",
+		"hint.misconception.collect_wrong_tile":   "Collecting only works on a treasure. Walk onto the treasure tile first.",
+		"hint.misconception.over_nesting":         "There are too many repeats inside each other. Try a single repeat.",
+		"misconception.repeat_off_by_one":         "Repeat count off by one",
+		"misconception.left_right_confusion":      "Mixing up left and right turns",
+		"misconception.collect_wrong_tile":        "Collecting on a tile without treasure",
+		"misconception.over_nesting":              "Nesting blocks too deeply",
+	},
+}
+
+// builtInString returns a built-in string in the language matching the locale.
+func builtInString(locale, key string) string {
+	language := apperr.MatchLanguage(locale)
+	if value, ok := builtInText[language][key]; ok {
+		return value
+	}
+	if value, ok := builtInText[DefaultLocale][key]; ok {
+		return value
+	}
+	return key
+}
+
+// TranslationGap is one text field without a translation.
+type TranslationGap struct {
+	Kind  string `json:"kind"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Field string `json:"field"`
+}
+
+// TranslationReport summarises how much of a set of chapters is translated
+// into one locale. Fields that are empty in the base text are not counted.
+type TranslationReport struct {
+	Locale     string           `json:"locale"`
+	Total      int              `json:"total"`
+	Translated int              `json:"translated"`
+	Complete   bool             `json:"complete"`
+	Missing    []TranslationGap `json:"missing"`
+}
+
+// TranslationLocales lists the locales content can be translated into.
+func TranslationLocales() []string {
+	locales := make([]string, 0, len(builtInText))
+	for language := range builtInText {
+		if !isDefaultLocale(language) {
+			locales = append(locales, language)
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Translations reports the translation completeness of the given chapters
+// and live levels for one locale. Unknown IDs are skipped.
+func (s *Service) Translations(ctx context.Context, chapterIDs, levelIDs []string, locale string) TranslationReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report := TranslationReport{Locale: locale, Missing: []TranslationGap{}}
+	check := func(kind, id, name, field string, needed, translated bool) {
+		if !needed {
+			return
+		}
+		report.Total++
+		if translated {
+			report.Translated++
+			return
+		}
+		report.Missing = append(report.Missing, TranslationGap{Kind: kind, ID: id, Name: name, Field: field})
+	}
+
+	for _, chapterID := range chapterIDs {
+		chapter, _ := s.findChapter(chapterID)
+		if chapter == nil {
+			continue
+		}
+		text, _ := lookupTranslation(chapter.Translations, locale)
+		check("chapter", chapter.ID, chapter.Title, "title", chapter.Title != "", text.Title != "")
+		check("chapter", chapter.ID, chapter.Title, "summary", chapter.Summary != "", text.Summary != "")
+	}
+	for _, levelID := range levelIDs {
+		level, ok := s.levels[levelID]
+		if !ok {
+			continue
+		}
+		text, _ := lookupTranslation(level.Translations, locale)
+		check("level", level.ID, level.Name, "name", level.Name != "", text.Name != "")
+		check("level", level.ID, level.Name, "comic", level.Comic != "", text.Comic != "")
+		check("level", level.ID, level.Name, "hints", len(level.Hints) > 0, len(text.Hints) == len(level.Hints))
+	}
+	report.Complete = len(report.Missing) == 0
+	return report
+}
@@ -0,0 +1,356 @@
+package student
+
+import (
+	"context"
+	"sort"
+)
+
+// Misconception is a recurring misunderstanding behind failed runs.
+type Misconception string
+
+const (
+	// MisconceptionRepeatCount is a repeat block that runs once too often or
+	// once too few.
+	MisconceptionRepeatCount Misconception = "repeat_off_by_one"
+	// MisconceptionTurnDirection is turning left where right was meant, or
+	// the other way round.
+	MisconceptionTurnDirection Misconception = "left_right_confusion"
+	// MisconceptionCollectTile is collecting on a tile without a collectible,
+	// or on one already emptied.
+	MisconceptionCollectTile Misconception = "collect_wrong_tile"
+	// MisconceptionOverNesting is blocks nested deeper than the task needs,
+	// such as a repeat running once or a repeat holding only another repeat.
+	MisconceptionOverNesting Misconception = "over_nesting"
+)
+
+// Analyzer limits. Programs with more than maxAnalyzedInstructions blocks are
+// not re-simulated with corrections, and nesting deeper than
+// maxUsefulNesting counts as over-nesting.
+const (
+	maxAnalyzedInstructions = 60
+	maxUsefulNesting        = 2
+	maxMisconceptionsReport = 3
+	hintMisconceptionWindow = 5
+)
+
+// MisconceptionCount is how often failed runs of a student or a group showed a
+// misconception, with the levels they happened on. Students is the number of
+// students concerned in group totals.
+type MisconceptionCount struct {
+	Kind       Misconception `json:"kind"`
+	Count      int           `json:"count"`
+	Students   int           `json:"students,omitempty"`
+	Levels     []string      `json:"levels"`
+	LastSeenAt int64         `json:"lastSeenAt"`
+}
+
+// StudentMisconceptions are the misconceptions of one student, most frequent
+// first.
+type StudentMisconceptions struct {
+	StudentID      string               `json:"studentId"`
+	Misconceptions []MisconceptionCount `json:"misconceptions"`
+}
+
+// MisconceptionReport aggregates the misconceptions of a group of students in
+// a date range: Totals over the group and Students in the given order.
+type MisconceptionReport struct {
+	Totals   []MisconceptionCount    `json:"totals"`
+	Students []StudentMisconceptions `json:"students"`
+}
+
+// Misconceptions aggregates the misconceptions found in the failed runs of
+// the students in the range.
+func (s *Service) Misconceptions(ctx context.Context, studentIDs []string, rng AnalyticsRange) MisconceptionReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report := MisconceptionReport{Students: make([]StudentMisconceptions, 0, len(studentIDs))}
+	totals := make(map[Misconception]*MisconceptionCount)
+	for _, studentID := range studentIDs {
+		counts := s.studentMisconceptions(studentID, rng)
+		for _, count := range counts {
+			total, ok := totals[count.Kind]
+			if !ok {
+				total = &MisconceptionCount{Kind: count.Kind, Levels: make([]string, 0)}
+				totals[count.Kind] = total
+			}
+			total.Count += count.Count
+			total.Students++
+			total.LastSeenAt = max(total.LastSeenAt, count.LastSeenAt)
+			for _, levelID := range count.Levels {
+				if !contains(total.Levels, levelID) {
+					total.Levels = append(total.Levels, levelID)
+				}
+			}
+		}
+		report.Students = append(report.Students, StudentMisconceptions{StudentID: studentID, Misconceptions: counts})
+	}
+	report.Totals = make([]MisconceptionCount, 0, len(totals))
+	for _, total := range totals {
+		sort.Slice(total.Levels, func(i, j int) bool { return s.levelOrder(total.Levels[i]) < s.levelOrder(total.Levels[j]) })
+		report.Totals = append(report.Totals, *total)
+	}
+	sortMisconceptions(report.Totals)
+	return report
+}
+
+// StudentMisconceptionTexts describes the most frequent misconceptions of a
+// student in the range, in the student's language.
+func (s *Service) StudentMisconceptionTexts(ctx context.Context, studentID string, rng AnalyticsRange) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	locale := ""
+	if profile, ok := s.profiles[studentID]; ok {
+		locale = profile.Settings.Language
+	}
+	counts := s.studentMisconceptions(studentID, rng)
+	texts := make([]string, 0, maxMisconceptionsReport)
+	for _, count := range counts {
+		if len(texts) == maxMisconceptionsReport {
+			break
+		}
+		texts = append(texts, builtInString(locale, "misconception."+string(count.Kind)))
+	}
+	return texts
+}
+
+// studentMisconceptions counts the misconceptions of a student's failed runs
+// in the range. Callers must hold the lock.
+func (s *Service) studentMisconceptions(studentID string, rng AnalyticsRange) []MisconceptionCount {
+	counts := make(map[Misconception]*MisconceptionCount)
+	for levelID, attempts := range s.attempts[studentID] {
+		for _, attempt := range attempts {
+			if !rng.contains(attempt.CreatedAt) {
+				continue
+			}
+			for _, kind := range attempt.Misconceptions {
+				count, ok := counts[kind]
+				if !ok {
+					count = &MisconceptionCount{Kind: kind, Levels: make([]string, 0)}
+					counts[kind] = count
+				}
+				count.Count++
+				count.LastSeenAt = max(count.LastSeenAt, attempt.CreatedAt)
+				if !contains(count.Levels, levelID) {
+					count.Levels = append(count.Levels, levelID)
+				}
+			}
+		}
+	}
+	result := make([]MisconceptionCount, 0, len(counts))
+	for _, count := range counts {
+		sort.Slice(count.Levels, func(i, j int) bool { return s.levelOrder(count.Levels[i]) < s.levelOrder(count.Levels[j]) })
+		result = append(result, *count)
+	}
+	sortMisconceptions(result)
+	return result
+}
+
+// recentMisconception returns the misconception seen most often in the
+// student's last failed runs of a level, if any. Callers must hold the lock.
+func (s *Service) recentMisconception(studentID, levelID string) (Misconception, bool) {
+	attempts := s.attempts[studentID][levelID]
+	counts := make(map[Misconception]int)
+	var best Misconception
+	seen := 0
+	for i := len(attempts) - 1; i >= 0 && seen < hintMisconceptionWindow; i-- {
+		if attempts[i].Success {
+			break
+		}
+		seen++
+		for _, kind := range attempts[i].Misconceptions {
+			counts[kind]++
+			if counts[kind] > counts[best] {
+				best = kind
+			}
+		}
+	}
+	return best, best != ""
+}
+
+// classifyRun finds the misconceptions behind a failed run from its program
+// and run log. Repeat counts and turn directions are blamed when correcting a
+// single block, or every turn, would have solved the level.
+func classifyRun(level LevelDefinition, program []Instruction, result SimulationResult) []Misconception {
+	if result.Success {
+		return nil
+	}
+	found := make([]Misconception, 0)
+	nodes := countInstructions(program)
+	if nodes <= maxAnalyzedInstructions && solvedByRepeatCount(level, program, nodes) {
+		found = append(found, MisconceptionRepeatCount)
+	}
+	if turnedWrongWay(level, result) || (nodes <= maxAnalyzedInstructions && solvedByTurnFlip(level, program, nodes)) {
+		found = append(found, MisconceptionTurnDirection)
+	}
+	if collectedOffTile(level, result) {
+		found = append(found, MisconceptionCollectTile)
+	}
+	if result.ErrorCode == "E_LOOP_DEPTH" || overNested(program, 0) {
+		found = append(found, MisconceptionOverNesting)
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return found
+}
+
+// solvedByRepeatCount reports whether running a single repeat block once
+// more or once less makes the program solve the level.
+func solvedByRepeatCount(level LevelDefinition, program []Instruction, nodes int) bool {
+	for target := 0; target < nodes; target++ {
+		if original := instructionAt(program, target); original.Type != "repeat" {
+			continue
+		}
+		for _, delta := range []int{-1, 1} {
+			variant := cloneInstructions(program)
+			instruction := instructionAt(variant, target)
+			if instruction.Times+delta < 1 {
+				continue
+			}
+			instruction.Times += delta
+			if newSimulator(level).run(variant).Success {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// solvedByTurnFlip reports whether turning the other way, at a single turn
+// block or at all of them, makes the program solve the level.
+func solvedByTurnFlip(level LevelDefinition, program []Instruction, nodes int) bool {
+	all := cloneInstructions(program)
+	turns := 0
+	walkInstructions(all, 0, func(instruction *Instruction, _ int) {
+		if instruction.Type == "turn" {
+			instruction.Direction = oppositeTurn(instruction.Direction)
+			turns++
+		}
+	})
+	if turns == 0 {
+		return false
+	}
+	if turns > 1 && newSimulator(level).run(all).Success {
+		return true
+	}
+	for target := 0; target < nodes; target++ {
+		variant := cloneInstructions(program)
+		instruction := instructionAt(variant, target)
+		if instruction.Type != "turn" {
+			continue
+		}
+		instruction.Direction = oppositeTurn(instruction.Direction)
+		if newSimulator(level).run(variant).Success {
+			return true
+		}
+	}
+	return false
+}
+
+// turnedWrongWay reports whether a run crashed moving right after a turn
+// when turning the other way would have left the path open.
+func turnedWrongWay(level LevelDefinition, result SimulationResult) bool {
+	if result.ErrorCode != "E_COLLIDE" || len(result.Log) < 2 {
+		return false
+	}
+	crash := result.Log[len(result.Log)-1]
+	turn := result.Log[len(result.Log)-2]
+	if crash.Instruction.Type != "move" || turn.Instruction.Type != "turn" {
+		return false
+	}
+	facing := rotate(turn.Position.Facing, oppositeTurn(turn.Instruction.Direction))
+	next := moveForward(Position{X: crash.Position.X, Y: crash.Position.Y, Facing: facing})
+	return newSimulator(level).isWalkable(next.X, next.Y)
+}
+
+// collectedOffTile reports whether a run collected on a tile without a
+// collectible, or on one it had already emptied.
+func collectedOffTile(level LevelDefinition, result SimulationResult) bool {
+	sim := newSimulator(level)
+	collected := make(map[Position]bool)
+	for _, step := range result.Log {
+		if step.Instruction.Type != "collect" {
+			continue
+		}
+		tile := Position{X: step.Position.X, Y: step.Position.Y}
+		if sim.collectibleAt(tile.X, tile.Y) == "" || collected[tile] {
+			return true
+		}
+		collected[tile] = true
+	}
+	return false
+}
+
+// overNested reports whether blocks are nested deeper than maxUsefulNesting,
+// or a repeat runs once or only wraps another repeat.
+func overNested(program []Instruction, depth int) bool {
+	for _, instruction := range program {
+		switch instruction.Type {
+		case "repeat":
+			if depth+1 > maxUsefulNesting || instruction.Times == 1 {
+				return true
+			}
+			if len(instruction.Body) == 1 && instruction.Body[0].Type == "repeat" {
+				return true
+			}
+			if overNested(instruction.Body, depth+1) {
+				return true
+			}
+		case "conditional":
+			if depth+1 > maxUsefulNesting || overNested(instruction.Truthy, depth+1) || overNested(instruction.Falsy, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// walkInstructions calls visit for every block of a program, nested ones
+// included, in program order.
+func walkInstructions(program []Instruction, depth int, visit func(*Instruction, int)) {
+	for i := range program {
+		instruction := &program[i]
+		visit(instruction, depth)
+		walkInstructions(instruction.Body, depth+1, visit)
+		walkInstructions(instruction.Truthy, depth+1, visit)
+		walkInstructions(instruction.Falsy, depth+1, visit)
+	}
+}
+
+func countInstructions(program []Instruction) int {
+	count := 0
+	walkInstructions(program, 0, func(*Instruction, int) { count++ })
+	return count
+}
+
+// instructionAt returns the block at position target in walk order, or nil
+// past the end.
+func instructionAt(program []Instruction, target int) *Instruction {
+	var found *Instruction
+	index := 0
+	walkInstructions(program, 0, func(instruction *Instruction, _ int) {
+		if index == target {
+			found = instruction
+		}
+		index++
+	})
+	return found
+}
+
+func oppositeTurn(direction string) string {
+	if direction == "left" {
+		return "right"
+	}
+	return "left"
+}
+
+func sortMisconceptions(counts []MisconceptionCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Kind < counts[j].Kind
+	})
+}
@@ -0,0 +1,415 @@
+package student
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// The text form of a program maps one-to-one onto []Instruction:
+//
+//	move()                  {"type":"move"}
+//	turn(left)              {"type":"turn","direction":"left"}
+//	collect()               {"type":"collect"}
+//	repeat 4 { ... }        {"type":"repeat","times":4,"body":[...]}
+//	if COND { ... } else { ... }
+//	                        {"type":"conditional","condition":{"type":COND},"truthy":[...],"falsy":[...]}
+//
+// Statements are separated by newlines or semicolons and "//" starts a
+// comment that runs to the end of the line. Conditions are written as their
+// type, e.g. tile-ahead-walkable.
+
+// Limits on program text. Nesting deeper than the simulator allows is
+// reported while parsing instead of at run time.
+const (
+	maxProgramTextBytes = 16 << 10
+	maxRepeatTimes      = 1000
+)
+
+// Errors returned when converting between the text and block forms.
+var (
+	ErrSyntax         = apperr.New(apperr.KindInvalid, "program.syntax")
+	ErrNoTextForm     = apperr.New(apperr.KindUnprocessable, "program.no_text_form")
+	ErrProgramTooLong = apperr.New(apperr.KindInvalid, "program.too_long")
+)
+
+// SyntaxError reports where a program text could not be parsed. Line and
+// Column are 1-based and count runes. Reason is a stable code such as
+// "expected" and Params holds the values it refers to; tokens are given as
+// their text, with "<newline>" and "<end>" standing for line breaks and the
+// end of the text.
+type SyntaxError struct {
+	Line   int            `json:"line"`
+	Column int            `json:"column"`
+	Reason string         `json:"reason"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, apperr.Problem{Code: e.Reason, Params: e.Params})
+}
+
+// AppError reports the position, reason and parameters as details of
+// ErrSyntax.
+func (e *SyntaxError) AppError() *apperr.Error {
+	err := ErrSyntax.With("line", e.Line).With("column", e.Column).With("reason", e.Reason)
+	for key, value := range e.Params {
+		err = err.With(key, value)
+	}
+	return err
+}
+
+// ParseProgram converts program text into instructions.
+func ParseProgram(source string) ([]Instruction, error) {
+	if len(source) > maxProgramTextBytes {
+		return nil, ErrProgramTooLong.With("maxBytes", maxProgramTextBytes)
+	}
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	program, err := p.block(false)
+	if err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// ProgramFromSource returns the blocks of a request that carries a program
+// in either form: program when it is not empty, otherwise the parsed source.
+func ProgramFromSource(program []Instruction, source string) ([]Instruction, error) {
+	if len(program) > 0 || source == "" {
+		return program, nil
+	}
+	return ParseProgram(source)
+}
+
+// FormatProgram prints instructions in the canonical text form, indenting
+// nested blocks by two spaces. It fails for instructions that have no text
+// form, such as unknown types or conditionals without a condition.
+func FormatProgram(program []Instruction) (string, error) {
+	var b strings.Builder
+	if err := formatBlock(&b, program, 0); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func formatBlock(b *strings.Builder, program []Instruction, depth int) error {
+	indent := strings.Repeat("  ", depth)
+	for _, instr := range program {
+		b.WriteString(indent)
+		switch instr.Type {
+		case "move", "collect":
+			b.WriteString(instr.Type + "()\n")
+		case "turn":
+			if instr.Direction != "left" && instr.Direction != "right" {
+				return ErrNoTextForm.With("type", instr.Type).With("direction", instr.Direction)
+			}
+			b.WriteString("turn(" + instr.Direction + ")\n")
+		case "repeat":
+			if instr.Times < 0 {
+				return ErrNoTextForm.With("type", instr.Type).With("times", instr.Times)
+			}
+			b.WriteString("repeat " + strconv.Itoa(instr.Times) + " ")
+			if err := formatBody(b, instr.Body, depth); err != nil {
+				return err
+			}
+			b.WriteString("\n")
+		case "conditional":
+			if instr.Condition == nil || !isIdentifier(instr.Condition.Type) {
+				return ErrNoTextForm.With("type", instr.Type)
+			}
+			b.WriteString("if " + instr.Condition.Type + " ")
+			if err := formatBody(b, instr.Truthy, depth); err != nil {
+				return err
+			}
+			if len(instr.Falsy) > 0 {
+				b.WriteString(" else ")
+				if err := formatBody(b, instr.Falsy, depth); err != nil {
+					return err
+				}
+			}
+			b.WriteString("\n")
+		default:
+			return ErrNoTextForm.With("type", instr.Type)
+		}
+	}
+	return nil
+}
+
+func formatBody(b *strings.Builder, body []Instruction, depth int) error {
+	if len(body) == 0 {
+		b.WriteString("{}")
+		return nil
+	}
+	b.WriteString("{\n")
+	if err := formatBlock(b, body, depth+1); err != nil {
+		return err
+	}
+	b.WriteString(strings.Repeat("  ", depth) + "}")
+	return nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenSymbol
+	tokenNewline
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int
+	column int
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "<end>"
+	case tokenNewline:
+		return "<newline>"
+	default:
+		return t.text
+	}
+}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	line, column := 1, 1
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := token{line: line, column: column}
+		switch {
+		case r == '\n':
+			start.kind, start.text = tokenNewline, "\n"
+			tokens = append(tokens, start)
+			i++
+			line, column = line+1, 1
+			continue
+		case unicode.IsSpace(r):
+			i++
+			column++
+			continue
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+				column++
+			}
+			continue
+		case strings.ContainsRune("(){};", r):
+			start.kind, start.text = tokenSymbol, string(r)
+			i++
+			column++
+		case isDigit(r):
+			j := i
+			for j < len(runes) && isDigit(runes[j]) {
+				j++
+			}
+			start.kind, start.text = tokenNumber, string(runes[i:j])
+			column += j - i
+			i = j
+		case isIdentStart(r):
+			j := i
+			for j < len(runes) && isIdentPart(runes[j]) {
+				j++
+			}
+			start.kind, start.text = tokenIdent, string(runes[i:j])
+			column += j - i
+			i = j
+		default:
+			return nil, &SyntaxError{Line: line, Column: column, Reason: "unexpected_char", Params: map[string]any{"char": string(r)}}
+		}
+		tokens = append(tokens, start)
+	}
+	return append(tokens, token{kind: tokenEOF, line: line, column: column}), nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || (r < unicode.MaxASCII && unicode.IsLetter(r))
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || r == '-' || isDigit(r)
+}
+
+func isIdentifier(text string) bool {
+	for i, r := range text {
+		if (i == 0 && !isIdentStart(r)) || !isIdentPart(r) {
+			return false
+		}
+	}
+	return text != ""
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) fail(tok token, reason string, params ...any) error {
+	problem := apperr.NewProblem("", reason, params...)
+	return &SyntaxError{Line: tok.line, Column: tok.column, Reason: problem.Code, Params: problem.Params}
+}
+
+func (p *parser) expect(text string) error {
+	tok := p.next()
+	if tok.kind != tokenSymbol || tok.text != text {
+		return p.fail(tok, "expected", "expected", text, "found", tok.describe())
+	}
+	return nil
+}
+
+func (p *parser) skipSeparators() {
+	for tok := p.peek(); tok.kind == tokenNewline || (tok.kind == tokenSymbol && tok.text == ";"); tok = p.peek() {
+		p.next()
+	}
+}
+
+// block parses statements until the end of input or, when nested, until the
+// closing brace, which it consumes.
+func (p *parser) block(nested bool) ([]Instruction, error) {
+	program := []Instruction{}
+	for {
+		p.skipSeparators()
+		tok := p.peek()
+		if tok.kind == tokenEOF {
+			if nested {
+				return nil, p.fail(tok, "unclosed_block")
+			}
+			return program, nil
+		}
+		if tok.kind == tokenSymbol && tok.text == "}" {
+			if !nested {
+				return nil, p.fail(tok, "unexpected_brace")
+			}
+			p.next()
+			return program, nil
+		}
+
+		instr, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		program = append(program, instr)
+
+		after := p.peek()
+		switch {
+		case after.kind == tokenEOF, after.kind == tokenNewline:
+		case after.kind == tokenSymbol && (after.text == ";" || after.text == "}"):
+		default:
+			return nil, p.fail(after, "missing_separator", "found", after.describe())
+		}
+	}
+}
+
+func (p *parser) statement() (Instruction, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return Instruction{}, p.fail(tok, "expected_statement", "found", tok.describe())
+	}
+
+	switch tok.text {
+	case "move", "collect":
+		if err := p.expect("("); err != nil {
+			return Instruction{}, err
+		}
+		if err := p.expect(")"); err != nil {
+			return Instruction{}, err
+		}
+		return Instruction{Type: tok.text}, nil
+	case "turn":
+		if err := p.expect("("); err != nil {
+			return Instruction{}, err
+		}
+		dir := p.next()
+		if dir.kind != tokenIdent || (dir.text != "left" && dir.text != "right") {
+			return Instruction{}, p.fail(dir, "invalid_direction", "found", dir.describe())
+		}
+		if err := p.expect(")"); err != nil {
+			return Instruction{}, err
+		}
+		return Instruction{Type: "turn", Direction: dir.text}, nil
+	case "repeat":
+		count := p.next()
+		if count.kind != tokenNumber {
+			return Instruction{}, p.fail(count, "expected_count", "found", count.describe())
+		}
+		times, err := strconv.Atoi(count.text)
+		if err != nil || times > maxRepeatTimes {
+			return Instruction{}, p.fail(count, "repeat_too_large", "max", maxRepeatTimes)
+		}
+		body, err := p.body()
+		if err != nil {
+			return Instruction{}, err
+		}
+		return Instruction{Type: "repeat", Times: times, Body: body}, nil
+	case "if":
+		cond := p.next()
+		if cond.kind != tokenIdent {
+			return Instruction{}, p.fail(cond, "expected_condition", "found", cond.describe())
+		}
+		truthy, err := p.body()
+		if err != nil {
+			return Instruction{}, err
+		}
+		instr := Instruction{Type: "conditional", Condition: &Condition{Type: cond.text}, Truthy: truthy}
+		if next := p.peek(); next.kind == tokenIdent && next.text == "else" {
+			p.next()
+			falsy, err := p.body()
+			if err != nil {
+				return Instruction{}, err
+			}
+			instr.Falsy = falsy
+		}
+		return instr, nil
+	default:
+		return Instruction{}, p.fail(tok, "unknown_statement", "found", tok.text)
+	}
+}
+
+// body parses a braced block. Empty blocks yield a nil slice so that they
+// match the omitted fields of the JSON form.
+func (p *parser) body() ([]Instruction, error) {
+	open := p.peek()
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if p.depth >= maxLoopDepth {
+		return nil, p.fail(open, "nesting_too_deep", "max", maxLoopDepth)
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	body, err := p.block(true)
+	if err != nil || len(body) == 0 {
+		return nil, err
+	}
+	return body, nil
+}
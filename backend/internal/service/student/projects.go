@@ -0,0 +1,480 @@
+package student
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Errors returned by sandbox projects.
+var (
+	ErrProjectNotFound = apperr.New(apperr.KindNotFound, "project.not_found")
+	ErrProjectInvalid  = apperr.New(apperr.KindUnprocessable, "project.invalid")
+	ErrSandboxLocked   = apperr.New(apperr.KindForbidden, "sandbox.locked")
+)
+
+// maxProjectTitle bounds project titles in characters.
+const maxProjectTitle = 40
+
+// ProjectStatus is where a project is in class moderation. Only published
+// projects are visible to classmates; a student publishing a project makes it
+// pending until a teacher approves or rejects it.
+type ProjectStatus string
+
+const (
+	ProjectPrivate   ProjectStatus = "private"
+	ProjectPending   ProjectStatus = "pending"
+	ProjectPublished ProjectStatus = "published"
+	ProjectRejected  ProjectStatus = "rejected"
+)
+
+// ProjectMap is a custom map built in sandbox mode. The goal is optional.
+type ProjectMap struct {
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+	Tiles  []Tile    `json:"tiles"`
+	Start  Position  `json:"start"`
+	Goal   LevelGoal `json:"goal"`
+}
+
+// SandboxProject is a saved sandbox program, played either on an existing
+// level or on a custom map.
+type SandboxProject struct {
+	ID             string        `json:"id"`
+	OwnerID        string        `json:"ownerId"`
+	ClassID        string        `json:"classId"`
+	Title          string        `json:"title"`
+	LevelID        string        `json:"levelId,omitempty"`
+	Map            *ProjectMap   `json:"map,omitempty"`
+	Program        []Instruction `json:"program"`
+	ForkedFrom     string        `json:"forkedFrom,omitempty"`
+	Forks          int           `json:"forks"`
+	Status         ProjectStatus `json:"status"`
+	ModerationNote string        `json:"moderationNote,omitempty"`
+	ModeratedBy    string        `json:"moderatedBy,omitempty"`
+	CreatedAt      int64         `json:"createdAt"`
+	UpdatedAt      int64         `json:"updatedAt"`
+	PublishedAt    int64         `json:"publishedAt,omitempty"`
+}
+
+// ProjectInput is the editable part of a project. A project needs either a
+// base level or a custom map.
+type ProjectInput struct {
+	Title   string        `json:"title"`
+	LevelID string        `json:"levelId"`
+	Map     *ProjectMap   `json:"map"`
+	Program []Instruction `json:"program"`
+}
+
+// Projects lists the student's own projects, most recently updated first.
+func (s *Service) Projects(ctx context.Context, userID string) []SandboxProject {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := make([]SandboxProject, 0)
+	for _, project := range s.projects {
+		if project.OwnerID == profile.ID {
+			projects = append(projects, s.projectCopy(project))
+		}
+	}
+	sortProjects(projects, func(p SandboxProject) int64 { return p.UpdatedAt })
+	return projects
+}
+
+// Gallery lists the published projects of the student's class, most recently
+// published first.
+func (s *Service) Gallery(ctx context.Context, userID string) []SandboxProject {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.classProjects(profile.ClassID, ProjectPublished)
+}
+
+// Project returns a project the student owns or can see in the gallery.
+func (s *Service) Project(ctx context.Context, userID, projectID string) (SandboxProject, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, err := s.visibleProject(profile, projectID)
+	if err != nil {
+		return SandboxProject{}, err
+	}
+	return s.projectCopy(project), nil
+}
+
+// CreateProject saves a new private project. Sandbox mode must be unlocked.
+func (s *Service) CreateProject(ctx context.Context, userID string, input ProjectInput) (SandboxProject, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !profile.SandboxUnlocked {
+		return SandboxProject{}, ErrSandboxLocked
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		input.Title = "未命名作品"
+	}
+	if err := s.validateProject(profile, input); err != nil {
+		return SandboxProject{}, err
+	}
+
+	now := time.Now().UnixMilli()
+	project := &SandboxProject{
+		ID:        uuid.NewString(),
+		OwnerID:   profile.ID,
+		ClassID:   profile.ClassID,
+		Status:    ProjectPrivate,
+		CreatedAt: now,
+	}
+	applyProjectInput(project, input, now)
+	s.projects[project.ID] = project
+	return s.projectCopy(project), nil
+}
+
+// UpdateProject replaces the title, map and program of an own project. A
+// shared project goes back to moderation; a rejected one becomes private.
+func (s *Service) UpdateProject(ctx context.Context, userID, projectID string, input ProjectInput) (SandboxProject, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.ownProject(profile.ID, projectID)
+	if err != nil {
+		return SandboxProject{}, err
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		input.Title = project.Title
+	}
+	if err := s.validateProject(profile, input); err != nil {
+		return SandboxProject{}, err
+	}
+
+	applyProjectInput(project, input, time.Now().UnixMilli())
+	switch project.Status {
+	case ProjectPublished, ProjectPending:
+		project.Status = ProjectPending
+		project.PublishedAt = 0
+	case ProjectRejected:
+		project.Status = ProjectPrivate
+	}
+	project.ModerationNote, project.ModeratedBy = "", ""
+	return s.projectCopy(project), nil
+}
+
+// DeleteProject removes an own project. Forks of it are kept.
+func (s *Service) DeleteProject(ctx context.Context, userID, projectID string) error {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownProject(profile.ID, projectID); err != nil {
+		return err
+	}
+	delete(s.projects, projectID)
+	return nil
+}
+
+// ForkProject copies an own project or one published in the student's class
+// into a new private project of the student.
+func (s *Service) ForkProject(ctx context.Context, userID, projectID string) (SandboxProject, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !profile.SandboxUnlocked {
+		return SandboxProject{}, ErrSandboxLocked
+	}
+	source, err := s.visibleProject(profile, projectID)
+	if err != nil {
+		return SandboxProject{}, err
+	}
+
+	now := time.Now().UnixMilli()
+	fork := &SandboxProject{
+		ID:         uuid.NewString(),
+		OwnerID:    profile.ID,
+		ClassID:    profile.ClassID,
+		ForkedFrom: source.ID,
+		Status:     ProjectPrivate,
+		CreatedAt:  now,
+	}
+	applyProjectInput(fork, ProjectInput{
+		Title:   source.Title,
+		LevelID: source.LevelID,
+		Map:     source.Map,
+		Program: source.Program,
+	}, now)
+	source.Forks++
+	s.projects[fork.ID] = fork
+	return s.projectCopy(fork), nil
+}
+
+// PublishProject submits an own project to the class gallery. It becomes
+// visible once a teacher approves it.
+func (s *Service) PublishProject(ctx context.Context, userID, projectID string) (SandboxProject, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.ownProject(profile.ID, projectID)
+	if err != nil {
+		return SandboxProject{}, err
+	}
+	if project.Status != ProjectPublished {
+		project.Status = ProjectPending
+		project.ModerationNote, project.ModeratedBy = "", ""
+	}
+	return s.projectCopy(project), nil
+}
+
+// UnpublishProject takes an own project out of the gallery or moderation.
+func (s *Service) UnpublishProject(ctx context.Context, userID, projectID string) (SandboxProject, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.ownProject(profile.ID, projectID)
+	if err != nil {
+		return SandboxProject{}, err
+	}
+	project.Status = ProjectPrivate
+	project.PublishedAt = 0
+	return s.projectCopy(project), nil
+}
+
+// RunProject simulates a program on the project's level or map. An empty
+// program runs the saved one.
+func (s *Service) RunProject(ctx context.Context, userID, projectID string, program []Instruction) (SimulationResult, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.visibleProject(profile, projectID)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+	if err := s.checkRunAllowed(profile, true); err != nil {
+		return SimulationResult{}, err
+	}
+	if len(program) == 0 {
+		program = project.Program
+	}
+	level, err := s.playableProjectLevel(profile, project)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+	now := time.Now()
+	if project.LevelID != "" {
+		if err := s.checkAssessmentFeedback(profile, project.LevelID, now.UnixMilli(), ErrAssessmentInProgress); err != nil {
+			return SimulationResult{}, err
+		}
+	}
+	s.recordActivity(profile.ID, now)
+	return newSimulator(level).run(program), nil
+}
+
+// ClassProjects lists the projects of a class in the given moderation status,
+// oldest first so teachers review in submission order.
+func (s *Service) ClassProjects(ctx context.Context, classID string, status ProjectStatus) []SandboxProject {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := s.classProjects(classID, status)
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].UpdatedAt < projects[j].UpdatedAt })
+	return projects
+}
+
+// ModerateProject approves or rejects a project submitted to the gallery of
+// a class. Approving a published project again is a no-op.
+func (s *Service) ModerateProject(ctx context.Context, classID, projectID, moderatorID string, approve bool, note string) (SandboxProject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[projectID]
+	if !ok || project.ClassID != classID || project.Status == ProjectPrivate {
+		return SandboxProject{}, ErrProjectNotFound.With("projectId", projectID)
+	}
+	project.ModerationNote = strings.TrimSpace(note)
+	project.ModeratedBy = moderatorID
+	if !approve {
+		project.Status = ProjectRejected
+		project.PublishedAt = 0
+		return s.projectCopy(project), nil
+	}
+	if project.Status != ProjectPublished {
+		project.Status = ProjectPublished
+		project.PublishedAt = time.Now().UnixMilli()
+	}
+	return s.projectCopy(project), nil
+}
+
+// ownProject returns a project owned by the student. Callers must hold the
+// lock.
+func (s *Service) ownProject(ownerID, projectID string) (*SandboxProject, error) {
+	project, ok := s.projects[projectID]
+	if !ok || project.OwnerID != ownerID {
+		return nil, ErrProjectNotFound.With("projectId", projectID)
+	}
+	return project, nil
+}
+
+// visibleProject returns a project the student owns or that is published in
+// their class. Callers must hold the lock.
+func (s *Service) visibleProject(profile *StudentProfile, projectID string) (*SandboxProject, error) {
+	project, ok := s.projects[projectID]
+	if !ok {
+		return nil, ErrProjectNotFound.With("projectId", projectID)
+	}
+	if project.OwnerID != profile.ID && (project.Status != ProjectPublished || project.ClassID != profile.ClassID) {
+		return nil, ErrProjectNotFound.With("projectId", projectID)
+	}
+	return project, nil
+}
+
+// classProjects lists the projects of a class in a status, most recently
+// published or updated first. Callers must hold the lock.
+func (s *Service) classProjects(classID string, status ProjectStatus) []SandboxProject {
+	projects := make([]SandboxProject, 0)
+	for _, project := range s.projects {
+		if project.ClassID == classID && project.Status == status {
+			projects = append(projects, s.projectCopy(project))
+		}
+	}
+	sortProjects(projects, func(p SandboxProject) int64 {
+		if p.PublishedAt > 0 {
+			return p.PublishedAt
+		}
+		return p.UpdatedAt
+	})
+	return projects
+}
+
+// validateProject checks the base level or custom map of a project. Custom
+// maps get the structural level checks but need not have a goal. Base levels
+// must be visible to the student and not under an open assessment. Callers
+// must hold the lock.
+func (s *Service) validateProject(profile *StudentProfile, input ProjectInput) error {
+	var problems []apperr.Problem
+	if len([]rune(strings.TrimSpace(input.Title))) > maxProjectTitle {
+		problems = append(problems, apperr.NewProblem("title", "too_long", "max", maxProjectTitle))
+	}
+	switch {
+	case input.Map == nil && input.LevelID == "":
+		problems = append(problems, apperr.NewProblem("levelId", "required"))
+	case input.Map != nil && input.LevelID != "":
+		problems = append(problems, apperr.NewProblem("map", "conflicts_with_level"))
+	case input.Map != nil:
+		for _, problem := range mapProblems(input.Map.level(), false) {
+			problem.Path = "map." + problem.Path
+			problems = append(problems, problem)
+		}
+	default:
+		if level, ok := s.levels[input.LevelID]; !ok || !s.levelVisible(profile, level) {
+			return ErrLevelNotFound.With("levelId", input.LevelID)
+		}
+		if err := s.checkAssessmentFeedback(profile, input.LevelID, time.Now().UnixMilli(), ErrAssessmentInProgress); err != nil {
+			return err
+		}
+	}
+	if len(problems) > 0 {
+		return ErrProjectInvalid.With("problems", problems)
+	}
+	return nil
+}
+
+// playableProjectLevel returns the level a student plays a project on. Base
+// levels are checked again since the student, or the level's chapter, may
+// have moved since the project was saved, and they follow the class focus
+// like any other run. Callers must hold the lock.
+func (s *Service) playableProjectLevel(profile *StudentProfile, project *SandboxProject) (LevelDefinition, error) {
+	level, ok := s.projectLevel(project)
+	if !ok {
+		return LevelDefinition{}, ErrLevelNotFound.With("levelId", project.LevelID)
+	}
+	if project.LevelID == "" {
+		return level, nil
+	}
+	if !s.levelVisible(profile, level) {
+		return LevelDefinition{}, ErrLevelNotFound.With("levelId", project.LevelID)
+	}
+	if err := s.checkLevelRestriction(profile, level); err != nil {
+		return LevelDefinition{}, err
+	}
+	return level, nil
+}
+
+// projectLevel returns the level a project is played on. Callers must hold
+// the lock.
+func (s *Service) projectLevel(project *SandboxProject) (LevelDefinition, bool) {
+	if project.Map != nil {
+		level := project.Map.level()
+		level.ID = project.ID
+		level.Name = project.Title
+		return level, true
+	}
+	level, ok := s.levels[project.LevelID]
+	return level, ok
+}
+
+// projectCopy returns a project with its map and program detached from the
+// stored one.
+func (s *Service) projectCopy(project *SandboxProject) SandboxProject {
+	clone := *project
+	clone.Map = project.Map.clone()
+	clone.Program = append([]Instruction(nil), project.Program...)
+	return clone
+}
+
+func applyProjectInput(project *SandboxProject, input ProjectInput, now int64) {
+	project.Title = strings.TrimSpace(input.Title)
+	project.LevelID = input.LevelID
+	project.Map = input.Map.clone()
+	project.Program = append([]Instruction{}, input.Program...)
+	project.UpdatedAt = now
+}
+
+func (m *ProjectMap) level() LevelDefinition {
+	return LevelDefinition{
+		Width:  m.Width,
+		Height: m.Height,
+		Tiles:  m.Tiles,
+		Start:  m.Start,
+		Goal:   m.Goal,
+	}
+}
+
+func (m *ProjectMap) clone() *ProjectMap {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.Tiles = append([]Tile(nil), m.Tiles...)
+	return &clone
+}
+
+func sortProjects(projects []SandboxProject, key func(SandboxProject) int64) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		if a, b := key(projects[i]), key(projects[j]); a != b {
+			return a > b
+		}
+		return projects[i].ID < projects[j].ID
+	})
+}
@@ -0,0 +1,105 @@
+package student
+
+import (
+	"context"
+)
+
+// Replay paging bounds.
+const (
+	defaultReplayPageSize = 100
+	maxReplayPageSize     = 500
+)
+
+// ReplayFrame is the world state after one primitive instruction of a replay.
+type ReplayFrame struct {
+	Index        int         `json:"index"`
+	Step         int         `json:"step"`
+	Instruction  Instruction `json:"instruction"`
+	Position     Position    `json:"position"`
+	Collectibles int         `json:"collectibles"`
+	ErrorCode    string      `json:"errorCode,omitempty"`
+}
+
+// ReplayLevel is the level layout a replay is rendered against.
+type ReplayLevel struct {
+	ID     string    `json:"id"`
+	Name   string    `json:"name"`
+	Width  int       `json:"width"`
+	Height int       `json:"height"`
+	Tiles  []Tile    `json:"tiles"`
+	Start  Position  `json:"start"`
+	Goal   LevelGoal `json:"goal"`
+}
+
+// Replay is a page of frames produced by re-simulating a stored attempt.
+type Replay struct {
+	AttemptID   string        `json:"attemptId"`
+	Level       ReplayLevel   `json:"level"`
+	Success     bool          `json:"success"`
+	Stars       int           `json:"stars"`
+	Steps       int           `json:"steps"`
+	ErrorCode   string        `json:"errorCode,omitempty"`
+	Verified    bool          `json:"verified"`
+	TotalFrames int           `json:"totalFrames"`
+	Offset      int           `json:"offset"`
+	Frames      []ReplayFrame `json:"frames"`
+}
+
+// Replay re-simulates a stored attempt against the level revision it was played on and
+// returns the requested page of frames. Verified reports whether the
+// re-simulated outcome matches what was recorded for the attempt.
+func (s *Service) Replay(ctx context.Context, studentID, attemptID string, offset, limit int) (Replay, error) {
+	attempt, err := s.Attempt(ctx, studentID, attemptID)
+	if err != nil {
+		return Replay{}, err
+	}
+
+	s.mu.RLock()
+	level, ok := s.levelRevision(attempt.LevelID, attempt.Revision)
+	s.mu.RUnlock()
+	if !ok {
+		return Replay{}, ErrRevisionNotFound
+	}
+
+	simulator := newSimulator(level)
+	result := simulator.run(attempt.Program)
+
+	if limit <= 0 {
+		limit = defaultReplayPageSize
+	}
+	if limit > maxReplayPageSize {
+		limit = maxReplayPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	total := len(simulator.frames)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return Replay{
+		AttemptID: attempt.ID,
+		Level: ReplayLevel{
+			ID:     level.ID,
+			Name:   level.Name,
+			Width:  level.Width,
+			Height: level.Height,
+			Tiles:  append([]Tile(nil), level.Tiles...),
+			Start:  level.Start,
+			Goal:   level.Goal,
+		},
+		Success:     result.Success,
+		Stars:       result.Stars,
+		Steps:       result.Steps,
+		ErrorCode:   result.ErrorCode,
+		Verified:    result.Success == attempt.Success && result.Steps == attempt.Steps && result.Stars == attempt.Stars && result.ErrorCode == attempt.ErrorCode,
+		TotalFrames: total,
+		Offset:      offset,
+		Frames:      append([]ReplayFrame{}, simulator.frames[offset:end]...),
+	}, nil
+}
@@ -0,0 +1,272 @@
+package student
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// RevisionStatus describes the lifecycle state of a level revision.
+type RevisionStatus string
+
+const (
+	RevisionStatusDraft     RevisionStatus = "draft"
+	RevisionStatusPublished RevisionStatus = "published"
+)
+
+// Errors returned by the level revision operations.
+var (
+	ErrLevelNotFound    = apperr.New(apperr.KindNotFound, "level.not_found")
+	ErrRevisionNotFound = apperr.New(apperr.KindNotFound, "revision.not_found")
+	ErrRevisionNotDraft = apperr.New(apperr.KindConflict, "revision.not_draft")
+)
+
+// LevelRevision is an immutable snapshot of a level definition. Only the
+// latest revision may be a draft; published revisions never change.
+type LevelRevision struct {
+	LevelID     string          `json:"levelId"`
+	Revision    int             `json:"revision"`
+	Status      RevisionStatus  `json:"status"`
+	Level       LevelDefinition `json:"level"`
+	CreatedAt   int64           `json:"createdAt"`
+	PublishedAt int64           `json:"publishedAt,omitempty"`
+}
+
+// AffectedStudent is a student whose progress on a level was earned on an
+// older revision than the one currently published.
+type AffectedStudent struct {
+	StudentID   string `json:"studentId"`
+	ClassID     string `json:"classId"`
+	Revision    int    `json:"revision"`
+	Stars       int    `json:"stars"`
+	CompletedAt int64  `json:"completedAt"`
+}
+
+// MigrationStatus reports the outcome of migrating a student's progress.
+type MigrationStatus string
+
+const (
+	MigrationStatusMigrated       MigrationStatus = "migrated"
+	MigrationStatusReplayRequired MigrationStatus = "replay-required"
+)
+
+// MigrationResult describes how a student's progress moved to a new revision.
+type MigrationResult struct {
+	StudentID    string          `json:"studentId"`
+	FromRevision int             `json:"fromRevision"`
+	ToRevision   int             `json:"toRevision"`
+	Status       MigrationStatus `json:"status"`
+	Stars        int             `json:"stars"`
+}
+
+// LevelRevisions lists all revisions of a level, oldest first.
+func (s *Service) LevelRevisions(ctx context.Context, levelID string) ([]LevelRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions, ok := s.revisions[levelID]
+	if !ok {
+		return nil, ErrLevelNotFound
+	}
+	return append([]LevelRevision(nil), revisions...), nil
+}
+
+// CreateLevelDraft validates and stores a new draft revision for an existing
+// level. An open draft is replaced rather than stacking several unpublished
+// revisions.
+func (s *Service) CreateLevelDraft(ctx context.Context, levelID string, definition LevelDefinition) (LevelRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.levels[levelID]; !ok {
+		return LevelRevision{}, ErrLevelNotFound
+	}
+	if _, err := validateLevel(definition); err != nil {
+		return LevelRevision{}, err
+	}
+	return s.saveDraft(levelID, definition), nil
+}
+
+// PublishLevelRevision makes a draft revision the one students play. Existing
+// progress keeps pointing at the revision it was earned on.
+func (s *Service) PublishLevelRevision(ctx context.Context, levelID string, revision int) (LevelRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.publishRevision(levelID, revision)
+}
+
+// AffectedStudents lists students whose progress on the level predates the
+// currently published revision.
+func (s *Service) AffectedStudents(ctx context.Context, levelID string) ([]AffectedStudent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	level, ok := s.levels[levelID]
+	if !ok {
+		return nil, ErrLevelNotFound
+	}
+
+	affected := make([]AffectedStudent, 0)
+	for _, profile := range s.profiles {
+		progress, ok := profile.Progress[levelID]
+		if !ok || progress.Revision >= level.Revision {
+			continue
+		}
+		affected = append(affected, AffectedStudent{
+			StudentID:   profile.ID,
+			ClassID:     profile.ClassID,
+			Revision:    progress.Revision,
+			Stars:       progress.Stars,
+			CompletedAt: progress.CompletedAt,
+		})
+	}
+	sort.Slice(affected, func(i, j int) bool { return affected[i].StudentID < affected[j].StudentID })
+	return affected, nil
+}
+
+// MigrateLevelProgress moves affected students onto the published revision by
+// re-running their latest successful attempt against it. Students whose
+// solution no longer works keep their old progress and must replay the level.
+// An empty studentIDs list migrates every affected student.
+func (s *Service) MigrateLevelProgress(ctx context.Context, levelID string, studentIDs []string) ([]MigrationResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	level, ok := s.levels[levelID]
+	if !ok {
+		return nil, ErrLevelNotFound
+	}
+
+	selected := make(map[string]struct{}, len(studentIDs))
+	for _, id := range studentIDs {
+		selected[id] = struct{}{}
+	}
+
+	results := make([]MigrationResult, 0)
+	for _, profile := range s.profiles {
+		if _, ok := selected[profile.ID]; len(selected) > 0 && !ok {
+			continue
+		}
+		progress, ok := profile.Progress[levelID]
+		if !ok || progress.Revision >= level.Revision {
+			continue
+		}
+
+		result := MigrationResult{
+			StudentID:    profile.ID,
+			FromRevision: progress.Revision,
+			ToRevision:   level.Revision,
+			Status:       MigrationStatusReplayRequired,
+			Stars:        progress.Stars,
+		}
+		if attempt, ok := s.latestSuccessfulAttempt(profile.ID, levelID); ok {
+			outcome := newSimulator(level).run(attempt.Program)
+			if outcome.Success {
+				diff := outcome.Steps - level.BestSteps
+				progress.Stars = outcome.Stars
+				progress.Steps = outcome.Steps
+				progress.BestDifference = &diff
+				progress.Revision = level.Revision
+				profile.Progress[levelID] = progress
+				s.recomputeDerivedState(profile)
+
+				result.Status = MigrationStatusMigrated
+				result.Stars = outcome.Stars
+			}
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].StudentID < results[j].StudentID })
+	return results, nil
+}
+
+// saveDraft stores the definition as the level's open draft. Callers must
+// hold the write lock and have checked that the level exists.
+func (s *Service) saveDraft(levelID string, definition LevelDefinition) LevelRevision {
+	current := s.levels[levelID]
+	revisions := s.revisions[levelID]
+	last := revisions[len(revisions)-1]
+
+	definition = normalizeLevel(definition)
+	definition.ID = levelID
+	definition.ChapterID = current.ChapterID
+	definition.Order = current.Order
+	draft := LevelRevision{
+		LevelID:   levelID,
+		Revision:  last.Revision + 1,
+		Status:    RevisionStatusDraft,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	if last.Status == RevisionStatusDraft {
+		draft.Revision = last.Revision
+		revisions = revisions[:len(revisions)-1]
+	}
+	definition.Revision = draft.Revision
+	draft.Level = definition
+
+	s.revisions[levelID] = append(revisions, draft)
+	return draft
+}
+
+// publishRevision publishes a draft and makes it the live level. The live
+// placement wins over the one captured in the draft, since the level may have
+// been reordered after the draft was saved. Callers must hold the write lock.
+func (s *Service) publishRevision(levelID string, revision int) (LevelRevision, error) {
+	revisions, ok := s.revisions[levelID]
+	if !ok {
+		return LevelRevision{}, ErrLevelNotFound
+	}
+	for i := range revisions {
+		if revisions[i].Revision != revision {
+			continue
+		}
+		if revisions[i].Status != RevisionStatusDraft {
+			return LevelRevision{}, ErrRevisionNotDraft
+		}
+		current := s.levels[levelID]
+		revisions[i].Status = RevisionStatusPublished
+		revisions[i].PublishedAt = time.Now().UnixMilli()
+		revisions[i].Level.ChapterID = current.ChapterID
+		revisions[i].Level.Order = current.Order
+		s.applyLevel(revisions[i].Level)
+		return revisions[i], nil
+	}
+	return LevelRevision{}, ErrRevisionNotFound
+}
+
+// levelRevision returns the definition of a specific revision of a level.
+// Callers must hold the lock.
+func (s *Service) levelRevision(levelID string, revision int) (LevelDefinition, bool) {
+	for _, candidate := range s.revisions[levelID] {
+		if candidate.Revision == revision {
+			return candidate.Level, true
+		}
+	}
+	return LevelDefinition{}, false
+}
+
+func (s *Service) latestSuccessfulAttempt(studentID, levelID string) (Attempt, bool) {
+	attempts := s.attempts[studentID][levelID]
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if attempts[i].Success {
+			return attempts[i], true
+		}
+	}
+	return Attempt{}, false
+}
+
+// applyLevel makes the definition the live version of its level in both the
+// level index and the owning chapter. Callers must hold the write lock.
+func (s *Service) applyLevel(level LevelDefinition) {
+	s.levels[level.ID] = level
+	chapter, _ := s.findChapter(level.ChapterID)
+	if chapter == nil {
+		return
+	}
+	if idx := s.findLevelIndex(*chapter, level.ID); idx >= 0 {
+		chapter.Levels[idx] = level
+	}
+}
@@ -0,0 +1,463 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Errors returned by rubrics and rubric reviews.
+var (
+	ErrRubricNotFound = apperr.New(apperr.KindNotFound, "rubric.not_found")
+	ErrRubricInvalid  = apperr.New(apperr.KindUnprocessable, "rubric.invalid")
+	ErrReviewInvalid  = apperr.New(apperr.KindUnprocessable, "review.invalid")
+)
+
+// maxCriterionPoints bounds the points of one rubric criterion.
+const maxCriterionPoints = 100
+
+// RubricCheckType is a criterion the simulator can decide on its own.
+type RubricCheckType string
+
+const (
+	// RubricCheckGoalMet passes when the program completes the level.
+	RubricCheckGoalMet RubricCheckType = "goal_met"
+	// RubricCheckUsesBlock passes when the program contains Block anywhere.
+	RubricCheckUsesBlock RubricCheckType = "uses_block"
+	// RubricCheckMaxBlocks passes when the program has at most Limit blocks,
+	// counting nested ones.
+	RubricCheckMaxBlocks RubricCheckType = "max_blocks"
+	// RubricCheckMaxSteps passes when the program completes the level in at
+	// most Limit steps.
+	RubricCheckMaxSteps RubricCheckType = "max_steps"
+)
+
+// RubricCheck makes a criterion auto-checkable.
+type RubricCheck struct {
+	Type  RubricCheckType `json:"type"`
+	Block string          `json:"block,omitempty"`
+	Limit int             `json:"limit,omitempty"`
+}
+
+// RubricCriterion is one line of a rubric. Criteria with a check are scored
+// all-or-nothing by the simulator; the others are scored by the teacher.
+type RubricCriterion struct {
+	ID     string       `json:"id"`
+	Title  string       `json:"title"`
+	Points int          `json:"points"`
+	Check  *RubricCheck `json:"check,omitempty"`
+}
+
+// Rubric is how submissions to a class are graded: solutions of one level,
+// or sandbox projects when Kind is WorkProject. A class has at most one
+// rubric per level and one for projects.
+type Rubric struct {
+	ID        string            `json:"id"`
+	OwnerID   string            `json:"ownerId,omitempty"`
+	ClassID   string            `json:"classId"`
+	Kind      WorkKind          `json:"kind"`
+	LevelID   string            `json:"levelId,omitempty"`
+	Title     string            `json:"title"`
+	Criteria  []RubricCriterion `json:"criteria"`
+	UpdatedAt int64             `json:"updatedAt"`
+}
+
+// MaxPoints is the sum of the points of all criteria.
+func (r Rubric) MaxPoints() int {
+	total := 0
+	for _, criterion := range r.Criteria {
+		total += criterion.Points
+	}
+	return total
+}
+
+// CriterionScore is the score of one criterion. Passed is set for
+// auto-checked criteria; Graded is false for teacher-scored criteria that
+// were not reviewed yet.
+type CriterionScore struct {
+	CriterionID string `json:"criterionId"`
+	Title       string `json:"title"`
+	Points      int    `json:"points"`
+	MaxPoints   int    `json:"maxPoints"`
+	Auto        bool   `json:"auto"`
+	Passed      *bool  `json:"passed,omitempty"`
+	Graded      bool   `json:"graded"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// RubricScore is a submission scored against a rubric. Complete is true once
+// every criterion is graded.
+type RubricScore struct {
+	RubricID  string           `json:"rubricId"`
+	Criteria  []CriterionScore `json:"criteria"`
+	Total     int              `json:"total"`
+	MaxPoints int              `json:"maxPoints"`
+	Complete  bool             `json:"complete"`
+}
+
+// ScoreInput is the teacher's score for one criterion. Points may be omitted
+// for auto-checked criteria to keep the simulator's verdict.
+type ScoreInput struct {
+	CriterionID string `json:"criterionId"`
+	Points      *int   `json:"points"`
+	Comment     string `json:"comment"`
+}
+
+// WorkReviewRecord is one review of a submission, kept with the version of
+// the work it reviewed.
+type WorkReviewRecord struct {
+	Version    int           `json:"version"`
+	Status     WorkStatus    `json:"status"`
+	Feedback   string        `json:"feedback,omitempty"`
+	Stars      int           `json:"stars"`
+	Score      *RubricScore  `json:"score,omitempty"`
+	Program    []Instruction `json:"program"`
+	Result     WorkResult    `json:"result"`
+	ReviewedBy string        `json:"reviewedBy"`
+	ReviewedAt int64         `json:"reviewedAt"`
+}
+
+// ClassMember is a student on a class roster.
+type ClassMember struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GradebookCell is a student's latest reviewed score on one rubric.
+type GradebookCell struct {
+	RubricID  string     `json:"rubricId"`
+	WorkID    string     `json:"workId,omitempty"`
+	Status    WorkStatus `json:"status,omitempty"`
+	Total     *int       `json:"total"`
+	MaxPoints int        `json:"maxPoints"`
+}
+
+// GradebookRow is one student's scores, in the order of the rubrics.
+type GradebookRow struct {
+	ClassMember
+	Cells     []GradebookCell `json:"cells"`
+	Total     int             `json:"total"`
+	MaxPoints int             `json:"maxPoints"`
+}
+
+// Gradebook lists the scores of a class per rubric. A student's total counts
+// the rubrics they have a reviewed score for.
+type Gradebook struct {
+	ClassID string         `json:"classId"`
+	Rubrics []Rubric       `json:"rubrics"`
+	Rows    []GradebookRow `json:"rows"`
+}
+
+// Rubrics lists the rubrics of a class ordered by title.
+func (s *Service) Rubrics(ctx context.Context, classID string) []Rubric {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.classRubrics(classID)
+}
+
+// Rubric returns one rubric of a class.
+func (s *Service) Rubric(ctx context.Context, classID, rubricID string) (Rubric, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rubric, ok := s.rubrics[rubricID]
+	if !ok || rubric.ClassID != classID {
+		return Rubric{}, ErrRubricNotFound.With("rubricId", rubricID)
+	}
+	return cloneRubric(rubric), nil
+}
+
+// SaveRubric validates a rubric and creates or replaces it. Criteria without
+// an ID are numbered. Reviews already given keep the scores they had.
+func (s *Service) SaveRubric(ctx context.Context, rubric Rubric) (Rubric, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var problems []apperr.Problem
+	fail := func(path, code string, params ...any) {
+		problems = append(problems, apperr.NewProblem(path, code, params...))
+	}
+	if strings.TrimSpace(rubric.ID) == "" {
+		fail("id", "required")
+	}
+	if existing, ok := s.rubrics[rubric.ID]; ok && existing.ClassID != rubric.ClassID {
+		fail("id", "taken", "id", rubric.ID)
+	}
+	if strings.TrimSpace(rubric.Title) == "" {
+		fail("title", "required")
+	}
+	switch rubric.Kind {
+	case WorkLevel:
+		if _, ok := s.levels[rubric.LevelID]; !ok {
+			fail("levelId", "not_found", "id", rubric.LevelID)
+		}
+	case WorkProject:
+		rubric.LevelID = ""
+	default:
+		fail("kind", "unknown", "value", string(rubric.Kind))
+	}
+	if other := s.findRubric(rubric.ClassID, rubric.Kind, rubric.LevelID); other != nil && other.ID != rubric.ID {
+		fail("levelId", "has_rubric", "rubricId", other.ID)
+	}
+	if len(rubric.Criteria) == 0 {
+		fail("criteria", "required")
+	}
+	rubric.Criteria = append([]RubricCriterion(nil), rubric.Criteria...)
+	seen := make(map[string]bool, len(rubric.Criteria))
+	for i := range rubric.Criteria {
+		criterion := &rubric.Criteria[i]
+		if criterion.ID == "" {
+			criterion.ID = fmt.Sprintf("c%d", i+1)
+		}
+		path := fmt.Sprintf("criteria[%d]", i)
+		if seen[criterion.ID] {
+			fail(path+".id", "duplicate", "id", criterion.ID)
+		}
+		seen[criterion.ID] = true
+		if strings.TrimSpace(criterion.Title) == "" {
+			fail(path+".title", "required")
+		}
+		if criterion.Points < 1 || criterion.Points > maxCriterionPoints {
+			fail(path+".points", "out_of_range", "min", 1, "max", maxCriterionPoints)
+		}
+		if check := criterion.Check; check != nil {
+			switch check.Type {
+			case RubricCheckGoalMet:
+			case RubricCheckUsesBlock:
+				if !contains(knownBlocks, check.Block) {
+					fail(path+".check.block", "unknown_block", "block", check.Block)
+				}
+			case RubricCheckMaxBlocks, RubricCheckMaxSteps:
+				if check.Limit < 1 {
+					fail(path+".check.limit", "not_positive")
+				}
+			default:
+				fail(path+".check.type", "unknown", "value", string(check.Type))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return Rubric{}, ErrRubricInvalid.With("problems", problems)
+	}
+
+	rubric.UpdatedAt = time.Now().UnixMilli()
+	s.rubrics[rubric.ID] = cloneRubric(rubric)
+	return rubric, nil
+}
+
+// DeleteRubric removes a rubric of a class. Scores already given are kept on
+// the submissions.
+func (s *Service) DeleteRubric(ctx context.Context, classID, rubricID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rubric, ok := s.rubrics[rubricID]
+	if !ok || rubric.ClassID != classID {
+		return ErrRubricNotFound.With("rubricId", rubricID)
+	}
+	delete(s.rubrics, rubricID)
+	return nil
+}
+
+// Gradebook lists the latest reviewed rubric scores of the given students.
+func (s *Service) Gradebook(ctx context.Context, classID string, roster []ClassMember) Gradebook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	book := Gradebook{ClassID: classID, Rubrics: s.classRubrics(classID), Rows: make([]GradebookRow, 0, len(roster))}
+	for _, student := range roster {
+		row := GradebookRow{ClassMember: student, Cells: make([]GradebookCell, 0, len(book.Rubrics))}
+		for _, rubric := range book.Rubrics {
+			cell := GradebookCell{RubricID: rubric.ID, MaxPoints: rubric.MaxPoints()}
+			for _, work := range s.works {
+				if work.OwnerID != student.ID || work.ClassID != classID || !rubricMatches(rubric, work) {
+					continue
+				}
+				cell.WorkID = work.ID
+				cell.Status = work.Status
+				if score := latestRubricScore(work, rubric.ID); score != nil {
+					total := score.Total
+					cell.Total = &total
+					cell.MaxPoints = score.MaxPoints
+				}
+				break
+			}
+			if cell.Total != nil {
+				row.Total += *cell.Total
+				row.MaxPoints += cell.MaxPoints
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		book.Rows = append(book.Rows, row)
+	}
+	return book
+}
+
+// scoreWork scores a submission against the rubric for it, taking the
+// teacher's scores for the criteria they gave. It returns nil when the
+// submission has no rubric. Callers must hold the lock.
+func (s *Service) scoreWork(work *WorkSubmission, inputs []ScoreInput) (*RubricScore, []apperr.Problem) {
+	rubric := s.rubricFor(work)
+	if rubric == nil {
+		if len(inputs) > 0 {
+			return nil, []apperr.Problem{apperr.NewProblem("scores", "no_rubric")}
+		}
+		return nil, nil
+	}
+
+	var problems []apperr.Problem
+	given := make(map[string]ScoreInput, len(inputs))
+	for _, input := range inputs {
+		if _, dup := given[input.CriterionID]; dup {
+			problems = append(problems, apperr.NewProblem("scores", "duplicate", "criterionId", input.CriterionID))
+		}
+		given[input.CriterionID] = input
+	}
+
+	score := &RubricScore{RubricID: rubric.ID, Criteria: make([]CriterionScore, 0, len(rubric.Criteria)), Complete: true}
+	for _, criterion := range rubric.Criteria {
+		entry := CriterionScore{CriterionID: criterion.ID, Title: criterion.Title, MaxPoints: criterion.Points}
+		if criterion.Check != nil {
+			passed := evaluateCheck(*criterion.Check, work.Program, work.Result)
+			entry.Auto, entry.Passed, entry.Graded = true, &passed, true
+			if passed {
+				entry.Points = criterion.Points
+			}
+		}
+		if input, ok := given[criterion.ID]; ok {
+			delete(given, criterion.ID)
+			entry.Comment = strings.TrimSpace(input.Comment)
+			switch {
+			case input.Points == nil:
+				if !entry.Auto {
+					problems = append(problems, apperr.NewProblem("scores", "required", "criterionId", criterion.ID))
+				}
+			case *input.Points < 0 || *input.Points > criterion.Points:
+				problems = append(problems, apperr.NewProblem("scores", "out_of_range", "criterionId", criterion.ID, "min", 0, "max", criterion.Points))
+			default:
+				entry.Points, entry.Graded = *input.Points, true
+			}
+		}
+		if !entry.Graded {
+			score.Complete = false
+		}
+		score.Total += entry.Points
+		score.MaxPoints += criterion.Points
+		score.Criteria = append(score.Criteria, entry)
+	}
+	unknown := make([]string, 0, len(given))
+	for id := range given {
+		unknown = append(unknown, id)
+	}
+	sort.Strings(unknown)
+	for _, id := range unknown {
+		problems = append(problems, apperr.NewProblem("scores", "not_found", "criterionId", id))
+	}
+	return score, problems
+}
+
+// rubricFor returns the rubric that applies to a submission. Callers must
+// hold the lock.
+func (s *Service) rubricFor(work *WorkSubmission) *Rubric {
+	for _, rubric := range s.rubrics {
+		if rubric.ClassID == work.ClassID && rubricMatches(rubric, work) {
+			return &rubric
+		}
+	}
+	return nil
+}
+
+// findRubric returns the rubric of a class for a kind of work. Callers must
+// hold the lock.
+func (s *Service) findRubric(classID string, kind WorkKind, levelID string) *Rubric {
+	for _, rubric := range s.rubrics {
+		if rubric.ClassID == classID && rubric.Kind == kind && rubric.LevelID == levelID {
+			return &rubric
+		}
+	}
+	return nil
+}
+
+// classRubrics lists the rubrics of a class ordered by title. Callers must
+// hold the lock.
+func (s *Service) classRubrics(classID string) []Rubric {
+	rubrics := make([]Rubric, 0)
+	for _, rubric := range s.rubrics {
+		if rubric.ClassID == classID {
+			rubrics = append(rubrics, cloneRubric(rubric))
+		}
+	}
+	sort.Slice(rubrics, func(i, j int) bool {
+		if rubrics[i].Title != rubrics[j].Title {
+			return rubrics[i].Title < rubrics[j].Title
+		}
+		return rubrics[i].ID < rubrics[j].ID
+	})
+	return rubrics
+}
+
+func rubricMatches(rubric Rubric, work *WorkSubmission) bool {
+	if rubric.Kind != work.Kind {
+		return false
+	}
+	return rubric.Kind == WorkProject || rubric.LevelID == work.LevelID
+}
+
+// latestRubricScore returns the score of the most recent review against the
+// rubric.
+func latestRubricScore(work *WorkSubmission, rubricID string) *RubricScore {
+	for i := len(work.Reviews) - 1; i >= 0; i-- {
+		if score := work.Reviews[i].Score; score != nil && score.RubricID == rubricID {
+			return score
+		}
+	}
+	return nil
+}
+
+func evaluateCheck(check RubricCheck, program []Instruction, result WorkResult) bool {
+	switch check.Type {
+	case RubricCheckGoalMet:
+		return result.Success
+	case RubricCheckUsesBlock:
+		return programUsesBlock(program, check.Block)
+	case RubricCheckMaxBlocks:
+		return countBlocks(program) <= check.Limit
+	case RubricCheckMaxSteps:
+		return result.Success && result.Steps <= check.Limit
+	}
+	return false
+}
+
+// countBlocks counts the blocks of a program including nested ones.
+func countBlocks(program []Instruction) int {
+	count := 0
+	for _, instr := range program {
+		count += 1 + countBlocks(instr.Body) + countBlocks(instr.Truthy) + countBlocks(instr.Falsy)
+	}
+	return count
+}
+
+func cloneRubric(rubric Rubric) Rubric {
+	rubric.Criteria = append([]RubricCriterion(nil), rubric.Criteria...)
+	for i, criterion := range rubric.Criteria {
+		if criterion.Check != nil {
+			check := *criterion.Check
+			rubric.Criteria[i].Check = &check
+		}
+	}
+	return rubric
+}
+
+func cloneRubricScore(score *RubricScore) *RubricScore {
+	if score == nil {
+		return nil
+	}
+	clone := *score
+	clone.Criteria = append([]CriterionScore(nil), score.Criteria...)
+	return &clone
+}
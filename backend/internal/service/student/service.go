@@ -2,12 +2,13 @@ package student
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
 )
 
 type Direction string
@@ -62,7 +63,11 @@ type LevelDefinition struct {
 	Comic         string       `json:"comic,omitempty"`
 	Rewards       LevelRewards `json:"rewards,omitempty"`
 	ChapterID     string       `json:"chapterId"`
+	Revision      int          `json:"revision"`
 	Order         int          `json:"-"`
+	// Translations holds the text of the level in other locales, keyed by
+	// language tag such as "en".
+	Translations map[string]LevelText `json:"translations,omitempty"`
 }
 
 type ChapterDefinition struct {
@@ -71,6 +76,11 @@ type ChapterDefinition struct {
 	Summary string            `json:"summary"`
 	Order   int               `json:"order"`
 	Levels  []LevelDefinition `json:"levels"`
+	// OwnerID is set for chapters authored by a teacher. Such chapters are
+	// only visible to the classes listed in ClassIDs.
+	OwnerID      string                 `json:"ownerId,omitempty"`
+	ClassIDs     []string               `json:"classIds,omitempty"`
+	Translations map[string]ChapterText `json:"translations,omitempty"`
 }
 
 type LevelStatus string
@@ -88,17 +98,26 @@ type StudentLevelProgress struct {
 	Duration       int              `json:"duration"`
 	BestDifference *int             `json:"bestDifference,omitempty"`
 	CompletedAt    int64            `json:"completedAt"`
+	Revision       int              `json:"revision"`
 	ReplayLog      []SimulationStep `json:"replayLog,omitempty"`
 }
 
+// AvatarState lists the items a student owns and wears. Slots holds the
+// item equipped per slot; Equipped is the most recently equipped item.
 type AvatarState struct {
-	Equipped string   `json:"equipped"`
-	Unlocked []string `json:"unlocked"`
+	Equipped string                `json:"equipped"`
+	Unlocked []string              `json:"unlocked"`
+	Slots    map[AvatarSlot]string `json:"slots"`
+	Wallet   Wallet                `json:"wallet"`
 }
 
+// AchievementState lists the badges and compendium entries a student holds.
+// Badges holds badge IDs internally; Profile returns their display names
+// and the full badges in Earned.
 type AchievementState struct {
-	Badges     []string `json:"badges"`
-	Compendium []string `json:"compendium"`
+	Badges     []string      `json:"badges"`
+	Compendium []string      `json:"compendium"`
+	Earned     []EarnedBadge `json:"earned,omitempty"`
 }
 
 type StudentSettings struct {
@@ -118,6 +137,7 @@ type StudentProfile struct {
 	Settings        StudentSettings                 `json:"settings"`
 	SandboxUnlocked bool                            `json:"sandboxUnlocked"`
 	Progress        map[string]StudentLevelProgress `json:"progress"`
+	Activity        ActivitySummary                 `json:"activity"`
 }
 
 type MapLevel struct {
@@ -173,9 +193,13 @@ type SimulationStep struct {
 type SimulationMetadata struct {
 	BestSteps int       `json:"bestSteps"`
 	Goal      LevelGoal `json:"goal"`
+	Revision  int       `json:"revision"`
 }
 
+// SimulationResult is the outcome of running a program. Runs during an
+// assessment are Hidden: they carry no result, only the runs left.
 type SimulationResult struct {
+	AttemptID             string             `json:"attemptId,omitempty"`
 	Success               bool               `json:"success"`
 	Steps                 int                `json:"steps"`
 	Stars                 int                `json:"stars"`
@@ -183,6 +207,8 @@ type SimulationResult struct {
 	RemainingCollectibles int                `json:"remainingCollectibles,omitempty"`
 	Log                   []SimulationStep   `json:"log"`
 	Metadata              SimulationMetadata `json:"metadata"`
+	Hidden                bool               `json:"hidden,omitempty"`
+	RunsRemaining         *int               `json:"runsRemaining,omitempty"`
 }
 
 type HintRequest struct {
@@ -191,7 +217,52 @@ type HintRequest struct {
 }
 
 type HintResponse struct {
-	Hint string `json:"hint"`
+	Hint      string `json:"hint"`
+	HintsUsed int    `json:"hintsUsed"`
+	HintLimit int    `json:"hintLimit,omitempty"`
+	Remaining *int   `json:"remaining,omitempty"`
+}
+
+// HintUsage reports how many hints a student consumed on a level.
+type HintUsage struct {
+	LevelID    string `json:"levelId"`
+	Used       int    `json:"used"`
+	Limit      int    `json:"limit"`
+	LastUsedAt int64  `json:"lastUsedAt"`
+}
+
+// Errors returned by the play endpoints.
+var (
+	ErrLevelLocked        = apperr.New(apperr.KindForbidden, "level.locked")
+	ErrLevelMisconfigured = apperr.New(apperr.KindInternal, "level.misconfigured")
+	ErrEmptyProgram       = apperr.New(apperr.KindUnprocessable, "program.empty")
+	ErrBlockLocked        = apperr.New(apperr.KindUnprocessable, "program.block_locked")
+	ErrRepeatOutOfRange   = apperr.New(apperr.KindUnprocessable, "program.repeat_range")
+	ErrNestingTooDeep     = apperr.New(apperr.KindUnprocessable, "program.nesting_too_deep")
+	ErrHintLimitReached   = apperr.New(apperr.KindTooManyRequests, "hint.limit_reached")
+	ErrOutfitRequired     = apperr.New(apperr.KindInvalid, "avatar.outfit_required")
+	ErrOutfitLocked       = apperr.New(apperr.KindForbidden, "avatar.outfit_locked")
+)
+
+// HintLimitError is returned when a student exhausted the class hint limit
+// for a level and must wait before requesting another hint.
+type HintLimitError struct {
+	Limit      int
+	Window     time.Duration
+	NextHintAt time.Time
+}
+
+func (e *HintLimitError) Error() string {
+	return e.AppError().Error()
+}
+
+// AppError reports the limit and the time of the next allowed hint as details
+// of ErrHintLimitReached.
+func (e *HintLimitError) AppError() *apperr.Error {
+	return ErrHintLimitReached.
+		With("hintLimit", e.Limit).
+		With("windowMinutes", int(e.Window/time.Minute)).
+		With("nextHintAt", e.NextHintAt.UnixMilli())
 }
 
 type CompleteRequest struct {
@@ -209,8 +280,10 @@ type SettingsUpdate struct {
 	Language  *string `json:"language,omitempty"`
 }
 
+// AvatarUpdate equips an owned item in its slot, or empties the Unequip slot.
 type AvatarUpdate struct {
-	Equipped string `json:"equipped"`
+	Equipped string     `json:"equipped"`
+	Unequip  AvatarSlot `json:"unequip,omitempty"`
 }
 
 type Level struct {
@@ -219,25 +292,94 @@ type Level struct {
 	Progress *StudentLevelProgress `json:"progress,omitempty"`
 }
 
+// defaultHintWindow is the rolling window the class hint limit applies to
+// when the class does not set one. Once a student used up the limit on a
+// level, the oldest hint in the window has to expire before another one is
+// handed out.
+const defaultHintWindow = 30 * time.Minute
+
+type levelHintState struct {
+	used    int
+	history []time.Time
+}
+
 type Service struct {
-	mu       sync.RWMutex
-	chapters []ChapterDefinition
-	levels   map[string]LevelDefinition
-	profiles map[string]*StudentProfile
+	mu              sync.RWMutex
+	chapters        []ChapterDefinition
+	levels          map[string]LevelDefinition
+	profiles        map[string]*StudentProfile
+	enrollments     map[string]string
+	classHintLimits map[string]int
+	hintWindows     map[string]time.Duration
+	hints           map[string]map[string]*levelHintState
+	attempts        map[string]map[string][]Attempt
+	revisions       map[string][]LevelRevision
+	compendium      map[string]CompendiumEntry
+	badges          map[string]BadgeDefinition
+	earnedBadges    map[string]map[string]int64
+	completions     map[string][]completionEvent
+	activity        map[string]*activityState
+	avatarItems     map[string]AvatarItem
+	ledgers         map[string][]LedgerEntry
+	projects        map[string]*SandboxProject
+	works           map[string]*WorkSubmission
+	notifications   map[string][]Notification
+	rubrics         map[string]Rubric
+	assignments     map[string]*Assignment
+	assessments     map[string]map[string]*assessmentSession
+	observers       []ActivityObserver
+	controls        map[string]ClassControls
+	classUnlocks    map[string]ClassUnlocks
+	unlockOverrides map[string]map[string]int64
+	onControls      []ControlsObserver
 }
 
 func New() *Service {
 	chapters := defaultChapters()
 	levelIndex := make(map[string]LevelDefinition)
-	for _, chapter := range chapters {
-		for _, level := range chapter.Levels {
-			levelIndex[level.ID] = level
+	revisions := make(map[string][]LevelRevision)
+	seededAt := time.Now().UnixMilli()
+	for ci := range chapters {
+		for li := range chapters[ci].Levels {
+			level := &chapters[ci].Levels[li]
+			level.Revision = 1
+			levelIndex[level.ID] = *level
+			revisions[level.ID] = []LevelRevision{{
+				LevelID:     level.ID,
+				Revision:    1,
+				Status:      RevisionStatusPublished,
+				Level:       *level,
+				CreatedAt:   seededAt,
+				PublishedAt: seededAt,
+			}}
 		}
 	}
 	return &Service{
-		chapters: chapters,
-		levels:   levelIndex,
-		profiles: make(map[string]*StudentProfile),
+		chapters:        chapters,
+		levels:          levelIndex,
+		profiles:        make(map[string]*StudentProfile),
+		enrollments:     make(map[string]string),
+		classHintLimits: make(map[string]int),
+		hintWindows:     make(map[string]time.Duration),
+		controls:        make(map[string]ClassControls),
+		classUnlocks:    make(map[string]ClassUnlocks),
+		unlockOverrides: make(map[string]map[string]int64),
+		hints:           make(map[string]map[string]*levelHintState),
+		attempts:        make(map[string]map[string][]Attempt),
+		revisions:       revisions,
+		compendium:      defaultCompendium(),
+		badges:          defaultBadges(),
+		earnedBadges:    make(map[string]map[string]int64),
+		completions:     make(map[string][]completionEvent),
+		activity:        make(map[string]*activityState),
+		avatarItems:     defaultAvatarItems(),
+		ledgers:         make(map[string][]LedgerEntry),
+		projects:        make(map[string]*SandboxProject),
+		works:           make(map[string]*WorkSubmission),
+		notifications:   make(map[string][]Notification),
+		rubrics:         make(map[string]Rubric),
+		assignments:     make(map[string]*Assignment),
+		assessments:     make(map[string]map[string]*assessmentSession),
 	}
 }
 
@@ -247,8 +389,12 @@ func (s *Service) Map(ctx context.Context, userID string) (Map, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	locale := profile.Settings.Language
 	chapters := make([]MapChapter, 0, len(s.chapters))
 	for _, chapter := range s.chapters {
+		if !chapterVisible(chapter, profile.ClassID) {
+			continue
+		}
 		levels := make([]MapLevel, 0, len(chapter.Levels))
 		for idx, levelDef := range chapter.Levels {
 			progress, ok := profile.Progress[levelDef.ID]
@@ -267,17 +413,18 @@ func (s *Service) Map(ctx context.Context, userID string) (Map, error) {
 			rewards := levelDef.Rewards
 			levels = append(levels, MapLevel{
 				ID:             levelDef.ID,
-				Name:           levelDef.Name,
+				Name:           localizeLevel(levelDef, locale).Name,
 				Status:         status,
 				Stars:          progress.Stars,
 				BestDifference: bestDiff,
 				Rewards:        nullableRewards(rewards),
 			})
 		}
+		title, summary := localizeChapter(chapter, locale)
 		chapters = append(chapters, MapChapter{
 			ID:      chapter.ID,
-			Title:   chapter.Title,
-			Summary: chapter.Summary,
+			Title:   title,
+			Summary: summary,
 			Order:   chapter.Order,
 			Levels:  levels,
 		})
@@ -300,7 +447,17 @@ func (s *Service) Profile(ctx context.Context, userID string) (StudentProfile, e
 	defer s.mu.Unlock()
 
 	s.recomputeDerivedState(profile)
-	return copyProfile(profile), nil
+	clone := copyProfile(profile)
+	clone.Avatar = s.avatarState(profile)
+	clone.Activity = s.activitySummary(profile.ID, time.Now())
+	clone.Achievements.Badges = make([]string, 0, len(profile.Achievements.Badges))
+	clone.Achievements.Earned = make([]EarnedBadge, 0, len(profile.Achievements.Badges))
+	for _, id := range profile.Achievements.Badges {
+		badge := s.earnedBadge(id, s.earnedBadges[profile.ID][id], profile.Settings.Language)
+		clone.Achievements.Badges = append(clone.Achievements.Badges, badge.Name)
+		clone.Achievements.Earned = append(clone.Achievements.Earned, badge)
+	}
+	return clone, nil
 }
 
 func (s *Service) Level(ctx context.Context, userID, levelID string) (Level, error) {
@@ -310,23 +467,27 @@ func (s *Service) Level(ctx context.Context, userID, levelID string) (Level, err
 	defer s.mu.RUnlock()
 
 	levelDef, ok := s.levels[levelID]
-	if !ok {
-		return Level{}, fmt.Errorf("关卡 %s 不存在", levelID)
+	if !ok || !s.levelVisible(profile, levelDef) {
+		return Level{}, ErrLevelNotFound.With("levelId", levelID)
+	}
+
+	if err := s.checkLevelRestriction(profile, levelDef); err != nil {
+		return Level{}, err
 	}
 
 	chapter, _ := s.findChapter(levelDef.ChapterID)
 	if chapter == nil {
-		return Level{}, fmt.Errorf("关卡 %s 未绑定章节", levelID)
+		return Level{}, ErrLevelMisconfigured.With("levelId", levelID)
 	}
 
 	levelIdx := s.findLevelIndex(*chapter, levelID)
 	if levelIdx < 0 {
-		return Level{}, fmt.Errorf("关卡 %s 未绑定章节", levelID)
+		return Level{}, ErrLevelMisconfigured.With("levelId", levelID)
 	}
 
 	progress, completed := profile.Progress[levelID]
 	if !completed && !s.isLevelUnlocked(profile, chapter.ID, levelIdx) {
-		return Level{}, fmt.Errorf("关卡未解锁，完成上一关后再试试吧")
+		return Level{}, ErrLevelLocked.With("levelId", levelID)
 	}
 
 	status := LevelStatusUnlocked
@@ -335,7 +496,7 @@ func (s *Service) Level(ctx context.Context, userID, levelID string) (Level, err
 	}
 
 	result := Level{
-		LevelDefinition: levelDef,
+		LevelDefinition: localizeLevel(levelDef, profile.Settings.Language),
 		Status:          status,
 	}
 	if completed {
@@ -346,13 +507,16 @@ func (s *Service) Level(ctx context.Context, userID, levelID string) (Level, err
 }
 
 func (s *Service) Prep(ctx context.Context, userID, levelID string) (Prep, error) {
+	profile := s.ensureProfile(userID)
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	levelDef, ok := s.levels[levelID]
-	if !ok {
-		return Prep{}, fmt.Errorf("关卡 %s 不存在", levelID)
+	if !ok || !s.levelVisible(profile, levelDef) {
+		return Prep{}, ErrLevelNotFound.With("levelId", levelID)
 	}
+	levelDef = localizeLevel(levelDef, profile.Settings.Language)
 
 	return Prep{
 		LevelID:          levelDef.ID,
@@ -363,46 +527,116 @@ func (s *Service) Prep(ctx context.Context, userID, levelID string) (Prep, error
 	}, nil
 }
 
-func (s *Service) Run(ctx context.Context, userID, levelID string, program []Instruction) (SimulationResult, error) {
-	level, ok := s.levels[levelID]
-	if !ok {
-		return SimulationResult{}, fmt.Errorf("关卡 %s 不存在", levelID)
+func (s *Service) Run(ctx context.Context, userID, levelID string, req RunRequest) (SimulationResult, error) {
+	profile := s.ensureProfile(userID)
+
+	program, err := ProgramFromSource(req.Program, req.Source)
+	if err != nil {
+		return SimulationResult{}, err
 	}
+	req.Program = program
 
-	if err := validateProgram(level, program); err != nil {
+	response, level, result, err := s.recordRun(profile, levelID, req)
+	if err != nil {
 		return SimulationResult{}, err
 	}
+	// Classifying simulates variants of the program, so it runs on the copied
+	// level without the lock and the attempt is tagged afterwards.
+	if kinds := classifyRun(level, req.Program, result); len(kinds) > 0 {
+		s.tagAttempt(profile.ID, levelID, result.AttemptID, kinds)
+	}
+	return response, nil
+}
+
+// recordRun simulates a run of a level and records it as an attempt. It
+// returns the response for the student, which hides the outcome during an
+// assessment, along with the level and the full result of the run.
+func (s *Service) recordRun(profile *StudentProfile, levelID string, req RunRequest) (SimulationResult, LevelDefinition, SimulationResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	level, ok := s.levels[levelID]
+	if !ok || !s.levelVisible(profile, level) {
+		return SimulationResult{}, LevelDefinition{}, SimulationResult{}, ErrLevelNotFound.With("levelId", levelID)
+	}
+	if err := s.checkRunAllowed(profile, false); err != nil {
+		return SimulationResult{}, LevelDefinition{}, SimulationResult{}, err
+	}
+	if err := s.checkLevelRestriction(profile, level); err != nil {
+		return SimulationResult{}, LevelDefinition{}, SimulationResult{}, err
+	}
+
+	if err := validateProgram(level, req.Program); err != nil {
+		return SimulationResult{}, LevelDefinition{}, SimulationResult{}, err
+	}
+
+	now := time.Now()
+	assessment, session, err := s.checkAssessmentRun(profile, levelID, now.UnixMilli())
+	if err != nil {
+		return SimulationResult{}, LevelDefinition{}, SimulationResult{}, err
+	}
+	s.recordActivity(profile.ID, now)
 	simulator := newSimulator(level)
-	return simulator.run(program), nil
+	result := simulator.run(req.Program)
+	attempt := s.recordAttempt(profile.ID, level, req, result, now)
+	result.AttemptID = attempt.ID
+	s.emitActivity(profile, ActivityEvent{Type: ActivityRun, LevelID: levelID, Success: attempt.Success, Stars: attempt.Stars, ErrorCode: attempt.ErrorCode}, now)
+	if session != nil {
+		return hiddenResult(result, assessment, session, levelID), level, result, nil
+	}
+	return result, level, result, nil
 }
 
 func (s *Service) Sandbox(ctx context.Context, userID, levelID string, program []Instruction) (SimulationResult, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	level, ok := s.levels[levelID]
-	if !ok {
-		return SimulationResult{}, fmt.Errorf("关卡 %s 不存在", levelID)
+	if !ok || !s.levelVisible(profile, level) {
+		return SimulationResult{}, ErrLevelNotFound.With("levelId", levelID)
+	}
+	if err := s.checkRunAllowed(profile, true); err != nil {
+		return SimulationResult{}, err
+	}
+	if err := s.checkLevelRestriction(profile, level); err != nil {
+		return SimulationResult{}, err
+	}
+	if err := s.checkAssessmentFeedback(profile, levelID, time.Now().UnixMilli(), ErrAssessmentInProgress); err != nil {
+		return SimulationResult{}, err
 	}
 	simulator := newSimulator(level)
 	return simulator.run(program), nil
 }
 
-func (s *Service) Complete(ctx context.Context, userID, levelID string, req CompleteRequest) error {
+// Complete records a level completion and evaluates the badge rules. The
+// result lists the badges and compendium entries earned by it.
+func (s *Service) Complete(ctx context.Context, userID, levelID string, req CompleteRequest) (CompleteResult, error) {
 	profile := s.ensureProfile(userID)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	levelDef, ok := s.levels[levelID]
-	if !ok {
-		return fmt.Errorf("关卡 %s 不存在", levelID)
+	if !ok || !s.levelVisible(profile, levelDef) {
+		return CompleteResult{}, ErrLevelNotFound.With("levelId", levelID)
+	}
+	if err := s.checkAssessmentFeedback(profile, levelID, time.Now().UnixMilli(), ErrAssessmentInProgress); err != nil {
+		return CompleteResult{}, err
 	}
 
 	progress := profile.Progress[levelID]
+	previousStars := progress.Stars
 	if req.Stars > progress.Stars {
 		progress.Stars = req.Stars
 	}
 	progress.Steps = req.Steps
-	if req.Hints != nil {
+	// Hints are counted server-side by Hint; the client-reported value is
+	// only used for levels that never requested one through the API.
+	if state := s.hints[profile.ID][levelID]; state != nil {
+		progress.Hints = state.used
+	} else if req.Hints != nil {
 		progress.Hints = *req.Hints
 	}
 	if req.Duration != nil {
@@ -412,7 +646,9 @@ func (s *Service) Complete(ctx context.Context, userID, levelID string, req Comp
 		diff := *req.BestDifference
 		progress.BestDifference = &diff
 	}
-	progress.CompletedAt = time.Now().UnixMilli()
+	now := time.Now()
+	progress.CompletedAt = now.UnixMilli()
+	progress.Revision = levelDef.Revision
 	if len(req.ReplayLog) > 0 {
 		progress.ReplayLog = make([]SimulationStep, len(req.ReplayLog))
 		copy(progress.ReplayLog, req.ReplayLog)
@@ -420,22 +656,203 @@ func (s *Service) Complete(ctx context.Context, userID, levelID string, req Comp
 
 	profile.Progress[levelID] = progress
 
-	if levelDef.Rewards.Outfit != "" && req.Stars >= levelDef.Rewards.Stars {
-		unlockAvatar(profile, levelDef.Rewards.Outfit)
+	if outfit := levelDef.Rewards.Outfit; outfit != "" && req.Stars >= levelDef.Rewards.Stars && !contains(profile.Avatar.Unlocked, outfit) {
+		unlockAvatar(profile, outfit)
+		s.postLedger(profile.ID, LedgerEntry{Key: "reward:" + outfit, Kind: LedgerReward, ItemID: outfit, Reason: levelID}, now)
 	}
+	s.creditCompletion(profile.ID, levelID, previousStars, progress.Stars, now)
 
 	s.recomputeDerivedState(profile)
-	return nil
+	s.recordActivity(profile.ID, now)
+	s.recordCompletion(profile.ID, completionEvent{levelID: levelID, stars: req.Stars, hints: progress.Hints, at: now})
+	s.emitActivity(profile, ActivityEvent{Type: ActivityComplete, LevelID: levelID, Success: true, Stars: progress.Stars}, now)
+	return s.awardAchievements(profile, now), nil
 }
 
 func (s *Service) Hint(ctx context.Context, userID, levelID string, payload HintRequest) (HintResponse, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	level, ok := s.levels[levelID]
-	if !ok {
-		return HintResponse{}, fmt.Errorf("关卡 %s 不存在", levelID)
+	if !ok || !s.levelVisible(profile, level) {
+		return HintResponse{}, ErrLevelNotFound.With("levelId", levelID)
+	}
+
+	now := time.Now()
+	if err := s.checkAssessmentFeedback(profile, levelID, now.UnixMilli(), ErrAssessmentHintsDisabled); err != nil {
+		return HintResponse{}, err
+	}
+	state := s.hintState(profile.ID, levelID)
+	window := s.hintWindow(profile.ClassID)
+	state.history = hintsWithinWindow(state.history, now, window)
+
+	limit := s.classHintLimits[profile.ClassID]
+	if limit > 0 && len(state.history) >= limit {
+		return HintResponse{}, &HintLimitError{Limit: limit, Window: window, NextHintAt: state.history[0].Add(window)}
+	}
+
+	state.used++
+	state.history = append(state.history, now)
+	s.recordActivity(profile.ID, now)
+	if progress, ok := profile.Progress[levelID]; ok {
+		progress.Hints = state.used
+		profile.Progress[levelID] = progress
+	}
+	s.emitActivity(profile, ActivityEvent{Type: ActivityHint, LevelID: levelID}, now)
+
+	hint := computeHint(localizeLevel(level, profile.Settings.Language), payload, profile.Settings.Language)
+	if kind, ok := s.recentMisconception(profile.ID, levelID); ok {
+		hint = builtInString(profile.Settings.Language, "hint.misconception."+string(kind))
+	}
+	response := HintResponse{
+		Hint:      hint,
+		HintsUsed: state.used,
+		HintLimit: limit,
+	}
+	if limit > 0 {
+		remaining := limit - len(state.history)
+		response.Remaining = &remaining
+	}
+	return response, nil
+}
+
+// HintUsage returns per-level hint consumption for a student, ordered by level.
+func (s *Service) HintUsage(ctx context.Context, studentID string) ([]HintUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	classID := s.enrollments[studentID]
+	if profile, ok := s.profiles[studentID]; ok {
+		classID = profile.ClassID
 	}
+	limit := s.classHintLimits[classID]
 
-	hint := computeHint(level, payload)
-	return HintResponse{Hint: hint}, nil
+	levels := s.hints[studentID]
+	usage := make([]HintUsage, 0, len(levels))
+	for levelID, state := range levels {
+		entry := HintUsage{LevelID: levelID, Used: state.used, Limit: limit}
+		if n := len(state.history); n > 0 {
+			entry.LastUsedAt = state.history[n-1].UnixMilli()
+		}
+		usage = append(usage, entry)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].LevelID < usage[j].LevelID })
+	return usage, nil
+}
+
+// ConfigureClass records class level policies enforced for enrolled students.
+// A hint limit of zero disables the limit and a zero hint window uses the
+// default of 30 minutes.
+func (s *Service) ConfigureClass(ctx context.Context, classID string, hintLimit int, hintWindow time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.classHintLimits[classID] = hintLimit
+	if hintWindow > 0 {
+		s.hintWindows[classID] = hintWindow
+	} else {
+		delete(s.hintWindows, classID)
+	}
+}
+
+// hintWindow returns the window the hint limit of a class applies to.
+// Callers must hold the lock.
+func (s *Service) hintWindow(classID string) time.Duration {
+	if window, ok := s.hintWindows[classID]; ok {
+		return window
+	}
+	return defaultHintWindow
+}
+
+// EnrollStudent binds a student to a class so class policies apply to them.
+func (s *Service) EnrollStudent(ctx context.Context, studentID, classID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enrollments[studentID] = classID
+	if profile, ok := s.profiles[studentID]; ok {
+		profile.ClassID = classID
+	}
+}
+
+// WithdrawStudent removes a student from their class. The student keeps their
+// progress and only sees the built-in chapters until they join another class.
+func (s *Service) WithdrawStudent(ctx context.Context, studentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.enrollments, studentID)
+	if profile, ok := s.profiles[studentID]; ok {
+		profile.ClassID = ""
+	}
+}
+
+// ProgressSummary is how far a student got on a set of levels.
+type ProgressSummary struct {
+	CompletedLevels int
+	Stars           int
+	LastCompletedAt int64
+}
+
+// ProgressSummaries sums the recorded progress of each student on the given
+// levels. Students without a profile get an empty summary.
+func (s *Service) ProgressSummaries(ctx context.Context, studentIDs, levelIDs []string) map[string]ProgressSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := make(map[string]ProgressSummary, len(studentIDs))
+	for _, studentID := range studentIDs {
+		var summary ProgressSummary
+		if profile, ok := s.profiles[studentID]; ok {
+			for _, levelID := range levelIDs {
+				progress, ok := profile.Progress[levelID]
+				if !ok || progress.Stars == 0 {
+					continue
+				}
+				summary.CompletedLevels++
+				summary.Stars += progress.Stars
+				if progress.CompletedAt > summary.LastCompletedAt {
+					summary.LastCompletedAt = progress.CompletedAt
+				}
+			}
+		}
+		summaries[studentID] = summary
+	}
+	return summaries
+}
+
+// LevelProgressRow is the progress of a student on one level together with
+// the level name, as listed in exports.
+type LevelProgressRow struct {
+	LevelID   string
+	LevelName string
+	StudentLevelProgress
+}
+
+// ProgressRows lists the recorded progress of a student in level order.
+// Students without a profile have no rows.
+func (s *Service) ProgressRows(ctx context.Context, studentID string) []LevelProgressRow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profile, ok := s.profiles[studentID]
+	if !ok {
+		return []LevelProgressRow{}
+	}
+	rows := make([]LevelProgressRow, 0, len(profile.Progress))
+	for levelID, progress := range profile.Progress {
+		row := LevelProgressRow{LevelID: levelID, LevelName: levelID, StudentLevelProgress: progress}
+		if level, ok := s.levels[levelID]; ok {
+			row.LevelName = level.Name
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if oi, oj := s.levelOrder(rows[i].LevelID), s.levelOrder(rows[j].LevelID); oi != oj {
+			return oi < oj
+		}
+		return rows[i].LevelID < rows[j].LevelID
+	})
+	return rows
 }
 
 func (s *Service) Settings(ctx context.Context, userID string) (StudentSettings, error) {
@@ -445,6 +862,18 @@ func (s *Service) Settings(ctx context.Context, userID string) (StudentSettings,
 	return profile.Settings, nil
 }
 
+// Language returns the language a student chose in their settings, or ""
+// for students without a profile. Unlike Settings it never creates one.
+func (s *Service) Language(ctx context.Context, userID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if profile, ok := s.profiles[userID]; ok {
+		return profile.Settings.Language
+	}
+	return ""
+}
+
 func (s *Service) UpdateSettings(ctx context.Context, userID string, update SettingsUpdate) (StudentSettings, error) {
 	profile := s.ensureProfile(userID)
 	s.mu.Lock()
@@ -464,21 +893,39 @@ func (s *Service) UpdateSettings(ctx context.Context, userID string, update Sett
 		profile.Settings.LowMotion = *update.LowMotion
 	}
 	if update.Language != nil && strings.TrimSpace(*update.Language) != "" {
-		profile.Settings.Language = *update.Language
+		language := strings.TrimSpace(*update.Language)
+		if apperr.MatchLanguage(language) == "" {
+			return profile.Settings, ErrUnsupportedLanguage.With("language", language)
+		}
+		profile.Settings.Language = language
 	}
 
 	return profile.Settings, nil
 }
 
+// ResetProgress clears the level progress of a student together with the
+// attempts, badges and level rewards that came from it. Hint usage is kept so
+// a reset does not lift the hint limit of the class within its window. The
+// ledger is kept too: the wallet, the purchase history and its idempotency
+// keys survive, items bought or granted stay unlocked, and levels cleared
+// again do not pay out a second time.
 func (s *Service) ResetProgress(ctx context.Context, userID string) error {
 	profile := s.ensureProfile(userID)
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	profile.Progress = make(map[string]StudentLevelProgress)
+	delete(s.attempts, profile.ID)
 	profile.SandboxUnlocked = false
 	profile.Achievements = AchievementState{}
+	delete(s.earnedBadges, profile.ID)
+	delete(s.completions, profile.ID)
 	profile.Avatar = defaultAvatarState()
+	for _, entry := range s.ledgers[profile.ID] {
+		if entry.Kind == LedgerSpend || entry.Kind == LedgerGrant {
+			unlockAvatar(profile, entry.ItemID)
+		}
+	}
 	return nil
 }
 
@@ -486,7 +933,7 @@ func (s *Service) Avatar(ctx context.Context, userID string) (AvatarState, error
 	profile := s.ensureProfile(userID)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return profile.Avatar, nil
+	return s.avatarState(profile), nil
 }
 
 func (s *Service) UpdateAvatar(ctx context.Context, userID string, update AvatarUpdate) (AvatarState, error) {
@@ -494,16 +941,21 @@ func (s *Service) UpdateAvatar(ctx context.Context, userID string, update Avatar
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if update.Unequip != "" && update.Equipped == "" {
+		delete(profile.Avatar.Slots, update.Unequip)
+		return s.avatarState(profile), nil
+	}
 	if update.Equipped == "" {
-		return profile.Avatar, errors.New("请选择要装备的装扮")
+		return s.avatarState(profile), ErrOutfitRequired
 	}
 
 	if !contains(profile.Avatar.Unlocked, update.Equipped) {
-		return profile.Avatar, fmt.Errorf("装扮 %s 尚未解锁", update.Equipped)
+		return s.avatarState(profile), ErrOutfitLocked.With("outfit", update.Equipped)
 	}
 
 	profile.Avatar.Equipped = update.Equipped
-	return profile.Avatar, nil
+	profile.Avatar.Slots[s.itemSlot(update.Equipped)] = update.Equipped
+	return s.avatarState(profile), nil
 }
 
 func (s *Service) ensureProfile(userID string) *StudentProfile {
@@ -516,11 +968,15 @@ func (s *Service) ensureProfile(userID string) *StudentProfile {
 
 	profile, ok := s.profiles[userID]
 	if !ok {
+		classID, enrolled := s.enrollments[userID]
+		if !enrolled {
+			classID = "class-demo"
+		}
 		profile = &StudentProfile{
 			ID:           userID,
 			Name:         "小冒险家",
 			Role:         "student",
-			ClassID:      "class-demo",
+			ClassID:      classID,
 			Avatar:       defaultAvatarState(),
 			Achievements: AchievementState{},
 			Settings: StudentSettings{
@@ -539,39 +995,15 @@ func (s *Service) ensureProfile(userID string) *StudentProfile {
 }
 
 func (s *Service) recomputeDerivedState(profile *StudentProfile) {
-	totalLevels := 0
-	completedLevels := 0
-	totalStars := 0
-	for _, chapter := range s.chapters {
-		totalLevels += len(chapter.Levels)
+	profile.SandboxUnlocked = false
+	for _, chapter := range s.visibleChapters(profile) {
 		for _, level := range chapter.Levels {
 			if progress, ok := profile.Progress[level.ID]; ok && progress.Stars > 0 {
-				completedLevels++
-				totalStars += progress.Stars
+				profile.SandboxUnlocked = true
+				return
 			}
 		}
 	}
-
-	if completedLevels > 0 {
-		profile.SandboxUnlocked = true
-	} else {
-		profile.SandboxUnlocked = false
-	}
-
-	profile.Achievements = computeAchievements(profile.Progress, totalLevels, totalStars)
-}
-
-func (s *Service) isLevelUnlocked(profile *StudentProfile, chapterID string, levelIndex int) bool {
-	if levelIndex == 0 {
-		return true
-	}
-	chapter, _ := s.findChapter(chapterID)
-	if chapter == nil {
-		return false
-	}
-	previousLevel := chapter.Levels[levelIndex-1]
-	progress, ok := profile.Progress[previousLevel.ID]
-	return ok && progress.Stars > 0
 }
 
 func (s *Service) findChapter(chapterID string) (*ChapterDefinition, int) {
@@ -600,6 +1032,29 @@ func (s *Service) levelOrder(levelID string) int {
 	return level.Order
 }
 
+func (s *Service) hintState(userID, levelID string) *levelHintState {
+	levels, ok := s.hints[userID]
+	if !ok {
+		levels = make(map[string]*levelHintState)
+		s.hints[userID] = levels
+	}
+	state, ok := levels[levelID]
+	if !ok {
+		state = &levelHintState{}
+		levels[levelID] = state
+	}
+	return state
+}
+
+func hintsWithinWindow(history []time.Time, now time.Time, window time.Duration) []time.Time {
+	cutoff := now.Add(-window)
+	idx := 0
+	for idx < len(history) && !history[idx].After(cutoff) {
+		idx++
+	}
+	return history[idx:]
+}
+
 func nullableRewards(rewards LevelRewards) *LevelRewards {
 	if rewards == (LevelRewards{}) {
 		return nil
@@ -617,6 +1072,10 @@ func copyProfile(profile *StudentProfile) StudentProfile {
 	clone.Avatar = AvatarState{
 		Equipped: profile.Avatar.Equipped,
 		Unlocked: append([]string{}, profile.Avatar.Unlocked...),
+		Slots:    make(map[AvatarSlot]string, len(profile.Avatar.Slots)),
+	}
+	for slot, itemID := range profile.Avatar.Slots {
+		clone.Avatar.Slots[slot] = itemID
 	}
 	clone.Achievements = AchievementState{
 		Badges:     append([]string{}, profile.Achievements.Badges...),
@@ -629,6 +1088,7 @@ func defaultAvatarState() AvatarState {
 	return AvatarState{
 		Equipped: "冒险家头盔",
 		Unlocked: []string{"冒险家头盔"},
+		Slots:    map[AvatarSlot]string{AvatarSlotHead: "冒险家头盔"},
 	}
 }
 
@@ -650,78 +1110,48 @@ func contains(list []string, target string) bool {
 	return false
 }
 
-func computeAchievements(progress map[string]StudentLevelProgress, totalLevels int, totalStars int) AchievementState {
-	badges := []string{}
-	compendium := []string{}
-
-	completedCount := 0
-	perfectRuns := 0
-	for levelID, entry := range progress {
-		if entry.Stars > 0 {
-			completedCount++
-		}
-		if entry.Stars == 3 {
-			perfectRuns++
-			compendium = append(compendium, fmt.Sprintf("%s-3star", levelID))
-		}
-	}
-
-	if completedCount >= 1 {
-		badges = append(badges, "初次通关")
-	}
-	if completedCount >= 5 {
-		badges = append(badges, "冒险旅人")
-	}
-	if perfectRuns >= 3 {
-		badges = append(badges, "完美主义者")
-	}
-	if totalLevels > 0 && completedCount == totalLevels {
-		badges = append(badges, "章节掌控者")
-	}
-	if totalStars >= 30 {
-		badges = append(badges, "星星收藏家")
-	}
-
-	sort.Strings(badges)
-	sort.Strings(compendium)
-
-	return AchievementState{Badges: badges, Compendium: compendium}
-}
-
+// validateProgram checks a block program against the blocks the level allows
+// and the limits of the text form, so that a program is accepted exactly when
+// it could also have been written as text: repeat counts run from zero to
+// maxRepeatTimes and blocks nest at most maxLoopDepth deep.
 func validateProgram(level LevelDefinition, program []Instruction) error {
 	if len(program) == 0 {
-		return errors.New("请先添加积木再运行程序")
-	}
-	if len(level.AllowedBlocks) == 0 {
-		return nil
+		return ErrEmptyProgram
 	}
 
-	allowed := make(map[string]struct{}, len(level.AllowedBlocks))
-	for _, block := range level.AllowedBlocks {
-		allowed[block] = struct{}{}
+	var allowed map[string]struct{}
+	if len(level.AllowedBlocks) > 0 {
+		allowed = make(map[string]struct{}, len(level.AllowedBlocks))
+		for _, block := range level.AllowedBlocks {
+			allowed[block] = struct{}{}
+		}
 	}
 
-	var validate func(instr Instruction) error
-	validate = func(instr Instruction) error {
-		blockCode := blockCodeForInstruction(instr)
-		if _, ok := allowed[blockCode]; !ok {
-			return fmt.Errorf("积木 %s 尚未解锁", blockCode)
+	var validate func(instructions []Instruction, depth int) error
+	validate = func(instructions []Instruction, depth int) error {
+		if depth > maxLoopDepth {
+			return ErrNestingTooDeep.With("max", maxLoopDepth)
 		}
-		switch instr.Type {
-		case "repeat":
-			for _, child := range instr.Body {
-				if err := validate(child); err != nil {
-					return err
+		for _, instr := range instructions {
+			if allowed != nil {
+				blockCode := blockCodeForInstruction(instr)
+				if _, ok := allowed[blockCode]; !ok {
+					return ErrBlockLocked.With("block", blockCode)
 				}
 			}
-		case "conditional":
-			for _, child := range instr.Truthy {
-				if err := validate(child); err != nil {
+			switch instr.Type {
+			case "repeat":
+				if instr.Times < 0 || instr.Times > maxRepeatTimes {
+					return ErrRepeatOutOfRange.With("times", instr.Times).With("max", maxRepeatTimes)
+				}
+				if err := validate(instr.Body, depth+1); err != nil {
 					return err
 				}
-			}
-			for _, child := range instr.Falsy {
-				if err := validate(child); err != nil {
+			case "conditional":
+				if err := validate(instr.Truthy, depth+1); err != nil {
+					return err
+				}
+				if err := validate(instr.Falsy, depth+1); err != nil {
 					return err
 				}
 			}
@@ -729,13 +1159,7 @@ func validateProgram(level LevelDefinition, program []Instruction) error {
 		return nil
 	}
 
-	for _, instruction := range program {
-		if err := validate(instruction); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return validate(program, 0)
 }
 
 func blockCodeForInstruction(instr Instruction) string {
@@ -758,8 +1182,12 @@ func blockCodeForInstruction(instr Instruction) string {
 	}
 }
 
+// maxLoopDepth is how deeply repeat and conditional blocks may nest.
+const maxLoopDepth = 10
+
 type simulator struct {
-	level LevelDefinition
+	level  LevelDefinition
+	frames []ReplayFrame
 }
 
 func newSimulator(level LevelDefinition) *simulator {
@@ -767,7 +1195,7 @@ func newSimulator(level LevelDefinition) *simulator {
 }
 
 func (s *simulator) run(program []Instruction) SimulationResult {
-	stepLimit := 200
+	stepLimit := defaultStepLimit
 	if s.level.Goal.StepLimit != nil {
 		stepLimit = *s.level.Goal.StepLimit
 	}
@@ -786,7 +1214,7 @@ func (s *simulator) run(program []Instruction) SimulationResult {
 
 	var execute func([]Instruction, int) string
 	execute = func(instructions []Instruction, depth int) string {
-		if depth > 10 {
+		if depth > maxLoopDepth {
 			return "E_LOOP_DEPTH"
 		}
 		for _, instruction := range instructions {
@@ -804,12 +1232,15 @@ func (s *simulator) run(program []Instruction) SimulationResult {
 			case "move":
 				next := moveForward(position)
 				if !s.isWalkable(next.X, next.Y) {
+					s.recordFrame(steps, instruction, position, collectibles, "E_COLLIDE")
 					return "E_COLLIDE"
 				}
 				position.X = next.X
 				position.Y = next.Y
+				s.recordFrame(steps, instruction, position, collectibles, "")
 			case "turn":
 				position.Facing = rotate(position.Facing, instruction.Direction)
+				s.recordFrame(steps, instruction, position, collectibles, "")
 			case "collect":
 				if collectible := s.collectibleAt(position.X, position.Y); collectible != "" {
 					key := fmt.Sprintf("%d:%d:%s", position.X, position.Y, collectible)
@@ -818,6 +1249,7 @@ func (s *simulator) run(program []Instruction) SimulationResult {
 						collectibles--
 					}
 				}
+				s.recordFrame(steps, instruction, position, collectibles, "")
 			case "repeat":
 				for i := 0; i < instruction.Times; i++ {
 					if errCode := execute(instruction.Body, depth+1); errCode != "" {
@@ -858,6 +1290,7 @@ func (s *simulator) run(program []Instruction) SimulationResult {
 		Metadata: SimulationMetadata{
 			BestSteps: s.level.BestSteps,
 			Goal:      s.level.Goal,
+			Revision:  s.level.Revision,
 		},
 		RemainingCollectibles: collectibles,
 	}
@@ -867,6 +1300,18 @@ func (s *simulator) run(program []Instruction) SimulationResult {
 	return result
 }
 
+// recordFrame captures the world state after a primitive instruction ran.
+func (s *simulator) recordFrame(step int, instruction Instruction, position Position, collectibles int, errorCode string) {
+	s.frames = append(s.frames, ReplayFrame{
+		Index:        len(s.frames),
+		Step:         step,
+		Instruction:  instruction,
+		Position:     position,
+		Collectibles: collectibles,
+		ErrorCode:    errorCode,
+	})
+}
+
 func moveForward(position Position) Position {
 	switch position.Facing {
 	case DirectionNorth:
@@ -962,23 +1407,17 @@ func (s *simulator) calculateStars(success bool, steps int, collectibles int) in
 	return 1
 }
 
-func computeHint(level LevelDefinition, payload HintRequest) string {
+func computeHint(level LevelDefinition, payload HintRequest, locale string) string {
 	if payload.Attempts <= 0 {
 		if len(level.Hints) > 0 {
 			return level.Hints[0]
 		}
-		return "尝试运行你的方案，看看会发生什么！"
+		return builtInString(locale, "hint.try_running")
 	}
 	if payload.LastError != nil {
-		switch *payload.LastError {
-		case "E_COLLIDE":
-			return "前方可能有障碍，试着调整转向积木。"
-		case "E_STEP_LIMIT":
-			return "步数有点多，考虑使用重复积木。"
-		case "E_GOAL_NOT_MET":
-			return "别忘了达成所有目标，再检查一下程序。"
-		case "E_LOOP_DEPTH":
-			return "循环层级太深了，简化一下结构吧。"
+		switch code := *payload.LastError; code {
+		case "E_COLLIDE", "E_STEP_LIMIT", "E_GOAL_NOT_MET", "E_LOOP_DEPTH":
+			return builtInString(locale, "hint."+code)
 		}
 	}
 	index := payload.Attempts
@@ -988,16 +1427,19 @@ func computeHint(level LevelDefinition, payload HintRequest) string {
 	if index >= 0 && index < len(level.Hints) {
 		return level.Hints[index]
 	}
-	return "检查一下积木的顺序，也许要换个思路。"
+	return builtInString(locale, "hint.rethink")
 }
 
 func defaultChapters() []ChapterDefinition {
-	return []ChapterDefinition{
+	chapters := []ChapterDefinition{
 		{
 			ID:      "chapter-1",
 			Title:   "顺序启航",
 			Summary: "学习基础移动与转向指令",
 			Order:   1,
+			Translations: map[string]ChapterText{
+				apperr.LanguageEnglish: {Title: "Setting Sail in Sequence", Summary: "Learn the basic move and turn blocks"},
+			},
 			Levels: []LevelDefinition{
 				newLevel("level-1-1", "第一步", 5, 5, []Tile{
 					tile(0, 2), tile(1, 2), tile(2, 2), tile(3, 2), tile(4, 2),
@@ -1037,6 +1479,9 @@ func defaultChapters() []ChapterDefinition {
 			Title:   "循环岛屿",
 			Summary: "掌握重复与条件判断",
 			Order:   2,
+			Translations: map[string]ChapterText{
+				apperr.LanguageEnglish: {Title: "Loop Island", Summary: "Master repeats and conditions"},
+			},
 			Levels: []LevelDefinition{
 				newLevel("level-2-1", "重复练习", 6, 4, []Tile{
 					tile(0, 1), tile(1, 1), tile(2, 1), tile(3, 1), tile(4, 1), tile(5, 1),
@@ -1062,6 +1507,64 @@ func defaultChapters() []ChapterDefinition {
 			},
 		},
 	}
+	for ci := range chapters {
+		for li := range chapters[ci].Levels {
+			level := &chapters[ci].Levels[li]
+			if text, ok := defaultLevelTranslations[level.ID]; ok {
+				level.Translations = map[string]LevelText{apperr.LanguageEnglish: text}
+			}
+		}
+	}
+	return chapters
+}
+
+// defaultLevelTranslations holds the English text of the built-in levels.
+var defaultLevelTranslations = map[string]LevelText{
+	"level-1-1": {
+		Name: "First Steps",
+		Hints: []string{
+			"Press \"Run\" to see what happens now.",
+			"Move forward a few steps to reach the goal.",
+			"Keep facing the same way and you will get there.",
+		},
+		Comic: "Take your first brave step and follow the road to the finish flag.",
+	},
+	"level-1-2": {
+		Name: "Right-Angle Challenge",
+		Hints: []string{
+			"You need to turn to reach the goal.",
+			"Move forward first, then try heading up.",
+			"Remember to use a turn block at the corner.",
+		},
+		Comic: "Make it round the corner to the goal in the north.",
+	},
+	"level-1-3": {
+		Name: "Gem Road",
+		Hints: []string{
+			"You can collect the gems along the way.",
+			"Stand on a gem and use the \"Collect\" block.",
+			"Collect every gem before heading to the goal.",
+		},
+		Comic: "Collect the gems along the way, then reach the treasure chest.",
+	},
+	"level-2-1": {
+		Name: "Repeat Practice",
+		Hints: []string{
+			"Use the \"Repeat\" block when doing the same thing several times.",
+			"A repeat block saves you dragging the same block again and again.",
+			"Try setting the repeat count to 5.",
+		},
+		Comic: "Use a repeat block to reach the goal quickly.",
+	},
+	"level-2-2": {
+		Name: "The Fork in the Road",
+		Hints: []string{
+			"Check whether the path ahead of the fork is walkable.",
+			"Use \"If the path ahead is walkable\" to pick your route.",
+			"Only move forward when the condition is true.",
+		},
+		Comic: "Use a condition to choose the right road.",
+	},
 }
 
 func newLevel(id, name string, width, height int, tiles []Tile, start Position, goal LevelGoal, bestSteps int, hints []string, blocks []string, comic string, rewards LevelRewards, chapterID string, order int) LevelDefinition {
@@ -0,0 +1,441 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Errors returned by the avatar shop.
+var (
+	ErrAvatarItemNotFound = apperr.New(apperr.KindNotFound, "avatar.item_not_found")
+	ErrAvatarItemInvalid  = apperr.New(apperr.KindUnprocessable, "avatar.item_invalid")
+	ErrItemNotForSale     = apperr.New(apperr.KindUnprocessable, "avatar.item_not_for_sale")
+	ErrItemOwned          = apperr.New(apperr.KindConflict, "avatar.item_owned")
+	ErrInsufficientFunds  = apperr.New(apperr.KindUnprocessable, "wallet.insufficient_funds")
+	ErrIdempotencyReused  = apperr.New(apperr.KindConflict, "idempotency.key_reused")
+)
+
+// AvatarSlot is where an avatar item is worn. One item per slot is equipped.
+type AvatarSlot string
+
+const (
+	AvatarSlotHead AvatarSlot = "head"
+	AvatarSlotCape AvatarSlot = "cape"
+	AvatarSlotPet  AvatarSlot = "pet"
+)
+
+// Currency is what shop prices are paid in. Stars are earned by improving
+// the best result of a level; coins by clearing levels and earning badges.
+type Currency string
+
+const (
+	CurrencyStars Currency = "stars"
+	CurrencyCoins Currency = "coins"
+)
+
+// Coin rewards.
+const (
+	coinsPerLevelClear = 10
+	coinsPerBadge      = 20
+)
+
+// AvatarItemText is the translatable text of an avatar item in one locale.
+type AvatarItemText struct {
+	Name string `json:"name,omitempty"`
+}
+
+// AvatarItem is an entry of the avatar catalog. Its ID is what AvatarState
+// lists and equips. Items with a zero price are not sold; they are level
+// rewards, starter items or granted by teachers.
+type AvatarItem struct {
+	ID           string                    `json:"id"`
+	Name         string                    `json:"name"`
+	Slot         AvatarSlot                `json:"slot"`
+	Price        int                       `json:"price,omitempty"`
+	Currency     Currency                  `json:"currency,omitempty"`
+	Icon         string                    `json:"icon,omitempty"`
+	Translations map[string]AvatarItemText `json:"translations,omitempty"`
+}
+
+// LedgerKind classifies a ledger entry.
+type LedgerKind string
+
+const (
+	LedgerEarn   LedgerKind = "earn"
+	LedgerSpend  LedgerKind = "spend"
+	LedgerGrant  LedgerKind = "grant"
+	LedgerReward LedgerKind = "reward"
+)
+
+// LedgerEntry is one transaction of a student's wallet. Amount is signed:
+// earnings are positive and purchases negative. Entries that hand out an
+// item carry its ID. Key makes posting idempotent: a second entry with the
+// same key is never recorded.
+type LedgerEntry struct {
+	ID        string     `json:"id"`
+	Key       string     `json:"key"`
+	Kind      LedgerKind `json:"kind"`
+	Currency  Currency   `json:"currency,omitempty"`
+	Amount    int        `json:"amount"`
+	ItemID    string     `json:"itemId,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	ActorID   string     `json:"actorId,omitempty"`
+	CreatedAt int64      `json:"createdAt"`
+}
+
+// Wallet holds the balances of a student.
+type Wallet struct {
+	Stars int `json:"stars"`
+	Coins int `json:"coins"`
+}
+
+func (w Wallet) balance(currency Currency) int {
+	if currency == CurrencyCoins {
+		return w.Coins
+	}
+	return w.Stars
+}
+
+// ShopItem is a catalog item as shown to a student.
+type ShopItem struct {
+	AvatarItem
+	ForSale    bool `json:"forSale"`
+	Owned      bool `json:"owned"`
+	Affordable bool `json:"affordable"`
+}
+
+// Shop is the catalog with the student's wallet.
+type Shop struct {
+	Wallet Wallet     `json:"wallet"`
+	Items  []ShopItem `json:"items"`
+}
+
+// PurchaseResult reports a purchase. Replayed is set when the idempotency
+// key was used before and nothing was charged again.
+type PurchaseResult struct {
+	Entry    LedgerEntry `json:"entry"`
+	Avatar   AvatarState `json:"avatar"`
+	Replayed bool        `json:"replayed"`
+}
+
+// Ledger is a student's wallet with its transactions, newest first.
+type Ledger struct {
+	Wallet  Wallet        `json:"wallet"`
+	Entries []LedgerEntry `json:"entries"`
+}
+
+// Shop lists the catalog for a student, ordered by slot and price.
+func (s *Service) Shop(ctx context.Context, userID string) Shop {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wallet := s.wallet(profile.ID)
+	shop := Shop{Wallet: wallet, Items: make([]ShopItem, 0, len(s.avatarItems))}
+	for _, item := range s.sortedAvatarItems() {
+		forSale := item.Price > 0
+		shop.Items = append(shop.Items, ShopItem{
+			AvatarItem: localizeAvatarItem(item, profile.Settings.Language),
+			ForSale:    forSale,
+			Owned:      contains(profile.Avatar.Unlocked, item.ID),
+			Affordable: forSale && wallet.balance(item.Currency) >= item.Price,
+		})
+	}
+	return shop
+}
+
+// Purchase buys a catalog item. Repeating a purchase with the same
+// idempotency key returns the original result; an empty key defaults to one
+// derived from the item, so buying an item twice never charges twice.
+func (s *Service) Purchase(ctx context.Context, userID, itemID, idempotencyKey string) (PurchaseResult, error) {
+	profile := s.ensureProfile(userID)
+	key := strings.TrimSpace(idempotencyKey)
+	if key == "" {
+		key = "purchase:" + itemID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.ledgerEntry(profile.ID, key); ok {
+		if entry.Kind != LedgerSpend || entry.ItemID != itemID {
+			return PurchaseResult{}, ErrIdempotencyReused.With("key", key)
+		}
+		return PurchaseResult{Entry: entry, Avatar: s.avatarState(profile), Replayed: true}, nil
+	}
+
+	item, ok := s.avatarItems[itemID]
+	if !ok {
+		return PurchaseResult{}, ErrAvatarItemNotFound.With("itemId", itemID)
+	}
+	if item.Price <= 0 {
+		return PurchaseResult{}, ErrItemNotForSale.With("itemId", itemID)
+	}
+	if contains(profile.Avatar.Unlocked, itemID) {
+		return PurchaseResult{}, ErrItemOwned.With("itemId", itemID)
+	}
+	if balance := s.wallet(profile.ID).balance(item.Currency); balance < item.Price {
+		return PurchaseResult{}, ErrInsufficientFunds.
+			With("currency", string(item.Currency)).
+			With("price", item.Price).
+			With("balance", balance)
+	}
+
+	entry, _ := s.postLedger(profile.ID, LedgerEntry{
+		Key:      key,
+		Kind:     LedgerSpend,
+		Currency: item.Currency,
+		Amount:   -item.Price,
+		ItemID:   itemID,
+	}, time.Now())
+	unlockAvatar(profile, itemID)
+	return PurchaseResult{Entry: entry, Avatar: s.avatarState(profile)}, nil
+}
+
+// GrantAvatarItem gives a catalog item to a student for free on behalf of a
+// teacher or admin. Granting an item twice returns the first grant.
+func (s *Service) GrantAvatarItem(ctx context.Context, studentID, itemID, actorID, reason string) (LedgerEntry, error) {
+	profile := s.ensureProfile(studentID)
+	key := "grant:" + itemID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.ledgerEntry(profile.ID, key); ok {
+		return entry, nil
+	}
+	if _, ok := s.avatarItems[itemID]; !ok {
+		return LedgerEntry{}, ErrAvatarItemNotFound.With("itemId", itemID)
+	}
+	if contains(profile.Avatar.Unlocked, itemID) {
+		return LedgerEntry{}, ErrItemOwned.With("itemId", itemID)
+	}
+
+	entry, _ := s.postLedger(profile.ID, LedgerEntry{
+		Key:     key,
+		Kind:    LedgerGrant,
+		ItemID:  itemID,
+		Reason:  strings.TrimSpace(reason),
+		ActorID: actorID,
+	}, time.Now())
+	unlockAvatar(profile, itemID)
+	return entry, nil
+}
+
+// Ledger returns a student's wallet and transactions, newest first.
+func (s *Service) Ledger(ctx context.Context, userID string) Ledger {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.ledgers[profile.ID]
+	ledger := Ledger{Wallet: s.wallet(profile.ID), Entries: make([]LedgerEntry, 0, len(entries))}
+	for i := len(entries) - 1; i >= 0; i-- {
+		ledger.Entries = append(ledger.Entries, entries[i])
+	}
+	return ledger
+}
+
+// AvatarItems lists the catalog ordered by slot and price.
+func (s *Service) AvatarItems(ctx context.Context) []AvatarItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedAvatarItems()
+}
+
+// SaveAvatarItem validates a catalog item and creates or replaces it.
+func (s *Service) SaveAvatarItem(ctx context.Context, item AvatarItem) (AvatarItem, error) {
+	var problems []apperr.Problem
+	if strings.TrimSpace(item.ID) == "" {
+		problems = append(problems, apperr.NewProblem("id", "required"))
+	}
+	if strings.TrimSpace(item.Name) == "" {
+		problems = append(problems, apperr.NewProblem("name", "required"))
+	}
+	switch item.Slot {
+	case AvatarSlotHead, AvatarSlotCape, AvatarSlotPet:
+	default:
+		problems = append(problems, apperr.NewProblem("slot", "unknown", "value", string(item.Slot)))
+	}
+	switch {
+	case item.Price < 0:
+		problems = append(problems, apperr.NewProblem("price", "negative"))
+	case item.Price == 0:
+		item.Currency = ""
+	case item.Currency != CurrencyStars && item.Currency != CurrencyCoins:
+		problems = append(problems, apperr.NewProblem("currency", "unknown", "value", string(item.Currency)))
+	}
+	for _, locale := range unsupportedLocales(item.Translations) {
+		problems = append(problems, apperr.NewProblem("translations", "unsupported_locale", "locale", locale))
+	}
+	if len(problems) > 0 {
+		return AvatarItem{}, ErrAvatarItemInvalid.With("problems", problems)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item.Translations = cloneTranslations(item.Translations)
+	s.avatarItems[item.ID] = item
+	return item, nil
+}
+
+// DeleteAvatarItem removes an item from the catalog. Students who own it
+// keep it.
+func (s *Service) DeleteAvatarItem(ctx context.Context, itemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.avatarItems[itemID]; !ok {
+		return ErrAvatarItemNotFound.With("itemId", itemID)
+	}
+	delete(s.avatarItems, itemID)
+	return nil
+}
+
+// creditCompletion posts the stars and coins a completion earned: the stars
+// by which the best result of the level improved, and coins for the first
+// clear. Callers must hold the write lock.
+func (s *Service) creditCompletion(studentID, levelID string, previousStars, stars int, at time.Time) {
+	if stars > previousStars {
+		s.postLedger(studentID, LedgerEntry{
+			Key:      fmt.Sprintf("level:%s:stars:%d", levelID, stars),
+			Kind:     LedgerEarn,
+			Currency: CurrencyStars,
+			Amount:   stars - previousStars,
+			Reason:   levelID,
+		}, at)
+	}
+	if previousStars == 0 && stars > 0 {
+		s.postLedger(studentID, LedgerEntry{
+			Key:      "level:" + levelID + ":clear",
+			Kind:     LedgerEarn,
+			Currency: CurrencyCoins,
+			Amount:   coinsPerLevelClear,
+			Reason:   levelID,
+		}, at)
+	}
+}
+
+// postLedger appends an entry unless one with the same key exists, in which
+// case the existing entry is returned with false. Callers must hold the
+// write lock.
+func (s *Service) postLedger(studentID string, entry LedgerEntry, at time.Time) (LedgerEntry, bool) {
+	if existing, ok := s.ledgerEntry(studentID, entry.Key); ok {
+		return existing, false
+	}
+	entry.ID = uuid.NewString()
+	entry.CreatedAt = at.UnixMilli()
+	s.ledgers[studentID] = append(s.ledgers[studentID], entry)
+	return entry, true
+}
+
+func (s *Service) ledgerEntry(studentID, key string) (LedgerEntry, bool) {
+	for _, entry := range s.ledgers[studentID] {
+		if entry.Key == key {
+			return entry, true
+		}
+	}
+	return LedgerEntry{}, false
+}
+
+// wallet sums the ledger of a student. Callers must hold the lock.
+func (s *Service) wallet(studentID string) Wallet {
+	var wallet Wallet
+	for _, entry := range s.ledgers[studentID] {
+		switch entry.Currency {
+		case CurrencyStars:
+			wallet.Stars += entry.Amount
+		case CurrencyCoins:
+			wallet.Coins += entry.Amount
+		}
+	}
+	return wallet
+}
+
+// avatarState returns a copy of the student's avatar with the wallet filled
+// in. Callers must hold the lock.
+func (s *Service) avatarState(profile *StudentProfile) AvatarState {
+	state := AvatarState{
+		Equipped: profile.Avatar.Equipped,
+		Unlocked: append([]string{}, profile.Avatar.Unlocked...),
+		Slots:    make(map[AvatarSlot]string, len(profile.Avatar.Slots)),
+		Wallet:   s.wallet(profile.ID),
+	}
+	for slot, itemID := range profile.Avatar.Slots {
+		state.Slots[slot] = itemID
+	}
+	return state
+}
+
+// itemSlot returns the slot of an item. Outfits outside the catalog, such as
+// rewards of teacher levels, are worn on the head.
+func (s *Service) itemSlot(itemID string) AvatarSlot {
+	if item, ok := s.avatarItems[itemID]; ok {
+		return item.Slot
+	}
+	return AvatarSlotHead
+}
+
+var avatarSlotOrder = map[AvatarSlot]int{AvatarSlotHead: 0, AvatarSlotCape: 1, AvatarSlotPet: 2}
+
+func (s *Service) sortedAvatarItems() []AvatarItem {
+	items := make([]AvatarItem, 0, len(s.avatarItems))
+	for _, item := range s.avatarItems {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Slot != b.Slot {
+			return avatarSlotOrder[a.Slot] < avatarSlotOrder[b.Slot]
+		}
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.ID < b.ID
+	})
+	return items
+}
+
+func localizeAvatarItem(item AvatarItem, locale string) AvatarItem {
+	text, ok := lookupTranslation(item.Translations, locale)
+	item.Translations = nil
+	if ok && !isDefaultLocale(locale) && text.Name != "" {
+		item.Name = text.Name
+	}
+	return item
+}
+
+func defaultAvatarItems() map[string]AvatarItem {
+	english := func(name string) map[string]AvatarItemText {
+		return map[string]AvatarItemText{apperr.LanguageEnglish: {Name: name}}
+	}
+	items := []AvatarItem{
+		{ID: "冒险家头盔", Name: "冒险家头盔", Slot: AvatarSlotHead, Translations: english("Adventurer Helmet")},
+		{ID: "宝石护目镜", Name: "宝石护目镜", Slot: AvatarSlotHead, Translations: english("Gem Goggles")},
+		{ID: "星光王冠", Name: "星光王冠", Slot: AvatarSlotHead, Price: 12, Currency: CurrencyStars, Translations: english("Starlight Crown")},
+		{ID: "海盗帽", Name: "海盗帽", Slot: AvatarSlotHead, Price: 40, Currency: CurrencyCoins, Translations: english("Pirate Hat")},
+		{ID: "新手披风", Name: "新手披风", Slot: AvatarSlotCape, Translations: english("Beginner Cape")},
+		{ID: "罗盘背包", Name: "罗盘背包", Slot: AvatarSlotCape, Translations: english("Compass Backpack")},
+		{ID: "循环披风", Name: "循环披风", Slot: AvatarSlotCape, Translations: english("Loop Cape")},
+		{ID: "探索者斗篷", Name: "探索者斗篷", Slot: AvatarSlotCape, Translations: english("Explorer Cloak")},
+		{ID: "彩虹披风", Name: "彩虹披风", Slot: AvatarSlotCape, Price: 20, Currency: CurrencyStars, Translations: english("Rainbow Cape")},
+		{ID: "像素小狗", Name: "像素小狗", Slot: AvatarSlotPet, Price: 30, Currency: CurrencyCoins, Translations: english("Pixel Puppy")},
+		{ID: "机器猫", Name: "机器猫", Slot: AvatarSlotPet, Price: 60, Currency: CurrencyCoins, Translations: english("Robo Cat")},
+		{ID: "小飞龙", Name: "小飞龙", Slot: AvatarSlotPet, Price: 30, Currency: CurrencyStars, Translations: english("Little Dragon")},
+	}
+	catalog := make(map[string]AvatarItem, len(items))
+	for _, item := range items {
+		catalog[item.ID] = item
+	}
+	return catalog
+}
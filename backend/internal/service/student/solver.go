@@ -0,0 +1,117 @@
+package student
+
+// maxSolverCollectibles bounds how many collectibles the solver tracks. Each
+// collectible doubles the search space, so larger levels are rejected.
+const maxSolverCollectibles = 8
+
+// defaultStepLimit mirrors the step budget the simulator applies when a level
+// does not configure one.
+const defaultStepLimit = 200
+
+type solverState struct {
+	x, y      int
+	facing    Direction
+	collected uint16
+}
+
+type solverNode struct {
+	parent      int
+	instruction Instruction
+	state       solverState
+}
+
+// solveLevel searches for the shortest sequence of primitive instructions that
+// satisfies the level goals using only the allowed blocks. It returns false
+// when no solution exists within the level's step limit.
+func solveLevel(level LevelDefinition) ([]Instruction, bool) {
+	sim := newSimulator(level)
+
+	collectibles := make(map[[2]int]int)
+	for _, tile := range level.Tiles {
+		if tile.Collectible != "" {
+			collectibles[[2]int{tile.X, tile.Y}] = len(collectibles)
+		}
+	}
+	if len(collectibles) > maxSolverCollectibles {
+		return nil, false
+	}
+	all := uint16(1)<<len(collectibles) - 1
+
+	done := func(state solverState) bool {
+		if level.Goal.Collectibles != nil && state.collected != all {
+			return false
+		}
+		if level.Goal.Reach != nil && (state.x != level.Goal.Reach.X || state.y != level.Goal.Reach.Y) {
+			return false
+		}
+		return true
+	}
+
+	allowed := func(code string) bool {
+		return len(level.AllowedBlocks) == 0 || contains(level.AllowedBlocks, code)
+	}
+	actions := make([]Instruction, 0, 4)
+	if allowed("MOVE") {
+		actions = append(actions, Instruction{Type: "move"})
+	}
+	if allowed("TURN_LEFT") {
+		actions = append(actions, Instruction{Type: "turn", Direction: "left"})
+	}
+	if allowed("TURN_RIGHT") {
+		actions = append(actions, Instruction{Type: "turn", Direction: "right"})
+	}
+	if allowed("COLLECT") {
+		actions = append(actions, Instruction{Type: "collect"})
+	}
+
+	stepLimit := defaultStepLimit
+	if level.Goal.StepLimit != nil {
+		stepLimit = *level.Goal.StepLimit
+	}
+
+	start := solverState{x: level.Start.X, y: level.Start.Y, facing: level.Start.Facing}
+	nodes := []solverNode{{parent: -1, state: start}}
+	depths := []int{0}
+	seen := map[solverState]struct{}{start: {}}
+
+	for head := 0; head < len(nodes); head++ {
+		current := nodes[head]
+		if done(current.state) {
+			program := make([]Instruction, depths[head])
+			for idx := head; nodes[idx].parent >= 0; idx = nodes[idx].parent {
+				program[depths[idx]-1] = nodes[idx].instruction
+			}
+			return program, true
+		}
+		if depths[head] >= stepLimit {
+			continue
+		}
+
+		for _, action := range actions {
+			next := current.state
+			switch action.Type {
+			case "move":
+				moved := moveForward(Position{X: next.x, Y: next.y, Facing: next.facing})
+				if !sim.isWalkable(moved.X, moved.Y) {
+					continue
+				}
+				next.x, next.y = moved.X, moved.Y
+			case "turn":
+				next.facing = rotate(next.facing, action.Direction)
+			case "collect":
+				idx, ok := collectibles[[2]int{next.x, next.y}]
+				if !ok || next.collected&(1<<idx) != 0 {
+					continue
+				}
+				next.collected |= 1 << idx
+			}
+			if _, ok := seen[next]; ok {
+				continue
+			}
+			seen[next] = struct{}{}
+			nodes = append(nodes, solverNode{parent: head, instruction: action, state: next})
+			depths = append(depths, depths[head]+1)
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,234 @@
+package student
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// UnlockMode selects how the levels of a chapter open up.
+type UnlockMode string
+
+const (
+	// UnlockSequential opens the first level of every chapter and each
+	// further level once the previous one earned MinStars.
+	UnlockSequential UnlockMode = "sequential"
+	// UnlockOpen opens every level.
+	UnlockOpen UnlockMode = "open"
+	// UnlockStars opens a level once each of its prerequisites, which may
+	// lie in other chapters, earned MinStars. Levels without prerequisites
+	// open sequentially.
+	UnlockStars UnlockMode = "stars"
+	// UnlockManual opens only the levels the teacher unlocked.
+	UnlockManual UnlockMode = "manual"
+)
+
+// Errors returned by the unlock operations.
+var (
+	ErrInvalidUnlockPolicy = apperr.New(apperr.KindUnprocessable, "unlock.invalid")
+	ErrUnlockNotFound      = apperr.New(apperr.KindNotFound, "unlock.not_found")
+)
+
+// UnlockPolicy decides which levels a student may play. MinStars applies to
+// the sequential and stars modes and defaults to 1, Prerequisites maps a
+// level to the levels it requires in stars mode and UnlockedLevels lists the
+// levels open in manual mode. Completed levels always stay open.
+type UnlockPolicy struct {
+	Mode           UnlockMode          `json:"mode"`
+	MinStars       int                 `json:"minStars,omitempty"`
+	Prerequisites  map[string][]string `json:"prerequisites,omitempty"`
+	UnlockedLevels []string            `json:"unlockedLevels,omitempty"`
+}
+
+// Clone returns a deep copy of the policy.
+func (p UnlockPolicy) Clone() UnlockPolicy {
+	clone := UnlockPolicy{Mode: p.Mode, MinStars: p.MinStars}
+	if p.Prerequisites != nil {
+		clone.Prerequisites = make(map[string][]string, len(p.Prerequisites))
+		for levelID, required := range p.Prerequisites {
+			clone.Prerequisites[levelID] = append([]string(nil), required...)
+		}
+	}
+	if p.UnlockedLevels != nil {
+		clone.UnlockedLevels = append([]string(nil), p.UnlockedLevels...)
+	}
+	return clone
+}
+
+// defaultUnlockPolicy is the policy of levels nobody configured.
+var defaultUnlockPolicy = UnlockPolicy{Mode: UnlockSequential, MinStars: 1}
+
+// ClassUnlocks are the unlock policies of a class. Default applies to every
+// level without an entry in Levels.
+type ClassUnlocks struct {
+	Default UnlockPolicy
+	Levels  map[string]UnlockPolicy
+}
+
+// LevelUnlock is a level a teacher opened for a single student.
+type LevelUnlock struct {
+	LevelID    string `json:"levelId"`
+	UnlockedAt int64  `json:"unlockedAt"`
+}
+
+// NormalizeUnlockPolicy checks a policy against the level catalogue and
+// fills in its defaults. Prerequisites are only kept in stars mode and
+// unlocked levels only in manual mode.
+func (s *Service) NormalizeUnlockPolicy(ctx context.Context, policy UnlockPolicy) (UnlockPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policy = policy.Clone()
+	switch policy.Mode {
+	case UnlockSequential, UnlockStars:
+		if policy.MinStars == 0 {
+			policy.MinStars = 1
+		}
+		if policy.MinStars < 1 || policy.MinStars > 3 {
+			return UnlockPolicy{}, ErrInvalidUnlockPolicy.With("field", "minStars")
+		}
+	case UnlockOpen, UnlockManual:
+		policy.MinStars = 0
+	default:
+		return UnlockPolicy{}, ErrInvalidUnlockPolicy.With("field", "mode")
+	}
+
+	if policy.Mode != UnlockStars {
+		policy.Prerequisites = nil
+	}
+	for levelID, required := range policy.Prerequisites {
+		if _, ok := s.levels[levelID]; !ok {
+			return UnlockPolicy{}, ErrInvalidUnlockPolicy.With("field", "prerequisites").With("levelId", levelID)
+		}
+		for _, requiredID := range required {
+			if _, ok := s.levels[requiredID]; !ok || requiredID == levelID {
+				return UnlockPolicy{}, ErrInvalidUnlockPolicy.With("field", "prerequisites").With("levelId", requiredID)
+			}
+		}
+	}
+
+	if policy.Mode != UnlockManual {
+		policy.UnlockedLevels = nil
+	}
+	for _, levelID := range policy.UnlockedLevels {
+		if _, ok := s.levels[levelID]; !ok {
+			return UnlockPolicy{}, ErrInvalidUnlockPolicy.With("field", "unlockedLevels").With("levelId", levelID)
+		}
+	}
+	return policy, nil
+}
+
+// ConfigureUnlocks records the unlock policies of a class.
+func (s *Service) ConfigureUnlocks(ctx context.Context, classID string, unlocks ClassUnlocks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.classUnlocks[classID] = unlocks
+}
+
+// UnlockLevelFor opens a level for one student regardless of the policy of
+// their class, for example after they missed a lesson.
+func (s *Service) UnlockLevelFor(ctx context.Context, studentID, levelID string) ([]LevelUnlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.levels[levelID]; !ok {
+		return nil, ErrLevelNotFound.With("levelId", levelID)
+	}
+	overrides, ok := s.unlockOverrides[studentID]
+	if !ok {
+		overrides = make(map[string]int64)
+		s.unlockOverrides[studentID] = overrides
+	}
+	if _, ok := overrides[levelID]; !ok {
+		overrides[levelID] = time.Now().UnixMilli()
+	}
+	return s.levelUnlocks(studentID), nil
+}
+
+// RevokeLevelUnlock removes a level opened for one student.
+func (s *Service) RevokeLevelUnlock(ctx context.Context, studentID, levelID string) ([]LevelUnlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.unlockOverrides[studentID][levelID]; !ok {
+		return nil, ErrUnlockNotFound.With("levelId", levelID)
+	}
+	delete(s.unlockOverrides[studentID], levelID)
+	return s.levelUnlocks(studentID), nil
+}
+
+// LevelUnlocks lists the levels opened for one student, oldest first.
+func (s *Service) LevelUnlocks(ctx context.Context, studentID string) []LevelUnlock {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.levelUnlocks(studentID)
+}
+
+// levelUnlocks lists the overrides of a student. Callers must hold the lock.
+func (s *Service) levelUnlocks(studentID string) []LevelUnlock {
+	unlocks := make([]LevelUnlock, 0, len(s.unlockOverrides[studentID]))
+	for levelID, unlockedAt := range s.unlockOverrides[studentID] {
+		unlocks = append(unlocks, LevelUnlock{LevelID: levelID, UnlockedAt: unlockedAt})
+	}
+	sort.Slice(unlocks, func(i, j int) bool {
+		if unlocks[i].UnlockedAt != unlocks[j].UnlockedAt {
+			return unlocks[i].UnlockedAt < unlocks[j].UnlockedAt
+		}
+		return unlocks[i].LevelID < unlocks[j].LevelID
+	})
+	return unlocks
+}
+
+// unlockPolicy returns the policy governing a level for a class. Callers
+// must hold the lock.
+func (s *Service) unlockPolicy(classID, levelID string) UnlockPolicy {
+	unlocks, ok := s.classUnlocks[classID]
+	if !ok {
+		return defaultUnlockPolicy
+	}
+	if policy, ok := unlocks.Levels[levelID]; ok {
+		return policy
+	}
+	if unlocks.Default.Mode == "" {
+		return defaultUnlockPolicy
+	}
+	return unlocks.Default
+}
+
+// isLevelUnlocked reports whether a student may open the level at
+// levelIndex of a chapter under the policy of their class or an override.
+// Callers must hold the lock.
+func (s *Service) isLevelUnlocked(profile *StudentProfile, chapterID string, levelIndex int) bool {
+	chapter, _ := s.findChapter(chapterID)
+	if chapter == nil || levelIndex < 0 || levelIndex >= len(chapter.Levels) {
+		return false
+	}
+	level := chapter.Levels[levelIndex]
+	if _, ok := s.unlockOverrides[profile.ID][level.ID]; ok {
+		return true
+	}
+
+	policy := s.unlockPolicy(profile.ClassID, level.ID)
+	earned := func(levelID string) bool {
+		progress, ok := profile.Progress[levelID]
+		return ok && progress.Stars > 0 && progress.Stars >= policy.MinStars
+	}
+	switch policy.Mode {
+	case UnlockOpen:
+		return true
+	case UnlockManual:
+		return contains(policy.UnlockedLevels, level.ID)
+	case UnlockStars:
+		if required, ok := policy.Prerequisites[level.ID]; ok {
+			for _, requiredID := range required {
+				if !earned(requiredID) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return levelIndex == 0 || earned(chapter.Levels[levelIndex-1].ID)
+}
@@ -0,0 +1,151 @@
+package student
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// maxLevelSize bounds the width and height of authored levels.
+const maxLevelSize = 20
+
+var knownBlocks = []string{"MOVE", "TURN_LEFT", "TURN_RIGHT", "COLLECT", "REPEAT", "CONDITIONAL"}
+
+// ErrLevelInvalid is the domain error behind LevelValidationError.
+var ErrLevelInvalid = apperr.New(apperr.KindUnprocessable, "level.invalid")
+
+// LevelValidationError lists every structural problem found in a level.
+type LevelValidationError struct {
+	Problems []apperr.Problem
+}
+
+func (e *LevelValidationError) Error() string {
+	return "level is invalid: " + apperr.JoinProblems(e.Problems)
+}
+
+// AppError reports the problems as details of ErrLevelInvalid.
+func (e *LevelValidationError) AppError() *apperr.Error {
+	return ErrLevelInvalid.With("problems", e.Problems)
+}
+
+// LevelCheck is the outcome of validating a level: the shortest solution the
+// solver found, already verified by the simulator.
+type LevelCheck struct {
+	ShortestSteps int           `json:"shortestSteps"`
+	Solution      []Instruction `json:"solution"`
+}
+
+// ValidateLevel checks a level definition without storing it.
+func (s *Service) ValidateLevel(ctx context.Context, level LevelDefinition) (LevelCheck, error) {
+	return validateLevel(level)
+}
+
+// validateLevel checks bounds, tiles, start and goal, then proves the level is
+// solvable by running the solver's solution through the simulator.
+func validateLevel(level LevelDefinition) (LevelCheck, error) {
+	var problems []apperr.Problem
+	fail := func(path, code string, params ...any) {
+		problems = append(problems, apperr.NewProblem(path, code, params...))
+	}
+
+	if strings.TrimSpace(level.Name) == "" {
+		fail("name", "required")
+	}
+	if level.BestSteps < 0 {
+		fail("bestSteps", "negative")
+	}
+	for _, block := range level.AllowedBlocks {
+		if !contains(knownBlocks, block) {
+			fail("allowedBlocks", "unknown_block", "block", block)
+		}
+	}
+	for _, locale := range unsupportedLocales(level.Translations) {
+		fail("translations", "unsupported_locale", "locale", locale)
+	}
+	problems = append(problems, mapProblems(level, true)...)
+
+	if len(problems) > 0 {
+		return LevelCheck{}, &LevelValidationError{Problems: problems}
+	}
+
+	invalid := func(path, code string, params ...any) error {
+		return &LevelValidationError{Problems: []apperr.Problem{apperr.NewProblem(path, code, params...)}}
+	}
+	solution, ok := solveLevel(level)
+	if !ok {
+		return LevelCheck{}, invalid("goal", "unsolvable")
+	}
+	if len(solution) == 0 {
+		return LevelCheck{}, invalid("goal", "met_at_start")
+	}
+	if result := newSimulator(level).run(solution); !result.Success {
+		return LevelCheck{}, invalid("goal", "simulation_mismatch", "errorCode", result.ErrorCode)
+	}
+	if level.BestSteps > 0 && level.BestSteps < len(solution) {
+		return LevelCheck{}, invalid("bestSteps", "below_shortest", "bestSteps", level.BestSteps, "shortest", len(solution))
+	}
+
+	return LevelCheck{ShortestSteps: len(solution), Solution: solution}, nil
+}
+
+// mapProblems checks the size, tiles, start and goal of a map. Sandbox maps
+// pass requireGoal false since free play has nothing to win.
+func mapProblems(level LevelDefinition, requireGoal bool) []apperr.Problem {
+	var problems []apperr.Problem
+	fail := func(path, code string, params ...any) {
+		problems = append(problems, apperr.NewProblem(path, code, params...))
+	}
+	inBounds := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < level.Width && y < level.Height
+	}
+
+	if level.Width < 1 || level.Width > maxLevelSize || level.Height < 1 || level.Height > maxLevelSize {
+		fail("size", "out_of_range", "min", 1, "max", maxLevelSize)
+	}
+
+	walkable := make(map[[2]int]bool, len(level.Tiles))
+	collectibles := 0
+	for _, tile := range level.Tiles {
+		key := [2]int{tile.X, tile.Y}
+		if !inBounds(tile.X, tile.Y) {
+			fail("tiles", "out_of_bounds", "x", tile.X, "y", tile.Y)
+			continue
+		}
+		if _, dup := walkable[key]; dup {
+			fail("tiles", "duplicate", "x", tile.X, "y", tile.Y)
+			continue
+		}
+		walkable[key] = tile.Walkable
+		if tile.Collectible != "" {
+			collectibles++
+			if !tile.Walkable {
+				fail("tiles", "collectible_not_walkable", "x", tile.X, "y", tile.Y)
+			}
+		}
+	}
+	if collectibles > maxSolverCollectibles {
+		fail("tiles", "too_many_collectibles", "max", maxSolverCollectibles)
+	}
+
+	switch level.Start.Facing {
+	case DirectionNorth, DirectionEast, DirectionSouth, DirectionWest:
+	default:
+		fail("start.facing", "unknown_direction", "facing", string(level.Start.Facing))
+	}
+	if !walkable[[2]int{level.Start.X, level.Start.Y}] {
+		fail("start", "not_walkable", "x", level.Start.X, "y", level.Start.Y)
+	}
+
+	goal := level.Goal
+	if requireGoal && goal.Reach == nil && goal.Collectibles == nil {
+		fail("goal", "required")
+	}
+	if goal.Reach != nil && !walkable[[2]int{goal.Reach.X, goal.Reach.Y}] {
+		fail("goal.reach", "not_walkable", "x", goal.Reach.X, "y", goal.Reach.Y)
+	}
+	if goal.StepLimit != nil && *goal.StepLimit < 1 {
+		fail("goal.stepLimit", "not_positive")
+	}
+	return problems
+}
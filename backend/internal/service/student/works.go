@@ -0,0 +1,419 @@
+package student
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+)
+
+// Errors returned by work submissions.
+var (
+	ErrWorkNotFound   = apperr.New(apperr.KindNotFound, "work.not_found")
+	ErrWorkNotPending = apperr.New(apperr.KindConflict, "work.not_pending")
+	ErrWorkInvalid    = apperr.New(apperr.KindInvalid, "work.invalid")
+)
+
+// WorkKind is what a submission contains.
+type WorkKind string
+
+const (
+	WorkLevel   WorkKind = "level"
+	WorkProject WorkKind = "project"
+)
+
+// WorkStatus is where a submission is in teacher review.
+type WorkStatus string
+
+const (
+	WorkPending  WorkStatus = "pending"
+	WorkApproved WorkStatus = "approved"
+	WorkRejected WorkStatus = "rejected"
+)
+
+// WorkResult is the simulator's verdict on the submitted program.
+type WorkResult struct {
+	Success   bool   `json:"success"`
+	Steps     int    `json:"steps"`
+	Stars     int    `json:"stars"`
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+// WorkSubmission is a level solution or sandbox project a student handed in
+// to their class. A student has at most one submission per level or project;
+// submitting again replaces its content and puts it back into review. Score
+// is the submission scored against the class rubric, if there is one, and
+// Reviews is every review the submission received across its versions.
+type WorkSubmission struct {
+	ID           string             `json:"id"`
+	OwnerID      string             `json:"ownerId"`
+	ClassID      string             `json:"classId"`
+	Kind         WorkKind           `json:"kind"`
+	Title        string             `json:"title"`
+	LevelID      string             `json:"levelId,omitempty"`
+	ProjectID    string             `json:"projectId,omitempty"`
+	AssignmentID string             `json:"assignmentId,omitempty"`
+	ItemID       string             `json:"itemId,omitempty"`
+	Map          *ProjectMap        `json:"map,omitempty"`
+	Program      []Instruction      `json:"program"`
+	Result       WorkResult         `json:"result"`
+	Status       WorkStatus         `json:"status"`
+	Feedback     string             `json:"feedback,omitempty"`
+	Stars        *int               `json:"stars,omitempty"`
+	ReviewedBy   string             `json:"reviewedBy,omitempty"`
+	ReviewedAt   int64              `json:"reviewedAt,omitempty"`
+	Score        *RubricScore       `json:"score,omitempty"`
+	Reviews      []WorkReviewRecord `json:"reviews"`
+	Version      int                `json:"version"`
+	CreatedAt    int64              `json:"createdAt"`
+	SubmittedAt  int64              `json:"submittedAt"`
+}
+
+// WorkInput is what a student submits: either a level with a program, or
+// one of their sandbox projects as saved. AssignmentID and ItemID hand the
+// work in for a task of an assignment.
+type WorkInput struct {
+	Title        string        `json:"title"`
+	LevelID      string        `json:"levelId"`
+	ProjectID    string        `json:"projectId"`
+	Program      []Instruction `json:"program"`
+	AssignmentID string        `json:"assignmentId"`
+	ItemID       string        `json:"itemId"`
+}
+
+// WorkReview is a teacher's verdict. Stars, when set, overrides the stars
+// the simulator awarded. Scores grades the criteria of the rubric.
+type WorkReview struct {
+	Status   WorkStatus   `json:"status"`
+	Feedback string       `json:"feedback"`
+	Stars    *int         `json:"stars"`
+	Scores   []ScoreInput `json:"scores"`
+}
+
+// Notification tells a student about something that happened to them, such
+// as a reviewed submission. Ref is the ID of the related object.
+type Notification struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Ref       string `json:"ref"`
+	Title     string `json:"title"`
+	Body      string `json:"body,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+	Read      bool   `json:"read"`
+}
+
+// Notifications is a student's inbox, newest first.
+type Notifications struct {
+	Unread int            `json:"unread"`
+	Items  []Notification `json:"items"`
+}
+
+// SubmitWork hands a level solution or sandbox project in to the student's
+// class. Submitting the same level or project again replaces the earlier
+// submission and puts it back into review.
+func (s *Service) SubmitWork(ctx context.Context, userID string, input WorkInput) (WorkSubmission, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	work := WorkSubmission{OwnerID: profile.ID, ClassID: profile.ClassID}
+	var level LevelDefinition
+	switch {
+	case input.LevelID != "" && input.ProjectID != "":
+		return WorkSubmission{}, ErrWorkInvalid.With("problems", []apperr.Problem{apperr.NewProblem("projectId", "conflicts_with_level")})
+	case input.ProjectID != "":
+		project, err := s.ownProject(profile.ID, input.ProjectID)
+		if err != nil {
+			return WorkSubmission{}, err
+		}
+		if level, err = s.playableProjectLevel(profile, project); err != nil {
+			return WorkSubmission{}, err
+		}
+		work.Kind = WorkProject
+		work.ProjectID = project.ID
+		work.LevelID = project.LevelID
+		work.Map = project.Map.clone()
+		work.Program = append([]Instruction{}, project.Program...)
+		work.Title = project.Title
+	case input.LevelID != "":
+		var ok bool
+		if level, ok = s.levels[input.LevelID]; !ok || !s.levelVisible(profile, level) {
+			return WorkSubmission{}, ErrLevelNotFound.With("levelId", input.LevelID)
+		}
+		if len(input.Program) == 0 {
+			return WorkSubmission{}, ErrWorkInvalid.With("problems", []apperr.Problem{apperr.NewProblem("program", "required")})
+		}
+		if err := s.checkRunAllowed(profile, false); err != nil {
+			return WorkSubmission{}, err
+		}
+		if err := s.checkLevelRestriction(profile, level); err != nil {
+			return WorkSubmission{}, err
+		}
+		if err := validateProgram(level, input.Program); err != nil {
+			return WorkSubmission{}, err
+		}
+		work.Kind = WorkLevel
+		work.LevelID = level.ID
+		work.Program = append([]Instruction{}, input.Program...)
+		work.Title = localizeLevel(level, profile.Settings.Language).Name
+	default:
+		return WorkSubmission{}, ErrWorkInvalid.With("problems", []apperr.Problem{apperr.NewProblem("levelId", "required")})
+	}
+	now := time.Now()
+	if work.LevelID != "" {
+		if err := s.checkAssessmentFeedback(profile, work.LevelID, now.UnixMilli(), ErrAssessmentInProgress); err != nil {
+			return WorkSubmission{}, err
+		}
+	}
+	if title := strings.TrimSpace(input.Title); title != "" {
+		work.Title = title
+	}
+	if input.AssignmentID != "" {
+		if err := s.bindAssignmentWork(profile, &work, input.AssignmentID, input.ItemID); err != nil {
+			return WorkSubmission{}, err
+		}
+	}
+
+	result := newSimulator(level).run(work.Program)
+	work.Result = WorkResult{Success: result.Success, Steps: result.Steps, Stars: result.Stars, ErrorCode: result.ErrorCode}
+
+	work.Status = WorkPending
+	work.SubmittedAt = now.UnixMilli()
+	if existing := s.findWork(profile.ID, work.Kind, work.LevelID, work.ProjectID); existing != nil {
+		work.ID = existing.ID
+		work.CreatedAt = existing.CreatedAt
+		work.Reviews = existing.Reviews
+		if work.AssignmentID == "" {
+			work.AssignmentID, work.ItemID = existing.AssignmentID, existing.ItemID
+		}
+		work.Version = existing.Version
+		if existing.Status != WorkPending {
+			work.Version++
+		}
+	} else {
+		work.ID = uuid.NewString()
+		work.CreatedAt = work.SubmittedAt
+		work.Version = 1
+	}
+	work.Score, _ = s.scoreWork(&work, nil)
+	s.works[work.ID] = &work
+	s.recordActivity(profile.ID, now)
+	return s.workCopy(&work), nil
+}
+
+// Works lists the student's submissions, most recently submitted first.
+func (s *Service) Works(ctx context.Context, userID string) []WorkSubmission {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterWorks(func(work *WorkSubmission) bool { return work.OwnerID == profile.ID })
+}
+
+// Work returns one of the student's submissions.
+func (s *Service) Work(ctx context.Context, userID, workID string) (WorkSubmission, error) {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	work, ok := s.works[workID]
+	if !ok || work.OwnerID != profile.ID {
+		return WorkSubmission{}, ErrWorkNotFound.With("workId", workID)
+	}
+	return s.workCopy(work), nil
+}
+
+// ClassWorks lists the submissions of a class in a status, or all of them
+// when status is empty, most recently submitted first.
+func (s *Service) ClassWorks(ctx context.Context, classID string, status WorkStatus) []WorkSubmission {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterWorks(func(work *WorkSubmission) bool {
+		return work.ClassID == classID && (status == "" || work.Status == status)
+	})
+}
+
+// FindWork returns any submission.
+func (s *Service) FindWork(ctx context.Context, workID string) (WorkSubmission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	work, ok := s.works[workID]
+	if !ok {
+		return WorkSubmission{}, ErrWorkNotFound.With("workId", workID)
+	}
+	return s.workCopy(work), nil
+}
+
+// ReviewWork records a teacher's verdict on a pending submission and notifies
+// the student. When the class has a rubric for the submission it is scored
+// against the rubric's current criteria; approving needs every criterion the
+// simulator cannot check to be scored. Approving a level solution with more
+// stars than the student's best result raises the best result to them.
+func (s *Service) ReviewWork(ctx context.Context, workID, reviewerID string, review WorkReview) (WorkSubmission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	work, ok := s.works[workID]
+	if !ok {
+		return WorkSubmission{}, ErrWorkNotFound.With("workId", workID)
+	}
+	if work.Status != WorkPending {
+		return WorkSubmission{}, ErrWorkNotPending.With("workId", workID).With("status", string(work.Status))
+	}
+	score, problems := s.scoreWork(work, review.Scores)
+	if len(problems) == 0 && score != nil && !score.Complete && review.Status == WorkApproved {
+		for _, criterion := range score.Criteria {
+			if !criterion.Graded {
+				problems = append(problems, apperr.NewProblem("scores", "required", "criterionId", criterion.CriterionID))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return WorkSubmission{}, ErrReviewInvalid.With("problems", problems)
+	}
+
+	now := time.Now()
+	work.Score = score
+	work.Status = review.Status
+	work.Feedback = strings.TrimSpace(review.Feedback)
+	work.ReviewedBy = reviewerID
+	work.ReviewedAt = now.UnixMilli()
+	stars := work.Result.Stars
+	if review.Stars != nil {
+		stars = *review.Stars
+	}
+	work.Stars = &stars
+	work.Reviews = append(work.Reviews, WorkReviewRecord{
+		Version:    work.Version,
+		Status:     work.Status,
+		Feedback:   work.Feedback,
+		Stars:      stars,
+		Score:      cloneRubricScore(score),
+		Program:    append([]Instruction(nil), work.Program...),
+		Result:     work.Result,
+		ReviewedBy: reviewerID,
+		ReviewedAt: work.ReviewedAt,
+	})
+
+	if profile, ok := s.profiles[work.OwnerID]; ok && work.Kind == WorkLevel && work.Status == WorkApproved {
+		progress := profile.Progress[work.LevelID]
+		if previous := progress.Stars; stars > previous {
+			progress.Stars = stars
+			if progress.CompletedAt == 0 {
+				progress.CompletedAt = work.ReviewedAt
+			}
+			profile.Progress[work.LevelID] = progress
+			s.creditCompletion(profile.ID, work.LevelID, previous, stars, now)
+			s.recomputeDerivedState(profile)
+		}
+	}
+
+	s.notify(work.OwnerID, Notification{
+		Kind:  "work." + string(work.Status),
+		Ref:   work.ID,
+		Title: work.Title,
+		Body:  work.Feedback,
+	}, now)
+	return s.workCopy(work), nil
+}
+
+// Notifications returns the student's inbox.
+func (s *Service) Notifications(ctx context.Context, userID string) Notifications {
+	profile := s.ensureProfile(userID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inbox := Notifications{Items: make([]Notification, 0, len(s.notifications[profile.ID]))}
+	items := s.notifications[profile.ID]
+	for i := len(items) - 1; i >= 0; i-- {
+		inbox.Items = append(inbox.Items, items[i])
+		if !items[i].Read {
+			inbox.Unread++
+		}
+	}
+	return inbox
+}
+
+// MarkNotificationsRead marks the given notifications, or all of them when
+// ids is empty, as read.
+func (s *Service) MarkNotificationsRead(ctx context.Context, userID string, ids []string) Notifications {
+	profile := s.ensureProfile(userID)
+
+	s.mu.Lock()
+	items := s.notifications[profile.ID]
+	for i := range items {
+		if len(ids) == 0 || contains(ids, items[i].ID) {
+			items[i].Read = true
+		}
+	}
+	s.mu.Unlock()
+
+	return s.Notifications(ctx, profile.ID)
+}
+
+// notify adds a notification to a student's inbox. Callers must hold the
+// write lock.
+func (s *Service) notify(studentID string, notification Notification, at time.Time) {
+	notification.ID = uuid.NewString()
+	notification.CreatedAt = at.UnixMilli()
+	s.notifications[studentID] = append(s.notifications[studentID], notification)
+}
+
+// findWork returns the student's submission of a level or project. Callers
+// must hold the lock.
+func (s *Service) findWork(ownerID string, kind WorkKind, levelID, projectID string) *WorkSubmission {
+	for _, work := range s.works {
+		if work.OwnerID != ownerID || work.Kind != kind {
+			continue
+		}
+		if (kind == WorkProject && work.ProjectID == projectID) || (kind == WorkLevel && work.LevelID == levelID) {
+			return work
+		}
+	}
+	return nil
+}
+
+// filterWorks returns copies of the matching submissions, most recently
+// submitted first. Callers must hold the lock.
+func (s *Service) filterWorks(match func(*WorkSubmission) bool) []WorkSubmission {
+	works := make([]WorkSubmission, 0)
+	for _, work := range s.works {
+		if match(work) {
+			works = append(works, s.workCopy(work))
+		}
+	}
+	sort.Slice(works, func(i, j int) bool {
+		if works[i].SubmittedAt != works[j].SubmittedAt {
+			return works[i].SubmittedAt > works[j].SubmittedAt
+		}
+		return works[i].ID < works[j].ID
+	})
+	return works
+}
+
+func (s *Service) workCopy(work *WorkSubmission) WorkSubmission {
+	clone := *work
+	clone.Map = work.Map.clone()
+	clone.Program = append([]Instruction(nil), work.Program...)
+	if work.Stars != nil {
+		stars := *work.Stars
+		clone.Stars = &stars
+	}
+	clone.Score = cloneRubricScore(work.Score)
+	clone.Reviews = make([]WorkReviewRecord, len(work.Reviews))
+	for i, record := range work.Reviews {
+		record.Score = cloneRubricScore(record.Score)
+		clone.Reviews[i] = record
+	}
+	return clone
+}
@@ -0,0 +1,256 @@
+package teacher
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// Errors returned by analytics.
+var (
+	ErrUnknownAnalytics       = apperr.New(apperr.KindNotFound, "analytics.unknown_resource")
+	ErrInvalidAnalyticsFilter = apperr.New(apperr.KindInvalid, "analytics.invalid_filter")
+)
+
+// Analytics date ranges default to the last defaultAnalyticsDays days and
+// may span at most maxAnalyticsDays days.
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 366
+	analyticsDateLayout  = "2006-01-02"
+)
+
+// analyticsFilter is the parsed query of an analytics request. To is the
+// start of the day after the last day of the range.
+type analyticsFilter struct {
+	ClassID string
+	From    time.Time
+	To      time.Time
+}
+
+// analyticsScope is the classes and students an analytics request covers.
+type analyticsScope struct {
+	classes  []TeacherClassDetail
+	classIDs []string
+	students []string
+}
+
+// Analytics returns aggregated metrics for the requested resource:
+//
+//	progress  completed levels and stars per student, grouped by class
+//	heatmap   stars, runs and time per student and level
+//	funnel    students who attempted, completed and perfected each level
+//	attempts  average runs and hints per level
+//	stars     distribution of best results per level
+//	time      time on task per level
+//	errors    most common error codes per level
+//	timeline  class activity and completed levels per day
+//	misconceptions  misconceptions behind failed runs per class and student
+//
+// The query accepts classId to limit the result to one class of the teacher,
+// and from and to as inclusive dates (YYYY-MM-DD) to limit it to a date range.
+func (s *Service) Analytics(ctx context.Context, teacherID, resource string, query map[string]string) (map[string]any, error) {
+	resource = strings.Trim(resource, "/")
+	filter, err := parseAnalyticsFilter(query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	scope, err := s.analyticsScope(teacherID, filter.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	rng := student.AnalyticsRange{From: filter.From, To: filter.To}
+
+	payload := map[string]any{
+		"resource": resource,
+		"filters": map[string]string{
+			"classId": filter.ClassID,
+			"from":    filter.From.Format(analyticsDateLayout),
+			"to":      filter.To.AddDate(0, 0, -1).Format(analyticsDateLayout),
+		},
+	}
+	switch resource {
+	case "progress":
+		payload["classes"] = s.progressAnalytics(ctx, scope, rng)
+	case "heatmap":
+		payload["heatmap"] = s.students.StudentAnalytics(ctx, scope.students, rng)
+	case "funnel", "attempts", "stars", "time", "errors":
+		payload["levels"] = levelAnalyticsView(resource, s.students.LevelAnalytics(ctx, scope.classIDs, scope.students, rng))
+	case "timeline":
+		payload["timeline"] = s.students.DailyAnalytics(ctx, scope.students, rng)
+	case "misconceptions":
+		payload["classes"] = s.misconceptionAnalytics(ctx, scope, rng)
+	default:
+		return nil, ErrUnknownAnalytics.With("resource", resource)
+	}
+	return payload, nil
+}
+
+// ProgressAnalyticsClass is one class in the progress resource.
+type ProgressAnalyticsClass struct {
+	ClassID   string                     `json:"classId"`
+	ClassName string                     `json:"className"`
+	Students  []ProgressAnalyticsStudent `json:"students"`
+}
+
+// ProgressAnalyticsStudent is a student's completed levels and stars in the
+// date range.
+type ProgressAnalyticsStudent struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Completed int    `json:"completed"`
+	Stars     int    `json:"stars"`
+}
+
+func (s *Service) progressAnalytics(ctx context.Context, scope analyticsScope, rng student.AnalyticsRange) []ProgressAnalyticsClass {
+	classes := make([]ProgressAnalyticsClass, 0, len(scope.classes))
+	for _, class := range scope.classes {
+		ids := make([]string, 0, len(class.Students))
+		for _, member := range class.Students {
+			ids = append(ids, member.ID)
+		}
+		stats := s.students.StudentAnalytics(ctx, ids, rng)
+		entry := ProgressAnalyticsClass{ClassID: class.Class.ID, ClassName: class.Class.Name, Students: make([]ProgressAnalyticsStudent, 0, len(stats))}
+		for i, member := range class.Students {
+			entry.Students = append(entry.Students, ProgressAnalyticsStudent{ID: member.ID, Name: member.Name, Completed: stats[i].Completed, Stars: stats[i].Stars})
+		}
+		classes = append(classes, entry)
+	}
+	return classes
+}
+
+// MisconceptionAnalyticsClass is one class in the misconceptions resource:
+// the totals of the class and each student's misconceptions, most frequent
+// first.
+type MisconceptionAnalyticsClass struct {
+	ClassID   string                          `json:"classId"`
+	ClassName string                          `json:"className"`
+	Totals    []student.MisconceptionCount    `json:"totals"`
+	Students  []MisconceptionAnalyticsStudent `json:"students"`
+}
+
+// MisconceptionAnalyticsStudent is a student's misconceptions in the date
+// range.
+type MisconceptionAnalyticsStudent struct {
+	ID             string                       `json:"id"`
+	Name           string                       `json:"name"`
+	Misconceptions []student.MisconceptionCount `json:"misconceptions"`
+}
+
+func (s *Service) misconceptionAnalytics(ctx context.Context, scope analyticsScope, rng student.AnalyticsRange) []MisconceptionAnalyticsClass {
+	classes := make([]MisconceptionAnalyticsClass, 0, len(scope.classes))
+	for _, class := range scope.classes {
+		ids := make([]string, 0, len(class.Students))
+		for _, member := range class.Students {
+			ids = append(ids, member.ID)
+		}
+		report := s.students.Misconceptions(ctx, ids, rng)
+		entry := MisconceptionAnalyticsClass{ClassID: class.Class.ID, ClassName: class.Class.Name, Totals: report.Totals, Students: make([]MisconceptionAnalyticsStudent, 0, len(ids))}
+		for i, member := range class.Students {
+			entry.Students = append(entry.Students, MisconceptionAnalyticsStudent{ID: member.ID, Name: member.Name, Misconceptions: report.Students[i].Misconceptions})
+		}
+		classes = append(classes, entry)
+	}
+	return classes
+}
+
+// levelAnalyticsView keeps the level fields a resource reports.
+func levelAnalyticsView(resource string, levels []student.LevelAnalytics) []map[string]any {
+	views := make([]map[string]any, 0, len(levels))
+	for _, level := range levels {
+		view := map[string]any{"levelId": level.LevelID, "name": level.Name, "chapterId": level.ChapterID}
+		switch resource {
+		case "funnel":
+			view["students"] = level.Students
+			view["attempted"] = level.Attempted
+			view["completed"] = level.Completed
+			view["perfect"] = level.Perfect
+		case "attempts":
+			view["attempted"] = level.Attempted
+			view["attempts"] = level.Attempts
+			view["avgAttempts"] = level.AvgAttempts
+			view["hints"] = level.Hints
+			view["avgHints"] = level.AvgHints
+		case "stars":
+			view["attempted"] = level.Attempted
+			view["stars"] = level.Stars
+		case "time":
+			view["attempted"] = level.Attempted
+			view["totalSeconds"] = level.TotalSeconds
+			view["avgSeconds"] = level.AvgSeconds
+		case "errors":
+			view["attempts"] = level.Attempts
+			view["errors"] = level.Errors
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// analyticsScope resolves the classes an analytics request covers: the
+// given class of the teacher, or every class of the teacher not archived when
+// classID is empty.
+func (s *Service) analyticsScope(teacherID, classID string) (analyticsScope, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var scope analyticsScope
+	if classID != "" {
+		class, err := s.ownedClass(teacherID, classID)
+		if err != nil {
+			return analyticsScope{}, err
+		}
+		scope.classes = []TeacherClassDetail{cloneClassDetail(class)}
+	} else {
+		for _, class := range s.classes {
+			if class.Class.Archived || class.Class.OwnerID == "" || class.Class.OwnerID != teacherID {
+				continue
+			}
+			scope.classes = append(scope.classes, cloneClassDetail(class))
+		}
+		sort.Slice(scope.classes, func(i, j int) bool { return scope.classes[i].Class.ID < scope.classes[j].Class.ID })
+	}
+	for _, class := range scope.classes {
+		scope.classIDs = append(scope.classIDs, class.Class.ID)
+		for _, member := range class.Students {
+			scope.students = append(scope.students, member.ID)
+		}
+	}
+	return scope, nil
+}
+
+// parseAnalyticsFilter reads classId, from and to from the query. The range
+// defaults to the last defaultAnalyticsDays days up to today.
+func parseAnalyticsFilter(query map[string]string, now time.Time) (analyticsFilter, error) {
+	filter := analyticsFilter{ClassID: query["classId"]}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	last := today
+	if value := query["to"]; value != "" {
+		parsed, err := time.ParseInLocation(analyticsDateLayout, value, now.Location())
+		if err != nil {
+			return analyticsFilter{}, ErrInvalidAnalyticsFilter.With("field", "to")
+		}
+		last = parsed
+	}
+	filter.To = last.AddDate(0, 0, 1)
+	filter.From = last.AddDate(0, 0, 1-defaultAnalyticsDays)
+	if value := query["from"]; value != "" {
+		parsed, err := time.ParseInLocation(analyticsDateLayout, value, now.Location())
+		if err != nil {
+			return analyticsFilter{}, ErrInvalidAnalyticsFilter.With("field", "from")
+		}
+		filter.From = parsed
+	}
+	if !filter.From.Before(filter.To) {
+		return analyticsFilter{}, ErrInvalidAnalyticsFilter.With("field", "from")
+	}
+	if filter.From.AddDate(0, 0, maxAnalyticsDays).Before(filter.To) {
+		return analyticsFilter{}, ErrInvalidAnalyticsFilter.With("field", "to").With("maxDays", maxAnalyticsDays)
+	}
+	return filter, nil
+}
@@ -0,0 +1,61 @@
+package teacher
+
+import (
+	"context"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// CreateAssignment gives an assignment to a class of the teacher.
+func (s *Service) CreateAssignment(ctx context.Context, teacherID, classID string, input student.AssignmentInput) (student.Assignment, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return student.Assignment{}, err
+	}
+	return s.students.CreateAssignment(ctx, classID, teacherID, input)
+}
+
+// ClassAssignments lists the assignments of a class of the teacher, all of
+// them when status is empty.
+func (s *Service) ClassAssignments(ctx context.Context, teacherID, classID string, status student.AssignmentStatus) ([]student.Assignment, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return nil, err
+	}
+	return s.students.ClassAssignments(ctx, classID, status), nil
+}
+
+// CloseAssignment stops an assignment of a class of the teacher from
+// accepting work.
+func (s *Service) CloseAssignment(ctx context.Context, teacherID, classID, assignmentID string) (student.Assignment, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return student.Assignment{}, err
+	}
+	return s.students.CloseAssignment(ctx, classID, assignmentID)
+}
+
+// AssignmentMatrix returns the completion of every student of a class of the
+// teacher on an assignment.
+func (s *Service) AssignmentMatrix(ctx context.Context, teacherID, classID, assignmentID string) (student.AssignmentMatrix, error) {
+	roster, err := s.ownedRoster(teacherID, classID)
+	if err != nil {
+		return student.AssignmentMatrix{}, err
+	}
+	return s.students.AssignmentMatrix(ctx, classID, assignmentID, roster)
+}
+
+// AssessmentResults returns the submissions and scores of every student of
+// the class on an assessment.
+func (s *Service) AssessmentResults(ctx context.Context, teacherID, classID, assignmentID string) (student.AssessmentResults, error) {
+	roster, err := s.ownedRoster(teacherID, classID)
+	if err != nil {
+		return student.AssessmentResults{}, err
+	}
+	return s.students.AssessmentResults(ctx, classID, assignmentID, roster)
+}
+
+// ReleaseAssessment shows the students of the class their assessment results.
+func (s *Service) ReleaseAssessment(ctx context.Context, teacherID, classID, assignmentID string) (student.Assignment, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return student.Assignment{}, err
+	}
+	return s.students.ReleaseAssessment(ctx, classID, assignmentID)
+}
@@ -0,0 +1,405 @@
+package teacher
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// CourseInput carries the editable fields of a course.
+type CourseInput struct {
+	Name        string
+	Description string
+}
+
+// ChapterInput carries the editable fields of a chapter.
+type ChapterInput struct {
+	Title        string
+	Summary      string
+	Translations map[string]student.ChapterText
+}
+
+// CreateCourse creates an empty course owned by the teacher.
+func (s *Service) CreateCourse(ctx context.Context, teacherID string, input CourseInput) (TeacherCourse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course := TeacherCourse{
+		ID:          "course-" + uuid.NewString(),
+		Name:        input.Name,
+		Description: input.Description,
+		OwnerID:     teacherID,
+		Chapters:    []TeacherCourseChapter{},
+	}
+	s.courses = append(s.courses, course)
+	return course.Clone(), nil
+}
+
+// UpdateCourse changes the name and description of an owned course.
+func (s *Service) UpdateCourse(ctx context.Context, teacherID, courseID string, input CourseInput) (TeacherCourse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, err := s.ownedCourse(teacherID, courseID)
+	if err != nil {
+		return TeacherCourse{}, err
+	}
+	course.Name = input.Name
+	course.Description = input.Description
+	s.syncCourse(ctx, course)
+	return course.Clone(), nil
+}
+
+// DeleteCourse removes an owned course with all of its chapters and levels
+// and detaches it from every class.
+func (s *Service) DeleteCourse(ctx context.Context, teacherID, courseID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, err := s.ownedCourse(teacherID, courseID)
+	if err != nil {
+		return err
+	}
+	for _, chapter := range course.Chapters {
+		if err := s.students.DeleteChapter(ctx, chapter.ID); err != nil && err != student.ErrChapterNotFound {
+			return err
+		}
+	}
+
+	for _, detail := range s.classes {
+		filtered := detail.Courses[:0]
+		for _, assigned := range detail.Courses {
+			if assigned.ID != courseID {
+				filtered = append(filtered, assigned)
+			}
+		}
+		detail.Courses = filtered
+		detail.Class.LevelCount = detail.totalLevels()
+	}
+	for i := range s.courses {
+		if s.courses[i].ID == courseID {
+			s.courses = append(s.courses[:i], s.courses[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// CreateChapter appends a chapter to an owned course.
+func (s *Service) CreateChapter(ctx context.Context, teacherID, courseID string, input ChapterInput) (TeacherCourseChapter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, err := s.ownedCourse(teacherID, courseID)
+	if err != nil {
+		return TeacherCourseChapter{}, err
+	}
+	created, err := s.students.CreateChapter(ctx, teacherID, student.ChapterDefinition{
+		Title:        input.Title,
+		Summary:      input.Summary,
+		Translations: input.Translations,
+	})
+	if err != nil {
+		return TeacherCourseChapter{}, err
+	}
+
+	chapter := TeacherCourseChapter{
+		ID:      created.ID,
+		Title:   created.Title,
+		Summary: created.Summary,
+		Order:   len(course.Chapters) + 1,
+		Levels:  []TeacherCourseLevel{},
+	}
+	course.Chapters = append(course.Chapters, chapter)
+	s.syncCourse(ctx, course)
+	return chapter.Clone(), nil
+}
+
+// UpdateChapter changes the title and summary of an owned chapter.
+func (s *Service) UpdateChapter(ctx context.Context, teacherID, chapterID string, input ChapterInput) (TeacherCourseChapter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, idx, err := s.ownedChapter(teacherID, chapterID)
+	if err != nil {
+		return TeacherCourseChapter{}, err
+	}
+	if _, err := s.students.UpdateChapter(ctx, chapterID, input.Title, input.Summary, input.Translations); err != nil {
+		return TeacherCourseChapter{}, err
+	}
+	course.Chapters[idx].Title = input.Title
+	course.Chapters[idx].Summary = input.Summary
+	s.syncCourse(ctx, course)
+	return course.Chapters[idx].Clone(), nil
+}
+
+// DeleteChapter removes an owned chapter and its levels.
+func (s *Service) DeleteChapter(ctx context.Context, teacherID, chapterID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, idx, err := s.ownedChapter(teacherID, chapterID)
+	if err != nil {
+		return err
+	}
+	if err := s.students.DeleteChapter(ctx, chapterID); err != nil && err != student.ErrChapterNotFound {
+		return err
+	}
+	course.Chapters = append(course.Chapters[:idx], course.Chapters[idx+1:]...)
+	for i := range course.Chapters {
+		course.Chapters[i].Order = i + 1
+	}
+	s.syncCourse(ctx, course)
+	return nil
+}
+
+// ReorderChapters sets the order of an owned course's chapters. chapterIDs
+// must list every chapter of the course exactly once.
+func (s *Service) ReorderChapters(ctx context.Context, teacherID, courseID string, chapterIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, err := s.ownedCourse(teacherID, courseID)
+	if err != nil {
+		return err
+	}
+	positions, err := orderPositions(chapterIDs, course.chapterIDs())
+	if err != nil {
+		return err
+	}
+	if err := s.students.ReorderChapters(ctx, chapterIDs); err != nil {
+		return err
+	}
+	for i := range course.Chapters {
+		course.Chapters[i].Order = positions[course.Chapters[i].ID]
+	}
+	sort.Slice(course.Chapters, func(i, j int) bool { return course.Chapters[i].Order < course.Chapters[j].Order })
+	s.syncCourse(ctx, course)
+	return nil
+}
+
+// CreateLevel validates a level and appends it to an owned chapter.
+func (s *Service) CreateLevel(ctx context.Context, teacherID, chapterID string, definition student.LevelDefinition) (student.LevelDefinition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, idx, err := s.ownedChapter(teacherID, chapterID)
+	if err != nil {
+		return student.LevelDefinition{}, err
+	}
+	level, err := s.students.CreateLevel(ctx, chapterID, definition)
+	if err != nil {
+		return student.LevelDefinition{}, err
+	}
+	course.Chapters[idx].Levels = append(course.Chapters[idx].Levels, courseLevel(level))
+	s.syncCourse(ctx, course)
+	return level, nil
+}
+
+// UpdateLevel validates and publishes a new revision of an owned level.
+func (s *Service) UpdateLevel(ctx context.Context, teacherID, levelID string, definition student.LevelDefinition) (student.LevelDefinition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, chapterIdx, levelIdx, err := s.ownedLevel(teacherID, levelID)
+	if err != nil {
+		return student.LevelDefinition{}, err
+	}
+	level, err := s.students.UpdateLevel(ctx, levelID, definition)
+	if err != nil {
+		return student.LevelDefinition{}, err
+	}
+	course.Chapters[chapterIdx].Levels[levelIdx] = courseLevel(level)
+	s.syncCourse(ctx, course)
+	return level, nil
+}
+
+// DeleteLevel removes an owned level.
+func (s *Service) DeleteLevel(ctx context.Context, teacherID, levelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, chapterIdx, levelIdx, err := s.ownedLevel(teacherID, levelID)
+	if err != nil {
+		return err
+	}
+	if err := s.students.DeleteLevel(ctx, levelID); err != nil && err != student.ErrLevelNotFound {
+		return err
+	}
+	levels := course.Chapters[chapterIdx].Levels
+	course.Chapters[chapterIdx].Levels = append(levels[:levelIdx], levels[levelIdx+1:]...)
+	s.syncCourse(ctx, course)
+	return nil
+}
+
+// ReorderLevels sets the play order of an owned chapter's levels.
+func (s *Service) ReorderLevels(ctx context.Context, teacherID, chapterID string, levelIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, idx, err := s.ownedChapter(teacherID, chapterID)
+	if err != nil {
+		return err
+	}
+	if err := s.students.ReorderLevels(ctx, chapterID, levelIDs); err != nil {
+		return err
+	}
+
+	byID := make(map[string]TeacherCourseLevel, len(course.Chapters[idx].Levels))
+	for _, level := range course.Chapters[idx].Levels {
+		byID[level.ID] = level
+	}
+	ordered := make([]TeacherCourseLevel, 0, len(levelIDs))
+	for _, id := range levelIDs {
+		ordered = append(ordered, byID[id])
+	}
+	course.Chapters[idx].Levels = ordered
+	s.syncCourse(ctx, course)
+	return nil
+}
+
+// ValidateLevel checks a level definition without storing it.
+func (s *Service) ValidateLevel(ctx context.Context, definition student.LevelDefinition) (student.LevelCheck, error) {
+	return s.students.ValidateLevel(ctx, definition)
+}
+
+// ownedCourse returns the course if the teacher authored it. Callers must
+// hold the lock.
+func (s *Service) ownedCourse(teacherID, courseID string) (*TeacherCourse, error) {
+	for i := range s.courses {
+		if s.courses[i].ID != courseID {
+			continue
+		}
+		if s.courses[i].OwnerID == "" || s.courses[i].OwnerID != teacherID {
+			return nil, ErrNotOwner
+		}
+		return &s.courses[i], nil
+	}
+	return nil, ErrCourseNotFound
+}
+
+// ownedChapter locates a chapter inside the teacher's courses. Callers must
+// hold the lock.
+func (s *Service) ownedChapter(teacherID, chapterID string) (*TeacherCourse, int, error) {
+	for i := range s.courses {
+		for idx, chapter := range s.courses[i].Chapters {
+			if chapter.ID != chapterID {
+				continue
+			}
+			if s.courses[i].OwnerID == "" || s.courses[i].OwnerID != teacherID {
+				return nil, -1, ErrNotOwner
+			}
+			return &s.courses[i], idx, nil
+		}
+	}
+	return nil, -1, student.ErrChapterNotFound
+}
+
+// ownedLevel locates a level inside the teacher's courses. Callers must hold
+// the lock.
+func (s *Service) ownedLevel(teacherID, levelID string) (*TeacherCourse, int, int, error) {
+	for i := range s.courses {
+		for chapterIdx, chapter := range s.courses[i].Chapters {
+			for levelIdx, level := range chapter.Levels {
+				if level.ID != levelID {
+					continue
+				}
+				if s.courses[i].OwnerID == "" || s.courses[i].OwnerID != teacherID {
+					return nil, -1, -1, ErrNotOwner
+				}
+				return &s.courses[i], chapterIdx, levelIdx, nil
+			}
+		}
+	}
+	return nil, -1, -1, student.ErrLevelNotFound
+}
+
+// syncCourse refreshes the copies of an edited course held by the classes it
+// is assigned to and shares any new chapters with those classes. Callers must
+// hold the write lock.
+func (s *Service) syncCourse(ctx context.Context, course *TeacherCourse) {
+	for classID, detail := range s.classes {
+		for i := range detail.Courses {
+			if detail.Courses[i].ID != course.ID {
+				continue
+			}
+			detail.Courses[i] = course.Clone()
+			detail.Class.LevelCount = detail.totalLevels()
+			s.students.AssignChapters(ctx, classID, course.chapterIDs())
+			s.syncUnlocks(ctx, detail)
+		}
+	}
+}
+
+// CourseTranslations reports how completely a course visible to the teacher
+// is translated, for one locale or, when locale is empty, for every locale
+// content can be translated into.
+func (s *Service) CourseTranslations(ctx context.Context, teacherID, courseID, locale string) ([]student.TranslationReport, error) {
+	locales := student.TranslationLocales()
+	if locale != "" {
+		if !student.IsTranslationLocale(locale) {
+			return nil, student.ErrUnsupportedLanguage.With("language", locale)
+		}
+		locales = []string{locale}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	course := s.findCourse(courseID)
+	if course == nil || (course.OwnerID != "" && course.OwnerID != teacherID) {
+		return nil, ErrCourseNotFound
+	}
+	var levelIDs []string
+	for _, chapter := range course.Chapters {
+		for _, level := range chapter.Levels {
+			levelIDs = append(levelIDs, level.ID)
+		}
+	}
+	reports := make([]student.TranslationReport, 0, len(locales))
+	for _, locale := range locales {
+		reports = append(reports, s.students.Translations(ctx, course.chapterIDs(), levelIDs, locale))
+	}
+	return reports, nil
+}
+
+func (c TeacherCourse) chapterIDs() []string {
+	ids := make([]string, 0, len(c.Chapters))
+	for _, chapter := range c.Chapters {
+		ids = append(ids, chapter.ID)
+	}
+	return ids
+}
+
+func courseLevel(level student.LevelDefinition) TeacherCourseLevel {
+	reward := TeacherCourseLevelReward{Stars: level.Rewards.Stars}
+	if level.Rewards.Outfit != "" {
+		outfit := level.Rewards.Outfit
+		reward.Outfit = &outfit
+	}
+	return TeacherCourseLevel{ID: level.ID, Name: level.Name, BestSteps: level.BestSteps, Rewards: reward}
+}
+
+// orderPositions maps each ID to its 1-based position after checking that
+// ids is a permutation of existing.
+func orderPositions(ids, existing []string) (map[string]int, error) {
+	if len(ids) != len(existing) {
+		return nil, student.ErrInvalidOrder
+	}
+	positions := make(map[string]int, len(ids))
+	for i, id := range ids {
+		if _, dup := positions[id]; dup {
+			return nil, student.ErrInvalidOrder
+		}
+		positions[id] = i + 1
+	}
+	for _, id := range existing {
+		if _, ok := positions[id]; !ok {
+			return nil, student.ErrInvalidOrder
+		}
+	}
+	return positions, nil
+}
@@ -0,0 +1,54 @@
+package teacher
+
+import (
+	"context"
+	"errors"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// Badges lists the built-in badges and the badges the teacher created.
+func (s *Service) Badges(ctx context.Context, teacherID string) []student.BadgeDefinition {
+	badges := s.students.Badges(ctx)
+	visible := badges[:0]
+	for _, badge := range badges {
+		if badge.OwnerID == "" || badge.OwnerID == teacherID {
+			visible = append(visible, badge)
+		}
+	}
+	return visible
+}
+
+// SaveBadge creates a badge owned by the teacher or replaces one they own.
+// Built-in badges cannot be changed.
+func (s *Service) SaveBadge(ctx context.Context, teacherID string, badge student.BadgeDefinition) (student.BadgeDefinition, error) {
+	if err := s.ownedBadge(ctx, teacherID, badge.ID, true); err != nil {
+		return student.BadgeDefinition{}, err
+	}
+	badge.OwnerID = teacherID
+	return s.students.SaveBadge(ctx, badge)
+}
+
+// DeleteBadge removes a badge the teacher owns.
+func (s *Service) DeleteBadge(ctx context.Context, teacherID, badgeID string) error {
+	if err := s.ownedBadge(ctx, teacherID, badgeID, false); err != nil {
+		return err
+	}
+	return s.students.DeleteBadge(ctx, badgeID)
+}
+
+// ownedBadge checks that the badge belongs to the teacher. A badge that does
+// not exist yet passes when create is set.
+func (s *Service) ownedBadge(ctx context.Context, teacherID, badgeID string, create bool) error {
+	badge, err := s.students.Badge(ctx, badgeID)
+	if errors.Is(err, student.ErrBadgeNotFound) && create {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if badge.OwnerID != teacherID {
+		return ErrNotOwner
+	}
+	return nil
+}
@@ -0,0 +1,670 @@
+package teacher
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+const (
+	// defaultHintLimit is the hint limit of classes created without one.
+	defaultHintLimit = 3
+	// defaultHintWindowMinutes is the window the hint limit applies to in
+	// classes created without one: about one lesson, so a student who used
+	// up the limit gets hints again in the next lesson.
+	defaultHintWindowMinutes = 30
+	// maxHintWindowMinutes bounds the hint window to half a school day.
+	maxHintWindowMinutes = 240
+	// maxInviteUses bounds the configurable uses of an invite code.
+	maxInviteUses = 1000
+	// maxRosterRows bounds the students imported from one roster file.
+	maxRosterRows = 500
+	// inviteCodeAlphabet leaves out characters that are easily confused when
+	// read aloud or copied from a board, such as 0/O and 1/I.
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	inviteCodeLength   = 6
+)
+
+// Errors returned by class management.
+var (
+	ErrInvalidClass    = apperr.New(apperr.KindUnprocessable, "class.invalid")
+	ErrClassArchived   = apperr.New(apperr.KindConflict, "class.archived")
+	ErrInvalidInvite   = apperr.New(apperr.KindUnprocessable, "invite.invalid")
+	ErrInviteExpired   = apperr.New(apperr.KindConflict, "invite.expired")
+	ErrInviteExhausted = apperr.New(apperr.KindConflict, "invite.exhausted")
+	ErrInvalidRoster   = apperr.New(apperr.KindUnprocessable, "roster.invalid")
+	ErrNotClassTeacher = apperr.New(apperr.KindForbidden, "class.not_teacher")
+)
+
+// InviteStatus tells whether students can still join with an invite code.
+type InviteStatus string
+
+const (
+	InviteActive    InviteStatus = "active"
+	InviteExpired   InviteStatus = "expired"
+	InviteExhausted InviteStatus = "exhausted"
+	InviteArchived  InviteStatus = "archived"
+)
+
+// ClassInvite is the code students use to join a class. MaxUses of zero lets
+// any number of students join and ExpiresAt of zero never expires. Status is
+// derived when the class is read.
+type ClassInvite struct {
+	Code      string       `json:"code"`
+	MaxUses   int          `json:"maxUses"`
+	Uses      int          `json:"uses"`
+	ExpiresAt int64        `json:"expiresAt"`
+	CreatedAt int64        `json:"createdAt"`
+	Status    InviteStatus `json:"status"`
+}
+
+func (i ClassInvite) status(now int64, archived bool) InviteStatus {
+	switch {
+	case archived:
+		return InviteArchived
+	case i.ExpiresAt != 0 && now >= i.ExpiresAt:
+		return InviteExpired
+	case i.MaxUses != 0 && i.Uses >= i.MaxUses:
+		return InviteExhausted
+	default:
+		return InviteActive
+	}
+}
+
+// InviteSettings configures a new invite code.
+type InviteSettings struct {
+	MaxUses   int   `json:"maxUses"`
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// ClassInput carries the fields of a new class. Courses are attached to the
+// class as with AssignCourseToClass.
+type ClassInput struct {
+	Name              string         `json:"name"`
+	HintLimit         int            `json:"hintLimit"`
+	HintWindowMinutes int            `json:"hintWindowMinutes"`
+	CourseIDs         []string       `json:"courseIds"`
+	Invite            InviteSettings `json:"invite"`
+}
+
+// ClassUpdate changes the given fields of a class. Archiving a class hides it
+// from the class list and analytics and stops students from joining it.
+type ClassUpdate struct {
+	Name              *string `json:"name"`
+	HintLimit         *int    `json:"hintLimit"`
+	HintWindowMinutes *int    `json:"hintWindowMinutes"`
+	Archived          *bool   `json:"archived"`
+}
+
+// RosterSkipReason explains why a roster row was not imported.
+type RosterSkipReason string
+
+const (
+	RosterMissingName     RosterSkipReason = "missing_name"
+	RosterDuplicateRow    RosterSkipReason = "duplicate_row"
+	RosterDuplicateName   RosterSkipReason = "duplicate_name"
+	RosterAlreadyEnrolled RosterSkipReason = "already_enrolled"
+	RosterInOtherClass    RosterSkipReason = "in_other_class"
+)
+
+// RosterSkip is a roster row that was not imported. Line is the line of the
+// row in the file, starting at 1.
+type RosterSkip struct {
+	Line      int              `json:"line"`
+	Name      string           `json:"name"`
+	StudentID string           `json:"studentId,omitempty"`
+	Reason    RosterSkipReason `json:"reason"`
+}
+
+// RosterImport reports the students added to a class from a roster file. A
+// dry run reports the same without changing the class.
+type RosterImport struct {
+	ClassID string           `json:"classId"`
+	DryRun  bool             `json:"dryRun"`
+	Added   []TeacherStudent `json:"added"`
+	Skipped []RosterSkip     `json:"skipped"`
+}
+
+// CreateClass creates a class with a fresh invite code.
+func (s *Service) CreateClass(ctx context.Context, teacherID string, input ClassInput) (TeacherClassDetail, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return TeacherClassDetail{}, ErrInvalidClass.With("field", "name")
+	}
+	hintLimit := defaultHintLimit
+	if input.HintLimit != 0 {
+		hintLimit = clampHintLimit(input.HintLimit)
+	}
+	hintWindow := defaultHintWindowMinutes
+	if input.HintWindowMinutes != 0 {
+		hintWindow = clampHintWindow(input.HintWindowMinutes)
+	}
+	now := time.Now()
+	if err := validateInvite(input.Invite, now); err != nil {
+		return TeacherClassDetail{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	courses := make([]*TeacherCourse, 0, len(input.CourseIDs))
+	for _, courseID := range input.CourseIDs {
+		course := s.findCourse(courseID)
+		if course == nil {
+			return TeacherClassDetail{}, ErrCourseNotFound.With("courseId", courseID)
+		}
+		courses = append(courses, course)
+	}
+
+	detail := &TeacherClassDetail{
+		Class: TeacherClassInfo{
+			ID:                "class-" + uuid.NewString(),
+			Name:              name,
+			OwnerID:           teacherID,
+			HintLimit:         hintLimit,
+			HintWindowMinutes: hintWindow,
+		},
+		Students:         []TeacherStudent{},
+		Courses:          []TeacherCourse{},
+		RecentActivities: []TeacherActivity{},
+	}
+	s.issueInvite(detail, input.Invite, now)
+	s.classes[detail.Class.ID] = detail
+	s.configureHints(ctx, detail)
+	for _, course := range courses {
+		s.attachCourse(ctx, detail, course)
+	}
+	return s.classView(ctx, detail, now), nil
+}
+
+// UpdateClass renames, reconfigures, archives or restores a class of the
+// teacher.
+func (s *Service) UpdateClass(ctx context.Context, teacherID, classID string, update ClassUpdate) (TeacherClassDetail, error) {
+	var name string
+	if update.Name != nil {
+		if name = strings.TrimSpace(*update.Name); name == "" {
+			return TeacherClassDetail{}, ErrInvalidClass.With("field", "name")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return TeacherClassDetail{}, err
+	}
+	now := time.Now()
+	if update.Name != nil {
+		detail.Class.Name = name
+	}
+	if update.HintLimit != nil {
+		detail.Class.HintLimit = clampHintLimit(*update.HintLimit)
+	}
+	if update.HintWindowMinutes != nil {
+		detail.Class.HintWindowMinutes = clampHintWindow(*update.HintWindowMinutes)
+	}
+	if update.HintLimit != nil || update.HintWindowMinutes != nil {
+		s.configureHints(ctx, detail)
+	}
+	if update.Archived != nil && *update.Archived != detail.Class.Archived {
+		detail.Class.Archived = *update.Archived
+		detail.Class.ArchivedAt = 0
+		if detail.Class.Archived {
+			detail.Class.ArchivedAt = now.UnixMilli()
+		}
+	}
+	return s.classView(ctx, detail, now), nil
+}
+
+// RegenerateInvite replaces the invite code of a class. The old code and any
+// demo alias of it stop working immediately.
+func (s *Service) RegenerateInvite(ctx context.Context, teacherID, classID string, settings InviteSettings) (ClassInvite, error) {
+	now := time.Now()
+	if err := validateInvite(settings, now); err != nil {
+		return ClassInvite{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return ClassInvite{}, err
+	}
+	if detail.Class.Archived {
+		return ClassInvite{}, ErrClassArchived.With("classId", classID)
+	}
+	for code, id := range s.invites {
+		if id == classID {
+			delete(s.invites, code)
+		}
+	}
+	s.issueInvite(detail, settings, now)
+	return s.classView(ctx, detail, now).Class.Invite, nil
+}
+
+// ExpireInvite stops the current invite code of a class from admitting more
+// students. Students who already joined stay in the class.
+func (s *Service) ExpireInvite(ctx context.Context, teacherID, classID string) (ClassInvite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return ClassInvite{}, err
+	}
+	now := time.Now()
+	if invite := &detail.Class.Invite; invite.ExpiresAt == 0 || invite.ExpiresAt > now.UnixMilli() {
+		invite.ExpiresAt = now.UnixMilli()
+	}
+	return s.classView(ctx, detail, now).Class.Invite, nil
+}
+
+// JoinClass admits a student to the class using the invite code and counts
+// the use. It returns an empty class ID when no class uses the code.
+func (s *Service) JoinClass(ctx context.Context, inviteCode, studentID, name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	classID, ok := s.invites[normalizeInviteCode(inviteCode)]
+	if !ok {
+		return "", nil
+	}
+	detail := s.classes[classID]
+	now := time.Now()
+	switch detail.Class.Invite.status(now.UnixMilli(), detail.Class.Archived) {
+	case InviteArchived:
+		return "", ErrClassArchived.With("classId", classID)
+	case InviteExpired:
+		return "", ErrInviteExpired
+	case InviteExhausted:
+		return "", ErrInviteExhausted
+	}
+	detail.Class.Invite.Uses++
+	s.enroll(ctx, detail, TeacherStudent{ID: studentID, Name: name, LastActiveAt: now.UnixMilli()})
+	return classID, nil
+}
+
+// ImportRoster adds the students listed in a CSV file to a class. The file
+// has a name column and an optional student ID column, either found by a
+// header row (name/姓名, studentId/学号) or taken as the first two columns.
+// Students without an ID get a new one; listed IDs must not belong to
+// another class, which is what TransferStudent is for.
+func (s *Service) ImportRoster(ctx context.Context, teacherID, classID string, data io.Reader, dryRun bool) (RosterImport, error) {
+	rows, err := parseRoster(data)
+	if err != nil {
+		return RosterImport{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return RosterImport{}, err
+	}
+	if detail.Class.Archived {
+		return RosterImport{}, ErrClassArchived.With("classId", classID)
+	}
+
+	report := RosterImport{ClassID: classID, DryRun: dryRun, Added: []TeacherStudent{}, Skipped: []RosterSkip{}}
+	names := make(map[string]bool)
+	for _, member := range detail.Students {
+		names[member.Name] = true
+	}
+	seenNames := make(map[string]bool)
+	seenIDs := make(map[string]bool)
+	for _, row := range rows {
+		skip := RosterSkip{Line: row.line, Name: row.name, StudentID: row.studentID}
+		switch {
+		case row.name == "":
+			skip.Reason = RosterMissingName
+		case seenNames[row.name] && row.studentID == "", row.studentID != "" && seenIDs[row.studentID]:
+			skip.Reason = RosterDuplicateRow
+		case row.studentID != "" && s.classOf(row.studentID) == classID:
+			skip.Reason = RosterAlreadyEnrolled
+		case row.studentID != "" && s.classOf(row.studentID) != "":
+			skip.Reason = RosterInOtherClass
+		case row.studentID == "" && names[row.name]:
+			skip.Reason = RosterDuplicateName
+		}
+		seenNames[row.name] = true
+		if row.studentID != "" {
+			seenIDs[row.studentID] = true
+		}
+		if skip.Reason != "" {
+			report.Skipped = append(report.Skipped, skip)
+			continue
+		}
+
+		member := TeacherStudent{ID: row.studentID, Name: row.name, TotalLevels: detail.totalLevels()}
+		if member.ID == "" {
+			member.ID = "student-" + uuid.NewString()
+		}
+		names[row.name] = true
+		report.Added = append(report.Added, member)
+	}
+
+	if !dryRun {
+		for _, member := range report.Added {
+			s.enroll(ctx, detail, member)
+		}
+	}
+	return report, nil
+}
+
+// RemoveStudent takes a student off the class roster. Their progress and
+// submitted work are kept.
+func (s *Service) RemoveStudent(ctx context.Context, teacherID, classID, studentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return err
+	}
+	if _, ok := removeMember(detail, studentID); !ok {
+		return ErrStudentNotFound
+	}
+	s.students.WithdrawStudent(ctx, studentID)
+	return nil
+}
+
+// TransferStudent moves a student to another class, whose policies and
+// content apply to the student from then on. The teacher must own both
+// classes.
+func (s *Service) TransferStudent(ctx context.Context, teacherID, classID, studentID, targetClassID string) (TeacherStudent, error) {
+	if targetClassID == classID {
+		return TeacherStudent{}, ErrInvalidClass.With("field", "targetClassId")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return TeacherStudent{}, err
+	}
+	target, err := s.ownedClass(teacherID, targetClassID)
+	if errors.Is(err, ErrClassNotFound) {
+		return TeacherStudent{}, ErrClassNotFound.With("classId", targetClassID)
+	}
+	if err != nil {
+		return TeacherStudent{}, err
+	}
+	if target.Class.Archived {
+		return TeacherStudent{}, ErrClassArchived.With("classId", targetClassID)
+	}
+	member, ok := removeMember(detail, studentID)
+	if !ok {
+		return TeacherStudent{}, ErrStudentNotFound
+	}
+	member.TotalLevels = target.totalLevels()
+	s.enroll(ctx, target, member)
+	return member, nil
+}
+
+// ClassRoster returns the students of a class to its teacher. Other teachers
+// are refused, as are all teachers for classes without an owner.
+func (s *Service) ClassRoster(ctx context.Context, teacherID, classID string) ([]TeacherStudent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]TeacherStudent(nil), detail.Students...), nil
+}
+
+// ownedClass returns a class if the teacher owns it. Callers must hold the
+// lock.
+func (s *Service) ownedClass(teacherID, classID string) (*TeacherClassDetail, error) {
+	detail, ok := s.classes[classID]
+	if !ok {
+		return nil, ErrClassNotFound
+	}
+	if detail.Class.OwnerID == "" || detail.Class.OwnerID != teacherID {
+		return nil, ErrNotClassTeacher.With("classId", classID)
+	}
+	return detail, nil
+}
+
+// ensureOwnedClass reports whether the teacher owns the class.
+func (s *Service) ensureOwnedClass(teacherID, classID string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err := s.ownedClass(teacherID, classID)
+	return err
+}
+
+// ownedRoster lists the students of a class the teacher owns.
+func (s *Service) ownedRoster(teacherID, classID string) ([]student.ClassMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return nil, err
+	}
+	roster := make([]student.ClassMember, 0, len(detail.Students))
+	for _, member := range detail.Students {
+		roster = append(roster, student.ClassMember{ID: member.ID, Name: member.Name})
+	}
+	return roster, nil
+}
+
+// enroll adds a student to the roster of a class. Callers must hold the lock.
+func (s *Service) enroll(ctx context.Context, detail *TeacherClassDetail, member TeacherStudent) {
+	if member.TotalLevels == 0 {
+		member.TotalLevels = detail.totalLevels()
+	}
+	detail.Students = append(detail.Students, member)
+	s.students.EnrollStudent(ctx, member.ID, detail.Class.ID)
+}
+
+// classOf returns the class whose roster lists the student, or "". Callers
+// must hold the lock.
+func (s *Service) classOf(studentID string) string {
+	for classID, detail := range s.classes {
+		for _, member := range detail.Students {
+			if member.ID == studentID {
+				return classID
+			}
+		}
+	}
+	return ""
+}
+
+// issueInvite gives a class a new invite code. Callers must hold the lock.
+func (s *Service) issueInvite(detail *TeacherClassDetail, settings InviteSettings, now time.Time) {
+	code := newInviteCode()
+	for _, taken := s.invites[normalizeInviteCode(code)]; taken; _, taken = s.invites[normalizeInviteCode(code)] {
+		code = newInviteCode()
+	}
+	detail.Class.Invite = ClassInvite{Code: code, MaxUses: settings.MaxUses, ExpiresAt: settings.ExpiresAt, CreatedAt: now.UnixMilli()}
+	detail.Class.InviteCode = code
+	s.invites[normalizeInviteCode(code)] = detail.Class.ID
+}
+
+// classView copies a class for callers, deriving the statistics and the
+// invite status. Callers must hold the lock.
+func (s *Service) classView(ctx context.Context, detail *TeacherClassDetail, now time.Time) TeacherClassDetail {
+	view := cloneClassDetail(detail)
+	s.fillStatistics(ctx, &view)
+	view.Class.Invite.Status = view.Class.Invite.status(now.UnixMilli(), view.Class.Archived)
+	return view
+}
+
+// attachCourse adds a course to a class unless it is already attached.
+// Callers must hold the lock.
+func (s *Service) attachCourse(ctx context.Context, detail *TeacherClassDetail, course *TeacherCourse) {
+	for _, existing := range detail.Courses {
+		if existing.ID == course.ID {
+			return
+		}
+	}
+	detail.Courses = append(detail.Courses, course.Clone())
+	detail.Class.LevelCount = detail.totalLevels()
+	if course.OwnerID != "" {
+		s.students.AssignChapters(ctx, detail.Class.ID, course.chapterIDs())
+	}
+	if course.UnlockPolicy != nil {
+		s.syncUnlocks(ctx, detail)
+	}
+}
+
+func removeMember(detail *TeacherClassDetail, studentID string) (TeacherStudent, bool) {
+	for i, member := range detail.Students {
+		if member.ID == studentID {
+			detail.Students = append(detail.Students[:i], detail.Students[i+1:]...)
+			return member, true
+		}
+	}
+	return TeacherStudent{}, false
+}
+
+func validateInvite(settings InviteSettings, now time.Time) error {
+	if settings.MaxUses < 0 || settings.MaxUses > maxInviteUses {
+		return ErrInvalidInvite.With("field", "maxUses").With("max", maxInviteUses)
+	}
+	if settings.ExpiresAt != 0 && settings.ExpiresAt <= now.UnixMilli() {
+		return ErrInvalidInvite.With("field", "expiresAt")
+	}
+	return nil
+}
+
+func clampHintLimit(hintLimit int) int {
+	if hintLimit < 1 {
+		return 1
+	}
+	if hintLimit > 20 {
+		return 20
+	}
+	return hintLimit
+}
+
+func clampHintWindow(minutes int) int {
+	if minutes < 1 {
+		return 1
+	}
+	if minutes > maxHintWindowMinutes {
+		return maxHintWindowMinutes
+	}
+	return minutes
+}
+
+// configureHints passes the hint limit and window of a class on to the
+// student service. Callers must hold the lock.
+func (s *Service) configureHints(ctx context.Context, detail *TeacherClassDetail) {
+	window := time.Duration(detail.Class.HintWindowMinutes) * time.Minute
+	s.students.ConfigureClass(ctx, detail.Class.ID, detail.Class.HintLimit, window)
+}
+
+func newInviteCode() string {
+	code := make([]byte, inviteCodeLength)
+	max := big.NewInt(int64(len(inviteCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+	return "CA-" + string(code)
+}
+
+// normalizeInviteCode ignores case, surrounding space and dashes so that
+// codes can be typed the way students read them.
+func normalizeInviteCode(code string) string {
+	return strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(code)), "-", "")
+}
+
+type rosterRow struct {
+	line      int
+	name      string
+	studentID string
+}
+
+// parseRoster reads the rows of a roster CSV file.
+func parseRoster(data io.Reader) ([]rosterRow, error) {
+	raw, err := io.ReadAll(data)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(raw, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	nameColumn, idColumn := 0, 1
+	rows := make([]rosterRow, 0)
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, ErrInvalidRoster.With("reason", "malformed").With("line", parseErr.Line)
+			}
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if first {
+			if name, id, ok := rosterHeader(record); ok {
+				nameColumn, idColumn = name, id
+				continue
+			}
+		}
+		row := rosterRow{line: line}
+		if nameColumn < len(record) {
+			row.name = strings.TrimSpace(record[nameColumn])
+		}
+		if idColumn >= 0 && idColumn < len(record) {
+			row.studentID = strings.TrimSpace(record[idColumn])
+		}
+		if row.name == "" && row.studentID == "" {
+			continue
+		}
+		rows = append(rows, row)
+		if len(rows) > maxRosterRows {
+			return nil, ErrInvalidRoster.With("reason", "too_many_rows").With("max", maxRosterRows)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, ErrInvalidRoster.With("reason", "empty")
+	}
+	return rows, nil
+}
+
+// rosterHeader finds the name and student ID columns of a header row. The ID
+// column is -1 when the header has none.
+func rosterHeader(record []string) (name, id int, ok bool) {
+	name, id = -1, -1
+	for i, field := range record {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "name", "姓名":
+			name = i
+		case "studentid", "student_id", "id", "学号":
+			id = i
+		}
+	}
+	if name < 0 {
+		return 0, 1, false
+	}
+	return name, id, true
+}
@@ -0,0 +1,38 @@
+package teacher
+
+import (
+	"context"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// ClassControls are the live controls a teacher applies to a class.
+type ClassControls = student.ClassControls
+
+// ClassControls returns the controls in effect for a class to its teacher.
+func (s *Service) ClassControls(ctx context.Context, teacherID, classID string) (ClassControls, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.ownedClass(teacherID, classID); err != nil {
+		return ClassControls{}, err
+	}
+	return s.students.ClassControls(ctx, classID), nil
+}
+
+// UpdateClassControls replaces the controls of a class. Students are held to
+// them from their next request and receive them over their run stream.
+// Archived classes cannot be controlled.
+func (s *Service) UpdateClassControls(ctx context.Context, teacherID, classID string, controls ClassControls) (ClassControls, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return ClassControls{}, err
+	}
+	if detail.Class.Archived {
+		return ClassControls{}, ErrClassArchived.With("classId", classID)
+	}
+	return s.students.SetClassControls(ctx, classID, controls)
+}
@@ -0,0 +1,429 @@
+package teacher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+	"github.com/codeadventurers/api-go/internal/levelpack"
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// ErrInvalidPack is returned when an import is attempted with levels that
+// fail validation. The accompanying report lists the problems.
+var ErrInvalidPack = apperr.New(apperr.KindUnprocessable, "levelpack.invalid")
+
+// ImportAction describes what an import does to a piece of content.
+type ImportAction string
+
+const (
+	ImportActionCreate    ImportAction = "create"
+	ImportActionUpdate    ImportAction = "update"
+	ImportActionUnchanged ImportAction = "unchanged"
+	// ImportActionRetained marks existing content missing from the pack. Imports
+	// never delete, so such content is kept as is.
+	ImportActionRetained ImportAction = "retained"
+)
+
+// ImportChange is one line of an import diff.
+type ImportChange struct {
+	Kind     string           `json:"kind"`
+	ID       string           `json:"id"`
+	SourceID string           `json:"sourceId,omitempty"`
+	Name     string           `json:"name"`
+	Action   ImportAction     `json:"action"`
+	Fields   []string         `json:"fields,omitempty"`
+	Problems []apperr.Problem `json:"problems,omitempty"`
+}
+
+// ImportReport summarises an import or a dry run of it.
+type ImportReport struct {
+	DryRun   bool           `json:"dryRun"`
+	Valid    bool           `json:"valid"`
+	CourseID string         `json:"courseId"`
+	Changes  []ImportChange `json:"changes"`
+}
+
+// ExportCourse builds a level pack from a course visible to the teacher.
+func (s *Service) ExportCourse(ctx context.Context, teacherID, courseID string) (levelpack.Pack, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	course := s.findCourse(courseID)
+	if course == nil || (course.OwnerID != "" && course.OwnerID != teacherID) {
+		return levelpack.Pack{}, ErrCourseNotFound
+	}
+
+	pack := levelpack.Pack{
+		SchemaVersion: levelpack.SchemaVersion,
+		Course: levelpack.Course{
+			ID:          course.ID,
+			Name:        course.Name,
+			Description: course.Description,
+			Chapters:    make([]levelpack.Chapter, 0, len(course.Chapters)),
+		},
+	}
+	for _, chapter := range course.Chapters {
+		// Chapters of the built-in courses only exist on the teacher side and
+		// carry no translations.
+		definition, _ := s.students.Chapter(ctx, chapter.ID)
+		packChapter := levelpack.Chapter{
+			ID:           chapter.ID,
+			Title:        chapter.Title,
+			Summary:      chapter.Summary,
+			Levels:       make([]levelpack.Level, 0, len(chapter.Levels)),
+			Translations: levelpack.ChapterTranslations(definition.Translations),
+		}
+		for _, level := range chapter.Levels {
+			def, err := s.students.PublishedLevel(ctx, level.ID)
+			if err != nil {
+				return levelpack.Pack{}, err
+			}
+			packChapter.Levels = append(packChapter.Levels, levelpack.FromDefinition(def))
+		}
+		pack.Course.Chapters = append(pack.Course.Chapters, packChapter)
+	}
+	for _, entry := range s.students.CompendiumEntries(ctx, course.chapterIDs()) {
+		pack.Compendium = append(pack.Compendium, levelpack.CompendiumEntry{
+			ID:          entry.ID,
+			ChapterID:   entry.ChapterID,
+			Name:        entry.Name,
+			Description: entry.Description,
+			ImageURL:    entry.ImageURL,
+		})
+	}
+	return pack, nil
+}
+
+// ImportPack validates every level of the pack with the simulator and diffs it
+// against existing content. A pack whose course the teacher already owns
+// updates that course; otherwise a new course is created, with IDs that are
+// already taken replaced by fresh ones. With dryRun nothing is stored.
+func (s *Service) ImportPack(ctx context.Context, teacherID string, pack levelpack.Pack, dryRun bool) (ImportReport, error) {
+	if err := pack.Validate(); err != nil {
+		return ImportReport{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan := s.planImport(ctx, teacherID, pack)
+	plan.report.DryRun = dryRun
+	if !plan.report.Valid {
+		if dryRun {
+			return plan.report, nil
+		}
+		return plan.report, ErrInvalidPack
+	}
+	if dryRun {
+		return plan.report, nil
+	}
+	if err := s.applyImport(ctx, teacherID, pack, plan); err != nil {
+		return ImportReport{}, err
+	}
+	return plan.report, nil
+}
+
+type importPlan struct {
+	report   ImportReport
+	course   *TeacherCourse
+	chapters map[string]string
+	levels   map[string]string
+	actions  map[string]ImportAction
+}
+
+// planImport resolves target IDs and actions for every item in the pack.
+// Callers must hold the lock.
+func (s *Service) planImport(ctx context.Context, teacherID string, pack levelpack.Pack) importPlan {
+	plan := importPlan{
+		report:   ImportReport{Valid: true, Changes: []ImportChange{}},
+		chapters: make(map[string]string),
+		levels:   make(map[string]string),
+		actions:  make(map[string]ImportAction),
+	}
+
+	courseChange := ImportChange{Kind: "course", SourceID: pack.Course.ID, Name: pack.Course.Name}
+	if existing := s.findCourse(pack.Course.ID); existing != nil && existing.OwnerID != "" && existing.OwnerID == teacherID {
+		plan.course = existing
+		courseChange.ID = existing.ID
+		courseChange.Action = ImportActionUnchanged
+		if existing.Name != pack.Course.Name {
+			courseChange.Fields = append(courseChange.Fields, "name")
+		}
+		if existing.Description != pack.Course.Description {
+			courseChange.Fields = append(courseChange.Fields, "description")
+		}
+		if len(courseChange.Fields) > 0 {
+			courseChange.Action = ImportActionUpdate
+		}
+	} else {
+		courseChange.ID = pack.Course.ID
+		if courseChange.ID == "" || existing != nil {
+			courseChange.ID = "course-" + uuid.NewString()
+		}
+		courseChange.Action = ImportActionCreate
+	}
+	plan.report.CourseID = courseChange.ID
+	plan.report.Changes = append(plan.report.Changes, courseChange)
+
+	inCourse := func(chapterID string) *TeacherCourseChapter {
+		if plan.course == nil {
+			return nil
+		}
+		for i := range plan.course.Chapters {
+			if plan.course.Chapters[i].ID == chapterID {
+				return &plan.course.Chapters[i]
+			}
+		}
+		return nil
+	}
+
+	seenChapters := make(map[string]struct{})
+	for ci, chapter := range pack.Course.Chapters {
+		key := chapterKey(ci, chapter)
+		change := ImportChange{Kind: "chapter", SourceID: chapter.ID, Name: chapter.Title}
+		existing := inCourse(chapter.ID)
+		if existing != nil {
+			change.ID = existing.ID
+			change.Action = ImportActionUnchanged
+			if existing.Title != chapter.Title {
+				change.Fields = append(change.Fields, "title")
+			}
+			if existing.Summary != chapter.Summary {
+				change.Fields = append(change.Fields, "summary")
+			}
+			if current, err := s.students.Chapter(ctx, existing.ID); err == nil && !reflect.DeepEqual(levelpack.ChapterTranslations(current.Translations), chapter.Translations) {
+				change.Fields = append(change.Fields, "translations")
+			}
+			if len(change.Fields) > 0 {
+				change.Action = ImportActionUpdate
+			}
+		} else {
+			change.ID = chapter.ID
+			if _, err := s.students.Chapter(ctx, chapter.ID); chapter.ID == "" || err == nil {
+				change.ID = "chapter-" + uuid.NewString()
+			}
+			change.Action = ImportActionCreate
+		}
+		seenChapters[change.ID] = struct{}{}
+		plan.chapters[key] = change.ID
+		plan.actions["chapter:"+change.ID] = change.Action
+		plan.report.Changes = append(plan.report.Changes, change)
+
+		seenLevels := make(map[string]struct{})
+		for _, level := range chapter.Levels {
+			levelChange := ImportChange{Kind: "level", SourceID: level.ID, Name: level.Name}
+			if _, err := s.students.ValidateLevel(ctx, level.Definition()); err != nil {
+				var invalid *student.LevelValidationError
+				if errors.As(err, &invalid) {
+					levelChange.Problems = invalid.Problems
+				} else {
+					levelChange.Problems = []apperr.Problem{apperr.NewProblem("", apperr.From(err).Code)}
+				}
+				plan.report.Valid = false
+			}
+
+			current, err := s.students.PublishedLevel(ctx, level.ID)
+			switch {
+			case err == nil && existing != nil && current.ChapterID == existing.ID:
+				levelChange.ID = level.ID
+				levelChange.Fields = levelpack.Diff(levelpack.FromDefinition(current), level)
+				levelChange.Action = ImportActionUnchanged
+				if len(levelChange.Fields) > 0 {
+					levelChange.Action = ImportActionUpdate
+				}
+			case err == nil:
+				levelChange.ID = "level-" + uuid.NewString()
+				levelChange.Action = ImportActionCreate
+			default:
+				levelChange.ID = level.ID
+				levelChange.Action = ImportActionCreate
+			}
+			seenLevels[levelChange.ID] = struct{}{}
+			plan.levels[level.ID] = levelChange.ID
+			plan.actions["level:"+levelChange.ID] = levelChange.Action
+			plan.report.Changes = append(plan.report.Changes, levelChange)
+		}
+
+		if existing != nil {
+			for _, level := range existing.Levels {
+				if _, ok := seenLevels[level.ID]; !ok {
+					plan.report.Changes = append(plan.report.Changes, ImportChange{Kind: "level", ID: level.ID, Name: level.Name, Action: ImportActionRetained})
+				}
+			}
+		}
+	}
+	if plan.course != nil {
+		for _, chapter := range plan.course.Chapters {
+			if _, ok := seenChapters[chapter.ID]; !ok {
+				plan.report.Changes = append(plan.report.Changes, ImportChange{Kind: "chapter", ID: chapter.ID, Name: chapter.Title, Action: ImportActionRetained})
+			}
+		}
+	}
+
+	for _, entry := range pack.Compendium {
+		change := ImportChange{Kind: "compendium", ID: entry.ID, Name: entry.Name, Action: ImportActionCreate}
+		if current, ok := s.students.CompendiumEntry(ctx, entry.ID); ok {
+			change.Action = ImportActionUnchanged
+			if current.Name != entry.Name || current.Description != entry.Description || current.ImageURL != entry.ImageURL {
+				change.Action = ImportActionUpdate
+			}
+		}
+		plan.report.Changes = append(plan.report.Changes, change)
+	}
+	return plan
+}
+
+// applyImport stores the planned changes. Callers must hold the write lock.
+func (s *Service) applyImport(ctx context.Context, teacherID string, pack levelpack.Pack, plan importPlan) error {
+	course := plan.course
+	if course == nil {
+		s.courses = append(s.courses, TeacherCourse{
+			ID:       plan.report.CourseID,
+			OwnerID:  teacherID,
+			Chapters: []TeacherCourseChapter{},
+		})
+		course = &s.courses[len(s.courses)-1]
+	}
+	course.Name = pack.Course.Name
+	course.Description = pack.Course.Description
+
+	chapterOrder := make([]string, 0, len(course.Chapters)+len(pack.Course.Chapters))
+	for ci, packChapter := range pack.Course.Chapters {
+		chapterID := plan.chapters[chapterKey(ci, packChapter)]
+		chapterOrder = append(chapterOrder, chapterID)
+
+		if plan.actions["chapter:"+chapterID] == ImportActionCreate {
+			if _, err := s.students.CreateChapter(ctx, teacherID, student.ChapterDefinition{ID: chapterID, Title: packChapter.Title, Summary: packChapter.Summary, Translations: packChapter.ServiceTranslations()}); err != nil {
+				return err
+			}
+			course.Chapters = append(course.Chapters, TeacherCourseChapter{ID: chapterID, Levels: []TeacherCourseLevel{}})
+		} else if _, err := s.students.UpdateChapter(ctx, chapterID, packChapter.Title, packChapter.Summary, packChapter.ServiceTranslations()); err != nil {
+			return err
+		}
+
+		var chapter *TeacherCourseChapter
+		for i := range course.Chapters {
+			if course.Chapters[i].ID == chapterID {
+				chapter = &course.Chapters[i]
+			}
+		}
+		chapter.Title = packChapter.Title
+		chapter.Summary = packChapter.Summary
+
+		levelOrder := make([]string, 0, len(chapter.Levels)+len(packChapter.Levels))
+		for _, packLevel := range packChapter.Levels {
+			def := packLevel.Definition()
+			def.ID = plan.levels[packLevel.ID]
+			levelOrder = append(levelOrder, def.ID)
+
+			switch plan.actions["level:"+def.ID] {
+			case ImportActionCreate:
+				created, err := s.students.CreateLevel(ctx, chapterID, def)
+				if err != nil {
+					return err
+				}
+				chapter.Levels = append(chapter.Levels, courseLevel(created))
+			case ImportActionUpdate:
+				updated, err := s.students.UpdateLevel(ctx, def.ID, def)
+				if err != nil {
+					return err
+				}
+				for i := range chapter.Levels {
+					if chapter.Levels[i].ID == def.ID {
+						chapter.Levels[i] = courseLevel(updated)
+					}
+				}
+			}
+		}
+
+		for _, level := range chapter.Levels {
+			if !contains(levelOrder, level.ID) {
+				levelOrder = append(levelOrder, level.ID)
+			}
+		}
+		if err := s.students.ReorderLevels(ctx, chapterID, levelOrder); err != nil {
+			return err
+		}
+		byID := make(map[string]TeacherCourseLevel, len(chapter.Levels))
+		for _, level := range chapter.Levels {
+			byID[level.ID] = level
+		}
+		chapter.Levels = chapter.Levels[:0]
+		for _, id := range levelOrder {
+			chapter.Levels = append(chapter.Levels, byID[id])
+		}
+	}
+
+	for _, chapter := range course.Chapters {
+		if !contains(chapterOrder, chapter.ID) {
+			chapterOrder = append(chapterOrder, chapter.ID)
+		}
+	}
+	if err := s.students.ReorderChapters(ctx, chapterOrder); err != nil {
+		return err
+	}
+	byID := make(map[string]TeacherCourseChapter, len(course.Chapters))
+	for _, chapter := range course.Chapters {
+		byID[chapter.ID] = chapter
+	}
+	course.Chapters = course.Chapters[:0]
+	for i, id := range chapterOrder {
+		chapter := byID[id]
+		chapter.Order = i + 1
+		course.Chapters = append(course.Chapters, chapter)
+	}
+
+	for _, entry := range pack.Compendium {
+		s.students.SaveCompendiumEntry(ctx, student.CompendiumEntry{
+			ID:          entry.ID,
+			ChapterID:   plan.chapters[entryChapterKey(pack, entry.ChapterID)],
+			Name:        entry.Name,
+			Description: entry.Description,
+			ImageURL:    entry.ImageURL,
+		})
+	}
+
+	s.syncCourse(ctx, course)
+	return nil
+}
+
+// findCourse returns the course with the ID. Callers must hold the lock.
+func (s *Service) findCourse(courseID string) *TeacherCourse {
+	for i := range s.courses {
+		if s.courses[i].ID == courseID {
+			return &s.courses[i]
+		}
+	}
+	return nil
+}
+
+// chapterKey identifies a pack chapter even when it has no ID.
+func chapterKey(index int, chapter levelpack.Chapter) string {
+	if chapter.ID != "" {
+		return chapter.ID
+	}
+	return "#" + strconv.Itoa(index)
+}
+
+func entryChapterKey(pack levelpack.Pack, chapterID string) string {
+	for ci, chapter := range pack.Course.Chapters {
+		if chapter.ID == chapterID {
+			return chapterKey(ci, chapter)
+		}
+	}
+	return chapterID
+}
+
+func contains(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
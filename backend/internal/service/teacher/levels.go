@@ -0,0 +1,132 @@
+package teacher
+
+import (
+	"context"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// TeacherAffectedStudent is a roster student whose progress on a level was
+// earned on an older revision than the published one.
+type TeacherAffectedStudent struct {
+	student.AffectedStudent
+	Name string `json:"name"`
+}
+
+// LevelRevisions lists every revision of an owned level.
+func (s *Service) LevelRevisions(ctx context.Context, teacherID, levelID string) ([]student.LevelRevision, error) {
+	if err := s.checkOwnedLevel(teacherID, levelID); err != nil {
+		return nil, err
+	}
+	return s.students.LevelRevisions(ctx, levelID)
+}
+
+// CreateLevelDraft stores a new draft revision of an owned level.
+func (s *Service) CreateLevelDraft(ctx context.Context, teacherID, levelID string, definition student.LevelDefinition) (student.LevelRevision, error) {
+	if err := s.checkOwnedLevel(teacherID, levelID); err != nil {
+		return student.LevelRevision{}, err
+	}
+	return s.students.CreateLevelDraft(ctx, levelID, definition)
+}
+
+// PublishLevelRevision publishes a draft revision of an owned level so
+// students play it.
+func (s *Service) PublishLevelRevision(ctx context.Context, teacherID, levelID string, revision int) (student.LevelRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, _, _, err := s.ownedLevel(teacherID, levelID); err != nil {
+		return student.LevelRevision{}, err
+	}
+	published, err := s.students.PublishLevelRevision(ctx, levelID, revision)
+	if err != nil {
+		return student.LevelRevision{}, err
+	}
+	for i := range s.courses {
+		for chapterIdx, chapter := range s.courses[i].Chapters {
+			for levelIdx, level := range chapter.Levels {
+				if level.ID == levelID {
+					s.courses[i].Chapters[chapterIdx].Levels[levelIdx] = courseLevel(published.Level)
+					s.syncCourse(ctx, &s.courses[i])
+				}
+			}
+		}
+	}
+	return published, nil
+}
+
+// AffectedStudents lists the teacher's students whose progress on an owned
+// level predates the published revision.
+func (s *Service) AffectedStudents(ctx context.Context, teacherID, levelID string) ([]TeacherAffectedStudent, error) {
+	if err := s.checkOwnedLevel(teacherID, levelID); err != nil {
+		return nil, err
+	}
+	affected, err := s.students.AffectedStudents(ctx, levelID)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := s.roster(teacherID)
+	result := make([]TeacherAffectedStudent, 0, len(affected))
+	for _, item := range affected {
+		name, ok := roster[item.StudentID]
+		if !ok {
+			continue
+		}
+		result = append(result, TeacherAffectedStudent{AffectedStudent: item, Name: name})
+	}
+	return result, nil
+}
+
+// MigrateLevelProgress moves the teacher's affected students onto the
+// published revision of an owned level. An empty list migrates all of them.
+func (s *Service) MigrateLevelProgress(ctx context.Context, teacherID, levelID string, studentIDs []string) ([]student.MigrationResult, error) {
+	if err := s.checkOwnedLevel(teacherID, levelID); err != nil {
+		return nil, err
+	}
+	roster := s.roster(teacherID)
+	selected := make([]string, 0, len(roster))
+	if len(studentIDs) == 0 {
+		for id := range roster {
+			selected = append(selected, id)
+		}
+	} else {
+		for _, id := range studentIDs {
+			if _, ok := roster[id]; !ok {
+				return nil, ErrStudentNotFound
+			}
+			selected = append(selected, id)
+		}
+	}
+	if len(selected) == 0 {
+		return []student.MigrationResult{}, nil
+	}
+	return s.students.MigrateLevelProgress(ctx, levelID, selected)
+}
+
+// checkOwnedLevel reports whether the level belongs to one of the teacher's
+// courses.
+func (s *Service) checkOwnedLevel(teacherID, levelID string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, _, _, err := s.ownedLevel(teacherID, levelID)
+	return err
+}
+
+// roster maps the IDs of all students in the teacher's classes to their names.
+func (s *Service) roster(teacherID string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	roster := make(map[string]string)
+	for _, detail := range s.classes {
+		if detail.Class.OwnerID == "" || detail.Class.OwnerID != teacherID {
+			continue
+		}
+		for _, member := range detail.Students {
+			roster[member.ID] = member.Name
+		}
+	}
+	return roster
+}
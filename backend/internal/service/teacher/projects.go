@@ -0,0 +1,28 @@
+package teacher
+
+import (
+	"context"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// ClassProjects lists the sandbox projects of a class of the teacher in a
+// moderation status, pending ones when status is empty.
+func (s *Service) ClassProjects(ctx context.Context, teacherID, classID string, status student.ProjectStatus) ([]student.SandboxProject, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = student.ProjectPending
+	}
+	return s.students.ClassProjects(ctx, classID, status), nil
+}
+
+// ModerateProject approves a project for the gallery of a class of the
+// teacher or rejects it with a note for the student.
+func (s *Service) ModerateProject(ctx context.Context, teacherID, classID, projectID string, approve bool, note string) (student.SandboxProject, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return student.SandboxProject{}, err
+	}
+	return s.students.ModerateProject(ctx, classID, projectID, teacherID, approve, note)
+}
@@ -0,0 +1,42 @@
+package teacher
+
+import (
+	"context"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// Rubrics lists the rubrics of a class of the teacher.
+func (s *Service) Rubrics(ctx context.Context, teacherID, classID string) ([]student.Rubric, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return nil, err
+	}
+	return s.students.Rubrics(ctx, classID), nil
+}
+
+// SaveRubric creates or replaces a rubric of a class of the teacher.
+func (s *Service) SaveRubric(ctx context.Context, teacherID string, rubric student.Rubric) (student.Rubric, error) {
+	if err := s.ensureOwnedClass(teacherID, rubric.ClassID); err != nil {
+		return student.Rubric{}, err
+	}
+	rubric.OwnerID = teacherID
+	return s.students.SaveRubric(ctx, rubric)
+}
+
+// DeleteRubric removes a rubric of a class of the teacher.
+func (s *Service) DeleteRubric(ctx context.Context, teacherID, classID, rubricID string) error {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return err
+	}
+	return s.students.DeleteRubric(ctx, classID, rubricID)
+}
+
+// Gradebook returns the rubric scores of every student in a class of the
+// teacher.
+func (s *Service) Gradebook(ctx context.Context, teacherID, classID string) (student.Gradebook, error) {
+	roster, err := s.ownedRoster(teacherID, classID)
+	if err != nil {
+		return student.Gradebook{}, err
+	}
+	return s.students.Gradebook(ctx, classID, roster), nil
+}
@@ -2,115 +2,152 @@ package teacher
 
 import (
 	"context"
-	"errors"
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/codeadventurers/api-go/internal/apperr"
+	"github.com/codeadventurers/api-go/internal/service/student"
 )
 
 // Service exposes analytics endpoints for teachers and provides
 // in-memory demo datasets for teaching operations.
 type Service struct {
-	mu           sync.RWMutex
-	courses      []TeacherCourse
-	classes      map[string]*TeacherClassDetail
-	pendingWorks map[string]TeacherWork
+	mu       sync.RWMutex
+	students *student.Service
+	courses  []TeacherCourse
+	classes  map[string]*TeacherClassDetail
+	// invites maps normalized invite codes to class IDs.
+	invites map[string]string
+}
+
+// demoInviteAliases are older demo invite codes that still admit students to
+// the seeded classes. They share the limits of the class's invite and stop
+// working once the invite is regenerated.
+var demoInviteAliases = map[string]string{
+	"ABC123":    "class-1",
+	"DEF456":    "class-2",
+	"A-CLASS-2": "class-2",
 }
 
 // New constructs the teacher service seeded with representative demo data.
-func New() *Service {
+// Class rosters and policies are registered with the student service so that
+// limits configured by teachers apply to the students' own requests.
+func New(students *student.Service) *Service {
 	courses := demoCourses()
 	now := time.Now()
 
 	class1 := &TeacherClassDetail{
 		Class: TeacherClassInfo{
-			ID:              "class-1",
-			Name:            "星际编程一班",
-			InviteCode:      "CA-CLASS-1",
-			HintLimit:       5,
-			StudentCount:    24,
-			LevelCount:      18,
-			AverageProgress: 58,
-			CompletionRate:  45,
+			ID:                "class-1",
+			OwnerID:           "teacher-1",
+			Name:              "星际编程一班",
+			InviteCode:        "CA-CLASS-1",
+			Invite:            ClassInvite{Code: "CA-CLASS-1", CreatedAt: now.UnixMilli()},
+			HintLimit:         5,
+			HintWindowMinutes: defaultHintWindowMinutes,
 		},
 		Students: []TeacherStudent{
-			{ID: "student-1", Name: "小明", CompletedLevels: 7, TotalLevels: 18, Stars: 18, LastActiveAt: now.Add(-48 * time.Hour).UnixMilli()},
-			{ID: "student-2", Name: "小红", CompletedLevels: 5, TotalLevels: 18, Stars: 14, LastActiveAt: now.Add(-5 * 24 * time.Hour).UnixMilli()},
-			{ID: "student-3", Name: "小刚", CompletedLevels: 2, TotalLevels: 18, Stars: 3, LastActiveAt: now.Add(-12 * 24 * time.Hour).UnixMilli()},
+			{ID: "student-1", Name: "小明", LastActiveAt: now.Add(-48 * time.Hour).UnixMilli()},
+			{ID: "student-2", Name: "小红", LastActiveAt: now.Add(-5 * 24 * time.Hour).UnixMilli()},
+			{ID: "student-3", Name: "小刚", LastActiveAt: now.Add(-12 * 24 * time.Hour).UnixMilli()},
 		},
 		Courses: []TeacherCourse{courses[0]},
 		RecentActivities: []TeacherActivity{
 			{StudentID: "student-1", StudentName: "小明", LevelID: "level-1-2", Stars: 3, CompletedAt: now.Add(-36 * time.Hour).UnixMilli()},
 			{StudentID: "student-2", StudentName: "小红", LevelID: "level-1-1", Stars: 2, CompletedAt: now.Add(-72 * time.Hour).UnixMilli()},
 		},
-		PendingWorks: []TeacherWork{
-			{ID: "work-1", Title: "第 2 课编程作业", OwnerID: "student-1", Status: "pending", CreatedAt: now.Add(-30 * time.Hour).UnixMilli(), ClassID: "class-1"},
-			{ID: "work-2", Title: "循环应用练习", OwnerID: "student-2", Status: "pending", CreatedAt: now.Add(-28 * time.Hour).UnixMilli(), ClassID: "class-1"},
-		},
 	}
 
 	class2 := &TeacherClassDetail{
 		Class: TeacherClassInfo{
-			ID:              "class-2",
-			Name:            "火箭编程实验班",
-			InviteCode:      "CA-CLASS-2",
-			HintLimit:       3,
-			StudentCount:    18,
-			LevelCount:      12,
-			AverageProgress: 64,
-			CompletionRate:  52,
+			ID:                "class-2",
+			OwnerID:           "teacher-1",
+			Name:              "火箭编程实验班",
+			InviteCode:        "CA-CLASS-2",
+			Invite:            ClassInvite{Code: "CA-CLASS-2", CreatedAt: now.UnixMilli()},
+			HintLimit:         3,
+			HintWindowMinutes: defaultHintWindowMinutes,
 		},
 		Students: []TeacherStudent{
-			{ID: "student-8", Name: "小宇", CompletedLevels: 9, TotalLevels: 12, Stars: 21, LastActiveAt: now.Add(-12 * time.Hour).UnixMilli()},
-			{ID: "student-9", Name: "小琴", CompletedLevels: 6, TotalLevels: 12, Stars: 16, LastActiveAt: now.Add(-2 * 24 * time.Hour).UnixMilli()},
+			{ID: "student-8", Name: "小宇", LastActiveAt: now.Add(-12 * time.Hour).UnixMilli()},
+			{ID: "student-9", Name: "小琴", LastActiveAt: now.Add(-2 * 24 * time.Hour).UnixMilli()},
 		},
-		Courses:         []TeacherCourse{courses[0], courses[1]},
+		Courses:          []TeacherCourse{courses[0], courses[1]},
 		RecentActivities: []TeacherActivity{{StudentID: "student-8", StudentName: "小宇", LevelID: "level-2-1", Stars: 3, CompletedAt: now.Add(-6 * time.Hour).UnixMilli()}},
-		PendingWorks:    []TeacherWork{{ID: "work-3", Title: "条件判断小测试", OwnerID: "student-9", Status: "pending", CreatedAt: now.Add(-18 * time.Hour).UnixMilli(), ClassID: "class-2"}},
+	}
+
+	// class3 has no teacher; it keeps the demo code CA-CLASS-3 working as it
+	// did before classes were managed here.
+	class3 := &TeacherClassDetail{
+		Class: TeacherClassInfo{
+			ID:                "class-3",
+			Name:              "体验班",
+			InviteCode:        "CA-CLASS-3",
+			Invite:            ClassInvite{Code: "CA-CLASS-3", CreatedAt: now.UnixMilli()},
+			HintWindowMinutes: defaultHintWindowMinutes,
+		},
+		Students: []TeacherStudent{},
+		Courses:  []TeacherCourse{courses[0]},
 	}
 
 	classes := map[string]*TeacherClassDetail{
 		class1.Class.ID: class1,
 		class2.Class.ID: class2,
+		class3.Class.ID: class3,
 	}
 
-	pending := make(map[string]TeacherWork)
+	invites := make(map[string]string, len(classes)+len(demoInviteAliases))
+	for code, classID := range demoInviteAliases {
+		invites[normalizeInviteCode(code)] = classID
+	}
+	ctx := context.Background()
 	for _, detail := range classes {
-		for _, work := range detail.PendingWorks {
-			pending[work.ID] = work
+		invites[normalizeInviteCode(detail.Class.Invite.Code)] = detail.Class.ID
+		students.ConfigureClass(ctx, detail.Class.ID, detail.Class.HintLimit, time.Duration(detail.Class.HintWindowMinutes)*time.Minute)
+		for _, member := range detail.Students {
+			students.EnrollStudent(ctx, member.ID, detail.Class.ID)
 		}
 	}
 
 	return &Service{
-		courses:      courses,
-		classes:      classes,
-		pendingWorks: pending,
+		students: students,
+		courses:  courses,
+		classes:  classes,
+		invites:  invites,
 	}
 }
 
-// Analytics returns aggregated metrics for the requested resource.
-func (s *Service) Analytics(ctx context.Context, resource string, query map[string]string) (map[string]any, error) {
-	return map[string]any{
-		"resource": resource,
-		"data":     []any{},
-	}, nil
-}
-
-// Courses returns all available courses.
-func (s *Service) Courses(ctx context.Context) ([]TeacherCourse, error) {
+// Courses returns the shared courses plus the ones authored by the teacher.
+func (s *Service) Courses(ctx context.Context, teacherID string) ([]TeacherCourse, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return cloneCourses(s.courses), nil
+
+	visible := make([]TeacherCourse, 0, len(s.courses))
+	for _, course := range s.courses {
+		if course.OwnerID == "" || course.OwnerID == teacherID {
+			visible = append(visible, course)
+		}
+	}
+	return cloneCourses(visible), nil
 }
 
-// Classes returns class summaries for the logged in teacher.
-func (s *Service) Classes(ctx context.Context) ([]TeacherClassSummary, error) {
+// Classes returns summaries of the classes the teacher owns. Archived
+// classes are only listed when includeArchived is set.
+func (s *Service) Classes(ctx context.Context, teacherID string, includeArchived bool) ([]TeacherClassSummary, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	result := make([]TeacherClassSummary, 0, len(s.classes))
 	now := time.Now()
-	for _, detail := range s.classes {
+	for _, ref := range s.classes {
+		if ref.Class.OwnerID == "" || ref.Class.OwnerID != teacherID {
+			continue
+		}
+		if ref.Class.Archived && !includeArchived {
+			continue
+		}
+		detail := s.classView(ctx, ref, now)
 		active := 0
 		for _, student := range detail.Students {
 			if student.LastActiveAt == 0 {
@@ -122,28 +159,29 @@ func (s *Service) Classes(ctx context.Context) ([]TeacherClassSummary, error) {
 		}
 
 		courses := make([]TeacherClassSummaryCourse, 0, len(detail.Courses))
-		levelCount := 0
 		for _, course := range detail.Courses {
 			courses = append(courses, TeacherClassSummaryCourse{
 				ID:           course.ID,
 				Name:         course.Name,
 				ChapterCount: len(course.Chapters),
 			})
-			levelCount += course.LevelCount()
 		}
 
 		result = append(result, TeacherClassSummary{
-			ID:              detail.Class.ID,
-			Name:            detail.Class.Name,
-			InviteCode:      detail.Class.InviteCode,
-			StudentCount:    detail.Class.StudentCount,
-			HintLimit:       detail.Class.HintLimit,
-			ActiveStudents:  active,
-			AverageProgress: detail.Class.AverageProgress,
-			CompletionRate:  detail.Class.CompletionRate,
-			CourseCount:     len(detail.Courses),
-			LevelCount:      levelCount,
-			Courses:         courses,
+			ID:                detail.Class.ID,
+			Name:              detail.Class.Name,
+			InviteCode:        detail.Class.InviteCode,
+			Invite:            detail.Class.Invite,
+			Archived:          detail.Class.Archived,
+			StudentCount:      detail.Class.StudentCount,
+			HintLimit:         detail.Class.HintLimit,
+			HintWindowMinutes: detail.Class.HintWindowMinutes,
+			ActiveStudents:    active,
+			AverageProgress:   detail.Class.AverageProgress,
+			CompletionRate:    detail.Class.CompletionRate,
+			CourseCount:       len(detail.Courses),
+			LevelCount:        detail.Class.LevelCount,
+			Courses:           courses,
 		})
 	}
 
@@ -153,118 +191,213 @@ func (s *Service) Classes(ctx context.Context) ([]TeacherClassSummary, error) {
 	return result, nil
 }
 
-// ClassDetail returns detail for the requested class.
-func (s *Service) ClassDetail(ctx context.Context, classID string) (TeacherClassDetail, error) {
+// ClassDetail returns detail for a class of the teacher.
+func (s *Service) ClassDetail(ctx context.Context, teacherID, classID string) (TeacherClassDetail, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	ref, ok := s.classes[classID]
-	if !ok {
-		return TeacherClassDetail{}, ErrClassNotFound
+	ref, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return TeacherClassDetail{}, err
 	}
-	return cloneClassDetail(ref), nil
+	detail := s.classView(ctx, ref, time.Now())
+	detail.PendingWorks = s.students.ClassWorks(ctx, classID, student.WorkPending)
+	for i := range detail.Students {
+		usage, err := s.students.HintUsage(ctx, detail.Students[i].ID)
+		if err != nil {
+			return TeacherClassDetail{}, err
+		}
+		detail.Students[i].HintUsage = usage
+		for _, entry := range usage {
+			detail.Students[i].HintsUsed += entry.Used
+		}
+		if activity, ok := s.students.ActivitySummary(ctx, detail.Students[i].ID); ok {
+			detail.Students[i].Activity = &activity
+			if activity.LastActiveAt > detail.Students[i].LastActiveAt {
+				detail.Students[i].LastActiveAt = activity.LastActiveAt
+			}
+		}
+	}
+	return detail, nil
 }
 
-// AssignCourseToClass attaches a course to a class if not already present.
-func (s *Service) AssignCourseToClass(ctx context.Context, classID, courseID string) error {
+// AssignCourseToClass attaches a course to a class of the teacher if not
+// already present.
+func (s *Service) AssignCourseToClass(ctx context.Context, teacherID, classID, courseID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	classDetail, ok := s.classes[classID]
-	if !ok {
-		return ErrClassNotFound
+	classDetail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return err
 	}
 
-	var course *TeacherCourse
-	for i := range s.courses {
-		if s.courses[i].ID == courseID {
-			course = &s.courses[i]
-			break
-		}
-	}
+	course := s.findCourse(courseID)
 	if course == nil {
 		return ErrCourseNotFound
 	}
+	s.attachCourse(ctx, classDetail, course)
+	return nil
+}
 
-	for _, existing := range classDetail.Courses {
-		if existing.ID == courseID {
-			return nil
-		}
+// UpdateClassHintLimit updates hint limits for a class of the teacher. A hint
+// window of zero keeps the current window.
+func (s *Service) UpdateClassHintLimit(ctx context.Context, teacherID, classID string, hintLimit, hintWindowMinutes int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	classDetail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return err
 	}
 
-	classDetail.Courses = append(classDetail.Courses, course.Clone())
-	classDetail.Class.LevelCount = classDetail.totalLevels()
+	classDetail.Class.HintLimit = clampHintLimit(hintLimit)
+	if hintWindowMinutes != 0 {
+		classDetail.Class.HintWindowMinutes = clampHintWindow(hintWindowMinutes)
+	}
+	s.configureHints(ctx, classDetail)
 	return nil
 }
 
-// UpdateClassHintLimit updates hint limits for a class.
-func (s *Service) UpdateClassHintLimit(ctx context.Context, classID string, hintLimit int) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// StudentAttempts lists the attempts a student of the teacher's class made on
+// a level.
+func (s *Service) StudentAttempts(ctx context.Context, teacherID, classID, studentID, levelID string) ([]student.AttemptSummary, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return nil, err
+	}
+	return s.students.Attempts(ctx, studentID, levelID)
+}
 
-	classDetail, ok := s.classes[classID]
-	if !ok {
-		return ErrClassNotFound
+// StudentAttemptHistory lists the attempts a student of the teacher's class
+// made on all levels, newest first.
+func (s *Service) StudentAttemptHistory(ctx context.Context, teacherID, classID, studentID string) ([]student.AttemptSummary, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return nil, err
 	}
+	return s.students.AttemptHistory(ctx, studentID), nil
+}
 
-	if hintLimit < 1 {
-		hintLimit = 1
+// StudentProgressRows lists the level progress of a student of the teacher's
+// class.
+func (s *Service) StudentProgressRows(ctx context.Context, teacherID, classID, studentID string) ([]student.LevelProgressRow, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return nil, err
 	}
-	if hintLimit > 20 {
-		hintLimit = 20
+	return s.students.ProgressRows(ctx, studentID), nil
+}
+
+// CheckClassMember reports whether the teacher owns the class and, when
+// studentID is set, whether the student belongs to it.
+func (s *Service) CheckClassMember(ctx context.Context, teacherID, classID, studentID string) error {
+	if studentID == "" {
+		return s.ensureOwnedClass(teacherID, classID)
 	}
-	classDetail.Class.HintLimit = hintLimit
-	return nil
+	return s.ensureOwnedMember(teacherID, classID, studentID)
+}
+
+// StudentAttempt returns a single attempt of a student of the teacher's class.
+func (s *Service) StudentAttempt(ctx context.Context, teacherID, classID, studentID, attemptID string) (student.Attempt, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return student.Attempt{}, err
+	}
+	return s.students.Attempt(ctx, studentID, attemptID)
+}
+
+// StudentReplay re-simulates an attempt of a student of the teacher's class and returns a page of frames.
+func (s *Service) StudentReplay(ctx context.Context, teacherID, classID, studentID, attemptID string, offset, limit int) (student.Replay, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return student.Replay{}, err
+	}
+	return s.students.Replay(ctx, studentID, attemptID, offset, limit)
+}
+
+// StudentActivity returns the streak and activity calendar of a student of
+// the teacher's class.
+func (s *Service) StudentActivity(ctx context.Context, teacherID, classID, studentID string, days int) (student.ActivityCalendar, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return student.ActivityCalendar{}, err
+	}
+	return s.students.Activity(ctx, studentID, days), nil
+}
+
+// GrantAvatarItem gives an avatar item to a student of the teacher's class.
+func (s *Service) GrantAvatarItem(ctx context.Context, teacherID, classID, studentID, itemID, reason string) (student.LedgerEntry, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return student.LedgerEntry{}, err
+	}
+	return s.students.GrantAvatarItem(ctx, studentID, itemID, teacherID, reason)
 }
 
-// PendingWorks returns all pending works for the teacher.
-func (s *Service) PendingWorks(ctx context.Context) ([]TeacherWork, error) {
+// PendingWorks returns the submissions awaiting review in the classes of the
+// teacher, most recently submitted first.
+func (s *Service) PendingWorks(ctx context.Context, teacherID string) ([]student.WorkSubmission, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	works := make([]TeacherWork, 0, len(s.pendingWorks))
-	for _, work := range s.pendingWorks {
-		works = append(works, work)
+	works := make([]student.WorkSubmission, 0)
+	for classID, detail := range s.classes {
+		if detail.Class.OwnerID == "" || detail.Class.OwnerID != teacherID {
+			continue
+		}
+		works = append(works, s.students.ClassWorks(ctx, classID, student.WorkPending)...)
 	}
 	sort.Slice(works, func(i, j int) bool {
-		return works[i].CreatedAt > works[j].CreatedAt
+		return works[i].SubmittedAt > works[j].SubmittedAt
 	})
 	return works, nil
 }
 
-// ReviewWork marks a pending work as reviewed.
-func (s *Service) ReviewWork(ctx context.Context, workID, status string) error {
-	if status != "approved" && status != "rejected" {
-		return ErrUnsupportedStatus
+// ClassWorks lists the submissions of a class of the teacher, optionally in
+// one status.
+func (s *Service) ClassWorks(ctx context.Context, teacherID, classID string, status student.WorkStatus) ([]student.WorkSubmission, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return nil, err
 	}
+	return s.students.ClassWorks(ctx, classID, status), nil
+}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	work, ok := s.pendingWorks[workID]
-	if !ok {
-		return ErrWorkNotFound
+// ClassWork returns a submission of a class of the teacher including its
+// program.
+func (s *Service) ClassWork(ctx context.Context, teacherID, classID, workID string) (student.WorkSubmission, error) {
+	if err := s.ensureOwnedClass(teacherID, classID); err != nil {
+		return student.WorkSubmission{}, err
+	}
+	work, err := s.students.FindWork(ctx, workID)
+	if err != nil || work.ClassID != classID {
+		return student.WorkSubmission{}, ErrWorkNotFound.With("workId", workID)
 	}
+	return work, nil
+}
 
-	delete(s.pendingWorks, workID)
-	if classDetail, ok := s.classes[work.ClassID]; ok {
-		filtered := classDetail.PendingWorks[:0]
-		for _, item := range classDetail.PendingWorks {
-			if item.ID != workID {
-				filtered = append(filtered, item)
-			}
-		}
-		classDetail.PendingWorks = filtered
+// ReviewWork approves or rejects a pending submission with written feedback
+// and an optional star override between 0 and 3. Only the teacher of the
+// class the work was handed in to may review it. The student is notified.
+func (s *Service) ReviewWork(ctx context.Context, teacherID, workID string, review student.WorkReview) (student.WorkSubmission, error) {
+	if review.Status != student.WorkApproved && review.Status != student.WorkRejected {
+		return student.WorkSubmission{}, ErrUnsupportedStatus
+	}
+	if review.Stars != nil && (*review.Stars < 0 || *review.Stars > 3) {
+		return student.WorkSubmission{}, ErrInvalidStars
 	}
 
-	return nil
+	work, err := s.students.FindWork(ctx, workID)
+	if err != nil {
+		return student.WorkSubmission{}, err
+	}
+	if err := s.ensureOwnedClass(teacherID, work.ClassID); err != nil {
+		return student.WorkSubmission{}, ErrWorkNotFound.With("workId", workID)
+	}
+	return s.students.ReviewWork(ctx, workID, teacherID, review)
 }
 
 // Errors returned by the service.
 var (
-	ErrClassNotFound      = errors.New("class not found")
-	ErrCourseNotFound     = errors.New("course not found")
-	ErrWorkNotFound       = errors.New("work not found")
-	ErrUnsupportedStatus  = errors.New("unsupported review status")
+	ErrClassNotFound      = apperr.New(apperr.KindNotFound, "class.not_found")
+	ErrStudentNotFound    = apperr.New(apperr.KindNotFound, "student.not_found")
+	ErrCourseNotFound     = apperr.New(apperr.KindNotFound, "course.not_found")
+	ErrWorkNotFound       = student.ErrWorkNotFound
+	ErrUnsupportedStatus  = apperr.New(apperr.KindInvalid, "review.unsupported_status")
+	ErrInvalidStars       = apperr.New(apperr.KindInvalid, "review.invalid_stars")
+	ErrNotOwner           = apperr.New(apperr.KindForbidden, "content.not_owner")
 )
 
 // TeacherCourse describes a course available to a teacher.
@@ -272,7 +405,11 @@ type TeacherCourse struct {
 	ID          string                 `json:"id"`
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
+	OwnerID     string                 `json:"ownerId,omitempty"`
 	Chapters    []TeacherCourseChapter `json:"chapters"`
+	// UnlockPolicy applies to the levels of the course in classes without
+	// a policy of their own.
+	UnlockPolicy *UnlockPolicy `json:"unlockPolicy,omitempty"`
 }
 
 // Clone returns a copy of the course.
@@ -281,11 +418,16 @@ func (c TeacherCourse) Clone() TeacherCourse {
 		ID:          c.ID,
 		Name:        c.Name,
 		Description: c.Description,
+		OwnerID:     c.OwnerID,
 		Chapters:    make([]TeacherCourseChapter, len(c.Chapters)),
 	}
 	for i, chapter := range c.Chapters {
 		clone.Chapters[i] = chapter.Clone()
 	}
+	if c.UnlockPolicy != nil {
+		policy := c.UnlockPolicy.Clone()
+		clone.UnlockPolicy = &policy
+	}
 	return clone
 }
 
@@ -302,13 +444,14 @@ func (c TeacherCourse) LevelCount() int {
 type TeacherCourseChapter struct {
 	ID      string                `json:"id"`
 	Title   string                `json:"title"`
+	Summary string                `json:"summary,omitempty"`
 	Order   int                   `json:"order"`
 	Levels  []TeacherCourseLevel  `json:"levels"`
 }
 
 // Clone returns a copy of the chapter.
 func (c TeacherCourseChapter) Clone() TeacherCourseChapter {
-	clone := TeacherCourseChapter{ID: c.ID, Title: c.Title, Order: c.Order, Levels: make([]TeacherCourseLevel, len(c.Levels))}
+	clone := TeacherCourseChapter{ID: c.ID, Title: c.Title, Summary: c.Summary, Order: c.Order, Levels: make([]TeacherCourseLevel, len(c.Levels))}
 	copy(clone.Levels, c.Levels)
 	return clone
 }
@@ -327,26 +470,38 @@ type TeacherCourseLevelReward struct {
 	Outfit *string `json:"outfit"`
 }
 
-// TeacherClassInfo summarises a class.
+// TeacherClassInfo summarises a class. InviteCode repeats Invite.Code for
+// older clients. The counts and rates are derived from the roster and the
+// students' progress when the class is read.
 type TeacherClassInfo struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	InviteCode      string `json:"inviteCode"`
-	HintLimit       int    `json:"hintLimit"`
-	StudentCount    int    `json:"studentCount"`
-	LevelCount      int    `json:"levelCount"`
-	AverageProgress int    `json:"averageProgress"`
-	CompletionRate  int    `json:"completionRate"`
+	ID                string        `json:"id"`
+	Name              string        `json:"name"`
+	OwnerID           string        `json:"ownerId,omitempty"`
+	InviteCode        string        `json:"inviteCode"`
+	Invite            ClassInvite   `json:"invite"`
+	HintLimit         int           `json:"hintLimit"`
+	HintWindowMinutes int           `json:"hintWindowMinutes"`
+	StudentCount      int           `json:"studentCount"`
+	LevelCount        int           `json:"levelCount"`
+	AverageProgress   int           `json:"averageProgress"`
+	CompletionRate    int           `json:"completionRate"`
+	Archived          bool          `json:"archived"`
+	ArchivedAt        int64         `json:"archivedAt,omitempty"`
+	UnlockPolicy      *UnlockPolicy `json:"unlockPolicy,omitempty"`
 }
 
-// TeacherStudent stores student stats for a class.
+// TeacherStudent stores student stats for a class. CompletedLevels, Stars
+// and TotalLevels cover the levels of the class courses.
 type TeacherStudent struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	CompletedLevels int    `json:"completedLevels"`
-	TotalLevels     int    `json:"totalLevels"`
-	Stars           int    `json:"stars"`
-	LastActiveAt    int64  `json:"lastActiveAt"`
+	ID              string                   `json:"id"`
+	Name            string                   `json:"name"`
+	CompletedLevels int                      `json:"completedLevels"`
+	TotalLevels     int                      `json:"totalLevels"`
+	Stars           int                      `json:"stars"`
+	LastActiveAt    int64                    `json:"lastActiveAt"`
+	HintsUsed       int                      `json:"hintsUsed"`
+	HintUsage       []student.HintUsage      `json:"hintUsage"`
+	Activity        *student.ActivitySummary `json:"activity,omitempty"`
 }
 
 // TeacherActivity captures recent student activity.
@@ -358,23 +513,13 @@ type TeacherActivity struct {
 	CompletedAt int64  `json:"completedAt"`
 }
 
-// TeacherWork is a pending work item awaiting review.
-type TeacherWork struct {
-	ID        string `json:"id"`
-	Title     string `json:"title"`
-	OwnerID   string `json:"ownerId"`
-	Status    string `json:"status"`
-	CreatedAt int64  `json:"createdAt"`
-	ClassID   string `json:"classId"`
-}
-
 // TeacherClassDetail contains class level data for a teacher.
 type TeacherClassDetail struct {
-	Class            TeacherClassInfo `json:"class"`
-	Students         []TeacherStudent `json:"students"`
-	Courses          []TeacherCourse  `json:"courses"`
-	RecentActivities []TeacherActivity `json:"recentActivities"`
-	PendingWorks     []TeacherWork    `json:"pendingWorks"`
+	Class            TeacherClassInfo         `json:"class"`
+	Students         []TeacherStudent         `json:"students"`
+	Courses          []TeacherCourse          `json:"courses"`
+	RecentActivities []TeacherActivity        `json:"recentActivities"`
+	PendingWorks     []student.WorkSubmission `json:"pendingWorks"`
 }
 
 func (c *TeacherClassDetail) totalLevels() int {
@@ -387,17 +532,20 @@ func (c *TeacherClassDetail) totalLevels() int {
 
 // TeacherClassSummary summarises a class for list view.
 type TeacherClassSummary struct {
-	ID              string                      `json:"id"`
-	Name            string                      `json:"name"`
-	InviteCode      string                      `json:"inviteCode"`
-	StudentCount    int                         `json:"studentCount"`
-	HintLimit       int                         `json:"hintLimit"`
-	ActiveStudents  int                         `json:"activeStudents"`
-	AverageProgress int                         `json:"averageProgress"`
-	CompletionRate  int                         `json:"completionRate"`
-	CourseCount     int                         `json:"courseCount"`
-	LevelCount      int                         `json:"levelCount"`
-	Courses         []TeacherClassSummaryCourse `json:"courses"`
+	ID                string                      `json:"id"`
+	Name              string                      `json:"name"`
+	InviteCode        string                      `json:"inviteCode"`
+	Invite            ClassInvite                 `json:"invite"`
+	Archived          bool                        `json:"archived"`
+	StudentCount      int                         `json:"studentCount"`
+	HintLimit         int                         `json:"hintLimit"`
+	HintWindowMinutes int                         `json:"hintWindowMinutes"`
+	ActiveStudents    int                         `json:"activeStudents"`
+	AverageProgress   int                         `json:"averageProgress"`
+	CompletionRate    int                         `json:"completionRate"`
+	CourseCount       int                         `json:"courseCount"`
+	LevelCount        int                         `json:"levelCount"`
+	Courses           []TeacherClassSummaryCourse `json:"courses"`
 }
 
 // TeacherClassSummaryCourse summarises a course assigned to a class.
@@ -421,11 +569,9 @@ func cloneClassDetail(ref *TeacherClassDetail) TeacherClassDetail {
 		Students:         make([]TeacherStudent, len(ref.Students)),
 		Courses:          make([]TeacherCourse, len(ref.Courses)),
 		RecentActivities: make([]TeacherActivity, len(ref.RecentActivities)),
-		PendingWorks:     make([]TeacherWork, len(ref.PendingWorks)),
 	}
 	copy(clone.Students, ref.Students)
 	copy(clone.RecentActivities, ref.RecentActivities)
-	copy(clone.PendingWorks, ref.PendingWorks)
 	for i, course := range ref.Courses {
 		clone.Courses[i] = course.Clone()
 	}
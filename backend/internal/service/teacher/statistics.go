@@ -0,0 +1,56 @@
+package teacher
+
+import (
+	"context"
+	"math"
+)
+
+// fillStatistics derives the statistics of a copy of a class from the
+// students' recorded progress on the levels of the courses assigned to it, so
+// they are current after every completion, approved submission or progress
+// migration. Only levels of the class courses count; AverageProgress is the
+// mean share of those levels each student completed and CompletionRate the
+// share of students who completed all of them, both in percent.
+func (s *Service) fillStatistics(ctx context.Context, detail *TeacherClassDetail) {
+	levelIDs := make([]string, 0)
+	for _, course := range detail.Courses {
+		for _, chapter := range course.Chapters {
+			for _, level := range chapter.Levels {
+				levelIDs = append(levelIDs, level.ID)
+			}
+		}
+	}
+	studentIDs := make([]string, 0, len(detail.Students))
+	for _, member := range detail.Students {
+		studentIDs = append(studentIDs, member.ID)
+	}
+	summaries := s.students.ProgressSummaries(ctx, studentIDs, levelIDs)
+
+	completed, finished := 0, 0
+	for i := range detail.Students {
+		summary := summaries[detail.Students[i].ID]
+		detail.Students[i].CompletedLevels = summary.CompletedLevels
+		detail.Students[i].TotalLevels = len(levelIDs)
+		detail.Students[i].Stars = summary.Stars
+		if summary.LastCompletedAt > detail.Students[i].LastActiveAt {
+			detail.Students[i].LastActiveAt = summary.LastCompletedAt
+		}
+		completed += summary.CompletedLevels
+		if len(levelIDs) > 0 && summary.CompletedLevels == len(levelIDs) {
+			finished++
+		}
+	}
+
+	detail.Class.StudentCount = len(detail.Students)
+	detail.Class.LevelCount = len(levelIDs)
+	detail.Class.AverageProgress = 0
+	detail.Class.CompletionRate = 0
+	if len(detail.Students) > 0 && len(levelIDs) > 0 {
+		detail.Class.AverageProgress = percent(completed, len(levelIDs)*len(detail.Students))
+		detail.Class.CompletionRate = percent(finished, len(detail.Students))
+	}
+}
+
+func percent(part, whole int) int {
+	return int(math.Round(float64(part) * 100 / float64(whole)))
+}
@@ -0,0 +1,130 @@
+package teacher
+
+import (
+	"context"
+	"time"
+
+	"github.com/codeadventurers/api-go/internal/service/student"
+)
+
+// UnlockPolicy decides which levels of a course or class students may play.
+type UnlockPolicy = student.UnlockPolicy
+
+// SetCourseUnlockPolicy sets the unlock policy of a course, or removes it
+// when policy is nil. It applies to every class the course is assigned to
+// unless the class has a policy of its own. Only the author of a course may
+// set it; shared built-in courses are adjusted per class instead.
+func (s *Service) SetCourseUnlockPolicy(ctx context.Context, teacherID, courseID string, policy *UnlockPolicy) (TeacherCourse, error) {
+	policy, err := s.normalizeUnlockPolicy(ctx, policy)
+	if err != nil {
+		return TeacherCourse{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	course, err := s.ownedCourse(teacherID, courseID)
+	if err != nil {
+		return TeacherCourse{}, err
+	}
+	course.UnlockPolicy = policy
+	s.syncCourse(ctx, course)
+	return course.Clone(), nil
+}
+
+// SetClassUnlockPolicy sets the unlock policy of a class, or removes it when
+// policy is nil so the policies of its courses apply again.
+func (s *Service) SetClassUnlockPolicy(ctx context.Context, teacherID, classID string, policy *UnlockPolicy) (TeacherClassInfo, error) {
+	policy, err := s.normalizeUnlockPolicy(ctx, policy)
+	if err != nil {
+		return TeacherClassInfo{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return TeacherClassInfo{}, err
+	}
+	detail.Class.UnlockPolicy = policy
+	s.syncUnlocks(ctx, detail)
+	return s.classView(ctx, detail, time.Now()).Class, nil
+}
+
+// StudentUnlocks lists the levels opened for one student of a class.
+func (s *Service) StudentUnlocks(ctx context.Context, teacherID, classID, studentID string) ([]student.LevelUnlock, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return nil, err
+	}
+	return s.students.LevelUnlocks(ctx, studentID), nil
+}
+
+// UnlockLevelForStudent opens a level for one student of a class whatever
+// the unlock policy, for example for a child who was absent.
+func (s *Service) UnlockLevelForStudent(ctx context.Context, teacherID, classID, studentID, levelID string) ([]student.LevelUnlock, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return nil, err
+	}
+	return s.students.UnlockLevelFor(ctx, studentID, levelID)
+}
+
+// RevokeLevelUnlock closes a level opened for one student of a class again.
+func (s *Service) RevokeLevelUnlock(ctx context.Context, teacherID, classID, studentID, levelID string) ([]student.LevelUnlock, error) {
+	if err := s.ensureOwnedMember(teacherID, classID, studentID); err != nil {
+		return nil, err
+	}
+	return s.students.RevokeLevelUnlock(ctx, studentID, levelID)
+}
+
+func (s *Service) normalizeUnlockPolicy(ctx context.Context, policy *UnlockPolicy) (*UnlockPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	normalized, err := s.students.NormalizeUnlockPolicy(ctx, *policy)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+func (s *Service) ensureOwnedMember(teacherID, classID, studentID string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	detail, err := s.ownedClass(teacherID, classID)
+	if err != nil {
+		return err
+	}
+	for _, member := range detail.Students {
+		if member.ID == studentID {
+			return nil
+		}
+	}
+	return ErrStudentNotFound
+}
+
+// syncUnlocks registers the unlock policies of a class with the student
+// service. A class policy covers all levels; otherwise each course policy
+// covers the levels of its course, the first assigned course winning for
+// levels shared by several. Callers must hold the lock.
+func (s *Service) syncUnlocks(ctx context.Context, detail *TeacherClassDetail) {
+	unlocks := student.ClassUnlocks{Levels: make(map[string]student.UnlockPolicy)}
+	if detail.Class.UnlockPolicy != nil {
+		unlocks.Default = *detail.Class.UnlockPolicy
+	} else {
+		for _, course := range detail.Courses {
+			if course.UnlockPolicy == nil {
+				continue
+			}
+			for _, chapter := range course.Chapters {
+				for _, level := range chapter.Levels {
+					if _, ok := unlocks.Levels[level.ID]; !ok {
+						unlocks.Levels[level.ID] = *course.UnlockPolicy
+					}
+				}
+			}
+		}
+	}
+	s.students.ConfigureUnlocks(ctx, detail.Class.ID, unlocks)
+}
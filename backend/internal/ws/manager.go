@@ -7,15 +7,20 @@ import (
 	"nhooyr.io/websocket"
 )
 
-// Manager keeps track of WebSocket connections per user.
+// Manager keeps track of WebSocket connections per user and of the topics
+// connections subscribed to.
 type Manager struct {
 	mu          sync.RWMutex
 	connections map[string][]*websocket.Conn
+	topics      map[string]map[*websocket.Conn]struct{}
 }
 
 // NewManager creates a new WebSocket manager instance.
 func NewManager() *Manager {
-	return &Manager{connections: make(map[string][]*websocket.Conn)}
+	return &Manager{
+		connections: make(map[string][]*websocket.Conn),
+		topics:      make(map[string]map[*websocket.Conn]struct{}),
+	}
 }
 
 // Register adds a connection for the given user ID.
@@ -39,10 +44,59 @@ func (m *Manager) Broadcast(ctx context.Context, userID string, payload []byte)
 	return firstErr
 }
 
-// Cleanup removes closed connections.
+// ClassTopic is the topic of the students of a class.
+func ClassTopic(classID string) string {
+	return "class:" + classID
+}
+
+// ClassMonitorTopic is the topic of the live monitor of a class.
+func ClassMonitorTopic(classID string) string {
+	return "class:" + classID + ":monitor"
+}
+
+// Subscribe adds a connection to a topic.
+func (m *Manager) Subscribe(topic string, conn *websocket.Conn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscribers, ok := m.topics[topic]
+	if !ok {
+		subscribers = make(map[*websocket.Conn]struct{})
+		m.topics[topic] = subscribers
+	}
+	subscribers[conn] = struct{}{}
+}
+
+// Unsubscribe removes a connection from a topic.
+func (m *Manager) Unsubscribe(topic string, conn *websocket.Conn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unsubscribe(topic, conn)
+}
+
+// Publish sends a payload to all connections subscribed to a topic.
+func (m *Manager) Publish(ctx context.Context, topic string, payload []byte) error {
+	m.mu.RLock()
+	conns := make([]*websocket.Conn, 0, len(m.topics[topic]))
+	for conn := range m.topics[topic] {
+		conns = append(conns, conn)
+	}
+	m.mu.RUnlock()
+	var firstErr error
+	for _, conn := range conns {
+		if err := conn.Write(ctx, websocket.MessageText, payload); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Cleanup removes closed connections and their topic subscriptions.
 func (m *Manager) Cleanup(userID string, conn *websocket.Conn) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	for topic := range m.topics {
+		m.unsubscribe(topic, conn)
+	}
 	connections := m.connections[userID]
 	for i, existing := range connections {
 		if existing == conn {
@@ -56,3 +110,12 @@ func (m *Manager) Cleanup(userID string, conn *websocket.Conn) {
 	}
 	m.connections[userID] = connections
 }
+
+// unsubscribe removes a connection from a topic. Callers must hold the lock.
+func (m *Manager) unsubscribe(topic string, conn *websocket.Conn) {
+	subscribers := m.topics[topic]
+	delete(subscribers, conn)
+	if len(subscribers) == 0 {
+		delete(m.topics, topic)
+	}
+}
@@ -5,20 +5,26 @@ import (
 	"errors"
 	"io/fs"
 	"log"
+	"os"
 	"os/signal"
+	"reflect"
+	"strings"
 	"syscall"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/hibiken/asynq"
 	"go.uber.org/automaxprocs/maxprocs"
 
+	"github.com/codeadventurers/api-go/internal/http/handlers/admin"
 	"github.com/codeadventurers/api-go/internal/http/handlers/auth"
 	"github.com/codeadventurers/api-go/internal/http/handlers/health"
+	"github.com/codeadventurers/api-go/internal/http/handlers/parent"
 	"github.com/codeadventurers/api-go/internal/http/handlers/student"
 	"github.com/codeadventurers/api-go/internal/http/handlers/teacher"
 	wsHandler "github.com/codeadventurers/api-go/internal/http/handlers/ws"
 	"github.com/codeadventurers/api-go/internal/http/router"
 	"github.com/codeadventurers/api-go/internal/jobs"
+	"github.com/codeadventurers/api-go/internal/levelpack"
 	"github.com/codeadventurers/api-go/internal/platform/cache"
 	"github.com/codeadventurers/api-go/internal/platform/config"
 	"github.com/codeadventurers/api-go/internal/platform/logger"
@@ -27,7 +33,10 @@ import (
 	"github.com/codeadventurers/api-go/internal/platform/storage"
 	"github.com/codeadventurers/api-go/internal/platform/telemetry"
 	authService "github.com/codeadventurers/api-go/internal/service/auth"
+	exportService "github.com/codeadventurers/api-go/internal/service/export"
 	healthService "github.com/codeadventurers/api-go/internal/service/health"
+	monitorService "github.com/codeadventurers/api-go/internal/service/monitor"
+	parentService "github.com/codeadventurers/api-go/internal/service/parent"
 	studentService "github.com/codeadventurers/api-go/internal/service/student"
 	teacherService "github.com/codeadventurers/api-go/internal/service/teacher"
 	"github.com/codeadventurers/api-go/internal/ws"
@@ -35,6 +44,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "levelpack" {
+		os.Exit(levelpack.Run(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	if _, err := maxprocs.Set(); err != nil {
 		log.Printf("failed to set GOMAXPROCS: %v", err)
 	}
@@ -82,28 +95,58 @@ func main() {
 	redisClient := cache.NewRedis(cfg.Redis)
 	defer redisClient.Close()
 
-	asynqClient := asynq.NewClient(asynq.RedisClientOpt{Addr: cfg.Redis.Addr, Password: cfg.Redis.Password, DB: cfg.Redis.DB})
+	redisOpt := asynq.RedisClientOpt{Addr: cfg.Redis.Addr, Password: cfg.Redis.Password, DB: cfg.Redis.DB}
+	asynqClient := asynq.NewClient(redisOpt)
 	defer asynqClient.Close()
 
 	jobDispatcher := jobs.NewDispatcher(asynqClient)
 
 	validate := validator.New()
+	// Report JSON field names in validation error details.
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
 
 	// Initialize repositories - commented out for now, services still use in-memory data
 	// repos := mysqlrepo.NewRepositories(db)
 
 	// Services will be refactored to use repositories in the next phase
-	authSvc := authService.New()
 	studentSvc := studentService.New()
-	teacherSvc := teacherService.New()
+	teacherSvc := teacherService.New(studentSvc)
+	authSvc := authService.New(teacherSvc)
+	parentSvc := parentService.New(studentSvc)
+	exportFiles, err := storage.NewLocalFiles(cfg.Export.Dir)
+	if err != nil {
+		loggr.Fatal("failed to prepare export storage", zapError(err))
+	}
+	exportSvc := exportService.New(teacherSvc, parentSvc, exportFiles, jobDispatcher, cfg.Export.TTL)
 	healthSvc := healthService.New(db, redisClient)
+	wsMgr := ws.NewManager()
+	monitorSvc := monitorService.New(wsMgr, loggr.Named("monitor"))
+	studentSvc.ObserveActivity(ctx, monitorSvc.Observe)
+	studentSvc.ObserveControls(ctx, monitorSvc.ObserveControls)
+	go monitorSvc.Run(ctx)
+	go exportSvc.Run(ctx)
+
+	worker := jobs.NewWorker(redisOpt, cfg.Asynq, loggr.Named("worker"))
+	worker.HandleExports(exportSvc)
+	go func() {
+		if err := worker.Run(ctx); err != nil {
+			loggr.Error("job worker stopped", zapError(err))
+		}
+	}()
 
 	authH := auth.New(authSvc, validate, loggr.Named("auth-handler"))
 	studentH := student.New(studentSvc, jobDispatcher, validate, loggr.Named("student-handler"))
-	teacherH := teacher.New(teacherSvc, loggr.Named("teacher-handler"))
+	teacherH := teacher.New(teacherSvc, exportSvc, loggr.Named("teacher-handler"))
+	parentH := parent.New(parentSvc, exportSvc, loggr.Named("parent-handler"))
+	adminH := admin.New(studentSvc, loggr.Named("admin-handler"))
 	healthH := health.New(healthSvc, loggr.Named("health-handler"))
-	wsMgr := ws.NewManager()
-	wsH := wsHandler.New(wsMgr, loggr.Named("ws-handler"))
+	wsH := wsHandler.New(wsMgr, studentSvc, teacherSvc, monitorSvc, loggr.Named("ws-handler"))
 
 	rateLimiter := rate.New(cfg.RateLimit.PerMinute)
 
@@ -112,9 +155,12 @@ func main() {
 		Auth:        authH,
 		Student:     studentH,
 		Teacher:     teacherH,
+		Parent:      parentH,
+		Admin:       adminH,
 		Health:      healthH,
 		WS:          wsH,
 		RateLimiter: rateLimiter,
+		Logger:      loggr.Named("http"),
 	})
 
 	srv := server.New(cfg.Addr(), engine)
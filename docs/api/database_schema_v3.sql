@@ -54,6 +54,33 @@ CREATE TABLE student_avatars (
   INDEX idx_student_avatars_student (student_id)
 ) ENGINE=InnoDB;
 
+-- Avatar items equipped per slot
+CREATE TABLE student_avatar_slots (
+  student_id VARCHAR(64) NOT NULL,
+  slot ENUM('head', 'cape', 'pet') NOT NULL,
+  avatar_item VARCHAR(64) NOT NULL,
+  PRIMARY KEY (student_id, slot),
+  FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Wallet transactions. amount is signed (earnings positive, purchases
+-- negative); idempotency_key makes posting an entry twice a no-op.
+CREATE TABLE wallet_ledger (
+  id VARCHAR(64) PRIMARY KEY,
+  student_id VARCHAR(64) NOT NULL,
+  idempotency_key VARCHAR(128) NOT NULL,
+  kind ENUM('earn', 'spend', 'grant', 'reward') NOT NULL,
+  currency ENUM('stars', 'coins') DEFAULT NULL,
+  amount INT NOT NULL DEFAULT 0,
+  avatar_item VARCHAR(64) DEFAULT NULL,
+  reason VARCHAR(255) DEFAULT NULL,
+  actor_id VARCHAR(64) DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE,
+  UNIQUE KEY uk_wallet_ledger_key (student_id, idempotency_key),
+  INDEX idx_wallet_ledger_student (student_id, created_at)
+) ENGINE=InnoDB;
+
 -- Achievements/badges earned by students
 CREATE TABLE student_badges (
   id INT AUTO_INCREMENT PRIMARY KEY,
@@ -76,6 +103,28 @@ CREATE TABLE student_compendium (
   INDEX idx_student_compendium_student (student_id)
 ) ENGINE=InnoDB;
 
+-- Practice time per student and local calendar day. frozen marks a missed
+-- day covered by a streak freeze.
+CREATE TABLE student_activity_days (
+  student_id VARCHAR(64) NOT NULL,
+  activity_date DATE NOT NULL,
+  active_seconds INT NOT NULL DEFAULT 0,
+  frozen BOOLEAN DEFAULT FALSE,
+  PRIMARY KEY (student_id, activity_date),
+  FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Streak state per student
+CREATE TABLE student_streaks (
+  student_id VARCHAR(64) PRIMARY KEY,
+  current_streak INT NOT NULL DEFAULT 0,
+  longest_streak INT NOT NULL DEFAULT 0,
+  freezes_available TINYINT NOT NULL DEFAULT 0,
+  last_active_date DATE DEFAULT NULL,
+  last_event_at TIMESTAMP NULL,
+  FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
 -- Teachers table (extends users)
 CREATE TABLE teachers (
   user_id VARCHAR(64) PRIMARY KEY,
@@ -113,8 +162,20 @@ CREATE TABLE classes (
   id VARCHAR(64) PRIMARY KEY,
   name VARCHAR(128) NOT NULL,
   invite_code VARCHAR(32) NOT NULL,
+  -- 0 allows any number of joins
+  invite_max_uses INT NOT NULL DEFAULT 0,
+  invite_uses INT NOT NULL DEFAULT 0,
+  -- NULL never expires
+  invite_expires_at TIMESTAMP NULL DEFAULT NULL,
+  invite_created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   teacher_id VARCHAR(64) NOT NULL,
   hint_limit INT DEFAULT 3,
+  -- Rolling window in minutes the hint limit applies to
+  hint_window_minutes INT NOT NULL DEFAULT 30,
+  archived_at TIMESTAMP NULL DEFAULT NULL,
+  -- Unlock policy JSON ({mode, minStars, prerequisites, unlockedLevels});
+  -- NULL applies the policies of the class courses
+  unlock_policy JSON DEFAULT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   INDEX idx_classes_teacher (teacher_id),
@@ -141,9 +202,15 @@ CREATE TABLE courses (
   id VARCHAR(64) PRIMARY KEY,
   name VARCHAR(128) NOT NULL,
   description TEXT DEFAULT NULL,
+  -- Authoring teacher; NULL for built-in courses
+  owner_id VARCHAR(64) DEFAULT NULL,
   display_order INT DEFAULT 0,
+  -- Unlock policy JSON for the course levels; NULL unlocks sequentially
+  unlock_policy JSON DEFAULT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (owner_id) REFERENCES teachers(user_id) ON DELETE CASCADE,
+  INDEX idx_courses_owner (owner_id),
   INDEX idx_courses_order (display_order)
 ) ENGINE=InnoDB;
 
@@ -176,6 +243,7 @@ CREATE TABLE levels (
   hints JSON DEFAULT NULL,
   allowed_blocks JSON DEFAULT NULL,
   rewards JSON DEFAULT NULL,
+  revision INT DEFAULT 1,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   FOREIGN KEY (chapter_id) REFERENCES chapters(id) ON DELETE CASCADE,
@@ -183,6 +251,43 @@ CREATE TABLE levels (
   INDEX idx_levels_order (display_order)
 ) ENGINE=InnoDB;
 
+-- Immutable level snapshots; levels.revision points at the published one
+CREATE TABLE level_revisions (
+  level_id VARCHAR(64) NOT NULL,
+  revision INT NOT NULL,
+  status ENUM('draft', 'published') DEFAULT 'draft',
+  definition JSON NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  published_at TIMESTAMP NULL,
+  PRIMARY KEY (level_id, revision),
+  FOREIGN KEY (level_id) REFERENCES levels(id) ON DELETE CASCADE,
+  INDEX idx_level_revisions_status (status)
+) ENGINE=InnoDB;
+
+-- Per-locale text of chapters; empty fields fall back to chapters.title/summary
+CREATE TABLE chapter_translations (
+  chapter_id VARCHAR(64) NOT NULL,
+  locale VARCHAR(16) NOT NULL,
+  title VARCHAR(128) DEFAULT NULL,
+  summary TEXT DEFAULT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (chapter_id, locale),
+  FOREIGN KEY (chapter_id) REFERENCES chapters(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Per-locale text of the published level revision; revisions keep their own
+-- copy inside level_revisions.definition
+CREATE TABLE level_translations (
+  level_id VARCHAR(64) NOT NULL,
+  locale VARCHAR(16) NOT NULL,
+  name VARCHAR(128) DEFAULT NULL,
+  hints JSON DEFAULT NULL,
+  comic TEXT DEFAULT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (level_id, locale),
+  FOREIGN KEY (level_id) REFERENCES levels(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
 -- ============================================================
 -- Student Progress Tracking
 -- ============================================================
@@ -199,6 +304,7 @@ CREATE TABLE student_level_progress (
   hints_used INT DEFAULT 0,
   total_duration INT DEFAULT 0,
   attempts INT DEFAULT 0,
+  level_revision INT DEFAULT 1,
   last_replay_log JSON DEFAULT NULL,
   first_completed_at TIMESTAMP NULL,
   last_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -210,6 +316,39 @@ CREATE TABLE student_level_progress (
   INDEX idx_progress_status (status)
 ) ENGINE=InnoDB;
 
+-- Levels a teacher opened for one student regardless of the unlock policy
+CREATE TABLE student_level_unlocks (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  student_id VARCHAR(64) NOT NULL,
+  level_id VARCHAR(64) NOT NULL,
+  unlocked_by VARCHAR(64) NOT NULL,
+  unlocked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE,
+  FOREIGN KEY (level_id) REFERENCES levels(id) ON DELETE CASCADE,
+  UNIQUE KEY uk_student_level_unlock (student_id, level_id)
+) ENGINE=InnoDB;
+
+-- Attempt history: every program run, pruned to the newest 50 per student
+-- and level and to 90 days of history
+CREATE TABLE level_attempts (
+  id VARCHAR(64) PRIMARY KEY,
+  student_id VARCHAR(64) NOT NULL,
+  level_id VARCHAR(64) NOT NULL,
+  program JSON NOT NULL,
+  success BOOLEAN DEFAULT FALSE,
+  stars INT DEFAULT 0,
+  steps INT DEFAULT 0,
+  error_code VARCHAR(32) DEFAULT NULL,
+  misconceptions JSON DEFAULT NULL,
+  level_revision INT DEFAULT 1,
+  duration INT DEFAULT 0,
+  created_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),
+  FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE,
+  FOREIGN KEY (level_id) REFERENCES levels(id) ON DELETE CASCADE,
+  INDEX idx_attempts_student_level (student_id, level_id, created_at),
+  INDEX idx_attempts_created (created_at)
+) ENGINE=InnoDB;
+
 -- ============================================================
 -- Sandbox & Submissions
 -- ============================================================
@@ -217,34 +356,171 @@ CREATE TABLE student_level_progress (
 CREATE TABLE sandbox_projects (
   id VARCHAR(64) PRIMARY KEY,
   student_id VARCHAR(64) NOT NULL,
+  class_id VARCHAR(64) DEFAULT NULL,
   title VARCHAR(128) DEFAULT 'Untitled Project',
+  level_id VARCHAR(64) DEFAULT NULL,
+  map_config JSON DEFAULT NULL,
   code TEXT DEFAULT NULL,
   thumbnail_url VARCHAR(255) DEFAULT NULL,
-  is_public BOOLEAN DEFAULT FALSE,
+  forked_from VARCHAR(64) DEFAULT NULL,
+  fork_count INT DEFAULT 0,
+  status ENUM('private','pending','published','rejected') DEFAULT 'private',
+  moderation_note VARCHAR(255) DEFAULT NULL,
+  moderated_by VARCHAR(64) DEFAULT NULL,
+  published_at TIMESTAMP NULL DEFAULT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE,
+  FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE SET NULL,
+  FOREIGN KEY (forked_from) REFERENCES sandbox_projects(id) ON DELETE SET NULL,
   INDEX idx_sandbox_student (student_id),
-  INDEX idx_sandbox_public (is_public)
+  INDEX idx_sandbox_class_status (class_id, status),
+  INDEX idx_sandbox_forked (forked_from)
 ) ENGINE=InnoDB;
 
 CREATE TABLE work_submissions (
   id VARCHAR(64) PRIMARY KEY,
   student_id VARCHAR(64) NOT NULL,
   class_id VARCHAR(64) DEFAULT NULL,
+  kind ENUM('level', 'project') DEFAULT 'level',
   level_id VARCHAR(64) DEFAULT NULL,
+  project_id VARCHAR(64) DEFAULT NULL,
+  assignment_id VARCHAR(64) DEFAULT NULL,
+  assignment_item_id VARCHAR(64) DEFAULT NULL,
   title VARCHAR(128) DEFAULT NULL,
   content JSON DEFAULT NULL,
+  result JSON DEFAULT NULL,
   status ENUM('pending', 'approved', 'rejected') DEFAULT 'pending',
   teacher_feedback TEXT DEFAULT NULL,
+  stars TINYINT DEFAULT NULL,
+  reviewed_by VARCHAR(64) DEFAULT NULL,
+  version INT DEFAULT 1,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   reviewed_at TIMESTAMP NULL,
   FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE,
   FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE SET NULL,
   FOREIGN KEY (level_id) REFERENCES levels(id) ON DELETE SET NULL,
-  INDEX idx_submissions_student (student_id),
+  FOREIGN KEY (project_id) REFERENCES sandbox_projects(id) ON DELETE SET NULL,
+  UNIQUE KEY uk_submissions_level (student_id, kind, level_id),
+  UNIQUE KEY uk_submissions_project (student_id, project_id),
   INDEX idx_submissions_class (class_id),
-  INDEX idx_submissions_status (status)
+  INDEX idx_submissions_status (status),
+  INDEX idx_submissions_assignment (assignment_id, assignment_item_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE assignments (
+  id VARCHAR(64) PRIMARY KEY,
+  class_id VARCHAR(64) NOT NULL,
+  owner_id VARCHAR(64) DEFAULT NULL,
+  title VARCHAR(128) NOT NULL,
+  description TEXT DEFAULT NULL,
+  status ENUM('open', 'closed') DEFAULT 'open',
+  is_assessment BOOLEAN DEFAULT FALSE,
+  time_limit_minutes INT DEFAULT 0,
+  max_runs INT DEFAULT 0,
+  released_at TIMESTAMP NULL DEFAULT NULL,
+  start_at TIMESTAMP NOT NULL,
+  due_at TIMESTAMP NOT NULL,
+  closed_at TIMESTAMP NULL DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE,
+  INDEX idx_assignments_class (class_id, status, due_at)
+) ENGINE=InnoDB;
+
+CREATE TABLE assignment_items (
+  assignment_id VARCHAR(64) NOT NULL,
+  id VARCHAR(64) NOT NULL,
+  kind ENUM('level', 'project') DEFAULT 'level',
+  level_id VARCHAR(64) DEFAULT NULL,
+  title VARCHAR(128) DEFAULT NULL,
+  min_stars TINYINT DEFAULT NULL,
+  display_order INT DEFAULT 0,
+  PRIMARY KEY (assignment_id, id),
+  FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE,
+  FOREIGN KEY (level_id) REFERENCES levels(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+CREATE TABLE assessment_sessions (
+  assignment_id VARCHAR(64) NOT NULL,
+  user_id VARCHAR(64) NOT NULL,
+  started_at TIMESTAMP NOT NULL,
+  ends_at TIMESTAMP NOT NULL,
+  finished_at TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY (assignment_id, user_id),
+  FOREIGN KEY (assignment_id) REFERENCES assignments(id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+CREATE TABLE assessment_levels (
+  assignment_id VARCHAR(64) NOT NULL,
+  user_id VARCHAR(64) NOT NULL,
+  level_id VARCHAR(64) NOT NULL,
+  runs INT DEFAULT 0,
+  program JSON DEFAULT NULL,
+  success BOOLEAN DEFAULT FALSE,
+  steps INT DEFAULT 0,
+  stars TINYINT DEFAULT 0,
+  error_code VARCHAR(64) DEFAULT NULL,
+  submitted_at TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY (assignment_id, user_id, level_id),
+  FOREIGN KEY (assignment_id, user_id) REFERENCES assessment_sessions(assignment_id, user_id) ON DELETE CASCADE,
+  FOREIGN KEY (level_id) REFERENCES levels(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+CREATE TABLE rubrics (
+  id VARCHAR(64) PRIMARY KEY,
+  class_id VARCHAR(64) NOT NULL,
+  owner_id VARCHAR(64) DEFAULT NULL,
+  kind ENUM('level', 'project') DEFAULT 'level',
+  level_id VARCHAR(64) NOT NULL DEFAULT '',
+  title VARCHAR(128) NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (class_id) REFERENCES classes(id) ON DELETE CASCADE,
+  UNIQUE KEY uk_rubrics_target (class_id, kind, level_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE rubric_criteria (
+  rubric_id VARCHAR(64) NOT NULL,
+  id VARCHAR(64) NOT NULL,
+  title VARCHAR(128) NOT NULL,
+  points SMALLINT NOT NULL,
+  check_config JSON DEFAULT NULL,
+  display_order INT DEFAULT 0,
+  PRIMARY KEY (rubric_id, id),
+  FOREIGN KEY (rubric_id) REFERENCES rubrics(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+CREATE TABLE work_reviews (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  submission_id VARCHAR(64) NOT NULL,
+  version INT NOT NULL,
+  status ENUM('approved', 'rejected') NOT NULL,
+  feedback TEXT DEFAULT NULL,
+  stars TINYINT NOT NULL,
+  rubric_id VARCHAR(64) DEFAULT NULL,
+  score JSON DEFAULT NULL,
+  score_total SMALLINT DEFAULT NULL,
+  content JSON DEFAULT NULL,
+  result JSON DEFAULT NULL,
+  reviewed_by VARCHAR(64) NOT NULL,
+  reviewed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (submission_id) REFERENCES work_submissions(id) ON DELETE CASCADE,
+  INDEX idx_work_reviews_submission (submission_id, version),
+  INDEX idx_work_reviews_rubric (rubric_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE student_notifications (
+  id VARCHAR(64) PRIMARY KEY,
+  student_id VARCHAR(64) NOT NULL,
+  kind VARCHAR(32) NOT NULL,
+  ref_id VARCHAR(64) DEFAULT NULL,
+  title VARCHAR(128) NOT NULL,
+  body TEXT DEFAULT NULL,
+  read_at TIMESTAMP NULL DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE,
+  INDEX idx_notifications_student (student_id, read_at)
 ) ENGINE=InnoDB;
 
 -- ============================================================
@@ -262,6 +538,48 @@ CREATE TABLE compendium_entries (
   INDEX idx_compendium_chapter (chapter_id)
 ) ENGINE=InnoDB;
 
+-- Avatar catalog. Items with price 0 are not sold (starter items, level
+-- rewards, teacher grants).
+CREATE TABLE avatar_items (
+  id VARCHAR(64) PRIMARY KEY,
+  name VARCHAR(128) NOT NULL,
+  slot ENUM('head', 'cape', 'pet') NOT NULL,
+  price INT NOT NULL DEFAULT 0,
+  currency ENUM('stars', 'coins') DEFAULT NULL,
+  icon VARCHAR(255) DEFAULT NULL,
+  translations JSON DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_avatar_items_slot (slot)
+) ENGINE=InnoDB;
+
+-- Badge definitions; rule is the BadgeRule JSON evaluated on every level
+-- completion. owner_id is NULL for built-in badges.
+CREATE TABLE badge_definitions (
+  code VARCHAR(64) PRIMARY KEY,
+  name VARCHAR(128) NOT NULL,
+  description TEXT DEFAULT NULL,
+  icon VARCHAR(255) DEFAULT NULL,
+  rule JSON NOT NULL,
+  translations JSON DEFAULT NULL,
+  owner_id VARCHAR(64) DEFAULT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (owner_id) REFERENCES teachers(user_id) ON DELETE CASCADE,
+  INDEX idx_badge_definitions_owner (owner_id)
+) ENGINE=InnoDB;
+
+-- Level completions kept for streak badge rules
+CREATE TABLE level_completions (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  student_id VARCHAR(64) NOT NULL,
+  level_id VARCHAR(64) NOT NULL,
+  stars TINYINT NOT NULL,
+  hints_used INT NOT NULL DEFAULT 0,
+  completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (student_id) REFERENCES students(user_id) ON DELETE CASCADE,
+  INDEX idx_level_completions_student (student_id, completed_at)
+) ENGINE=InnoDB;
+
 CREATE TABLE weekly_reports (
   id VARCHAR(64) PRIMARY KEY,
   student_id VARCHAR(64) NOT NULL,
@@ -295,15 +613,23 @@ CREATE TABLE asset_records (
 CREATE TABLE export_records (
   id VARCHAR(64) PRIMARY KEY,
   user_id VARCHAR(64) NOT NULL,
+  user_role ENUM('teacher', 'parent') NOT NULL,
   export_type VARCHAR(32) NOT NULL,
+  export_format ENUM('csv', 'xlsx') NOT NULL DEFAULT 'csv',
+  class_id VARCHAR(64) DEFAULT NULL,
+  student_id VARCHAR(64) DEFAULT NULL,
   file_path VARCHAR(512) DEFAULT NULL,
+  file_name VARCHAR(255) DEFAULT NULL,
+  file_size INT DEFAULT 0,
   status ENUM('pending', 'processing', 'completed', 'failed') DEFAULT 'pending',
   error_message TEXT DEFAULT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
   completed_at TIMESTAMP NULL,
+  expires_at TIMESTAMP NULL,
   FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
   INDEX idx_exports_user (user_id),
-  INDEX idx_exports_status (status)
+  INDEX idx_exports_status (status),
+  INDEX idx_exports_expires (expires_at)
 ) ENGINE=InnoDB;
 
 -- ============================================================
@@ -339,6 +665,29 @@ LEFT JOIN users u ON c.teacher_id = u.id
 LEFT JOIN students s ON c.id = s.class_id
 GROUP BY c.id, c.name, c.invite_code, c.teacher_id, u.name, c.hint_limit;
 
+-- Progress of every student on the levels of the courses assigned to their
+-- class; the basis of the class statistics shown to teachers.
+CREATE OR REPLACE VIEW v_class_student_progress AS
+SELECT
+  s.class_id,
+  s.user_id AS student_id,
+  COUNT(slp.id) AS completed_levels,
+  COALESCE(SUM(slp.stars), 0) AS stars,
+  MAX(slp.first_completed_at) AS last_completed_at
+FROM students s
+LEFT JOIN (
+  SELECT DISTINCT cc.class_id, l.id AS level_id
+  FROM class_courses cc
+  JOIN chapters ch ON ch.course_id = cc.course_id
+  JOIN levels l ON l.chapter_id = ch.id
+) cl ON cl.class_id = s.class_id
+LEFT JOIN student_level_progress slp
+  ON slp.student_id = s.user_id
+  AND slp.level_id = cl.level_id
+  AND slp.status = 'completed'
+WHERE s.class_id IS NOT NULL
+GROUP BY s.class_id, s.user_id;
+
 CREATE OR REPLACE VIEW v_level_statistics AS
 SELECT
   l.id AS level_id,
@@ -50,7 +50,7 @@ const LevelDetailPage = () => {
         if (!cancelled) {
           if (detailResponse.error) {
             // 如果关卡未解锁，保留状态并允许展示准备信息
-            if (detailResponse.error.includes('未解锁')) {
+            if (detailResponse.code === 'level.locked') {
               setStatus('locked');
             } else {
               setError(detailResponse.error);
@@ -91,7 +91,7 @@ const PlayPage = () => {
       ]);
 
       if (detailResponse.error) {
-        if (detailResponse.error.includes('未解锁')) {
+        if (detailResponse.code === 'level.locked') {
           setLevel(null);
           setLevelStatus('locked');
         } else {
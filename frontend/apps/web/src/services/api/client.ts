@@ -3,6 +3,8 @@ const API_BASE = 'http://localhost:8081/api';
 export interface ApiResponse<T> {
   data?: T;
   error?: string;
+  /** Stable error code from the backend error envelope, e.g. `level.locked`. */
+  code?: string;
   message?: string;
 }
 
@@ -40,6 +42,32 @@ export interface ParentSettings {
   notifyChannels: Array<'app' | 'email' | 'sms'>;
 }
 
+export type ExportType = 'class_gradebook' | 'student_progress' | 'attempt_history';
+
+export interface ExportRequest {
+  type: ExportType;
+  format?: 'csv' | 'xlsx';
+  classId?: string;
+  studentId?: string;
+}
+
+export interface ExportRecord {
+  id: string;
+  userId: string;
+  role: 'teacher' | 'parent';
+  type: ExportType;
+  format: 'csv' | 'xlsx';
+  classId?: string;
+  studentId?: string;
+  status: 'pending' | 'processing' | 'completed' | 'failed';
+  fileName?: string;
+  size?: number;
+  error?: string;
+  createdAt: number;
+  completedAt?: number;
+  expiresAt?: number;
+}
+
 export interface TeacherProfile extends User {
   role: 'teacher';
   managedClassIds: string[];
@@ -72,6 +100,16 @@ export interface ParentProgressRecord {
   replayLog: unknown[];
 }
 
+export type Misconception = 'repeat_off_by_one' | 'left_right_confusion' | 'collect_wrong_tile' | 'over_nesting';
+
+export interface MisconceptionCount {
+  kind: Misconception;
+  count: number;
+  students?: number;
+  levels: string[];
+  lastSeenAt: number;
+}
+
 export interface WeeklyReport {
   childId: string;
   generatedAt: number;
@@ -95,12 +133,115 @@ export interface TeacherCourse {
   chapters: TeacherCourseChapter[];
 }
 
+export interface ClassInvite {
+  code: string;
+  maxUses: number;
+  uses: number;
+  expiresAt: number;
+  createdAt: number;
+  status: 'active' | 'expired' | 'exhausted' | 'archived';
+}
+
+export interface UnlockPolicy {
+  mode: 'sequential' | 'open' | 'stars' | 'manual';
+  minStars?: number;
+  prerequisites?: Record<string, string[]>;
+  unlockedLevels?: string[];
+}
+
+export interface LevelUnlock {
+  levelId: string;
+  unlockedAt: number;
+}
+
+export interface AssessmentSettings {
+  timeLimitMinutes: number;
+  maxRuns: number;
+  releasedAt?: number;
+}
+
+export interface AssessmentSubmission {
+  levelId: string;
+  program: any[];
+  result: { success: boolean; steps: number; stars: number; errorCode?: string };
+  submittedAt: number;
+}
+
+export interface StudentAssessment {
+  assignmentId: string;
+  status: 'not_started' | 'in_progress' | 'ended';
+  timeLimitMinutes: number;
+  maxRuns: number;
+  startedAt?: number;
+  endsAt?: number;
+  remainingSeconds: number;
+  released: boolean;
+  score?: number;
+  maxScore: number;
+  levels: Array<{
+    levelId: string;
+    runs: number;
+    runsRemaining?: number;
+    submittedAt?: number;
+    result?: AssessmentSubmission;
+  }>;
+}
+
+export interface AssessmentResults {
+  assignment: any;
+  maxScore: number;
+  rows: Array<{
+    id: string;
+    name: string;
+    status: StudentAssessment['status'];
+    startedAt?: number;
+    endedAt?: number;
+    runs: number;
+    score: number;
+    submissions: AssessmentSubmission[];
+  }>;
+}
+
+export interface ClassMonitorStudent {
+  studentId: string;
+  name: string;
+  levelId?: string;
+  attempts: number;
+  failedRuns: number;
+  hints: number;
+  lastError?: string;
+  stars: number;
+  status: 'inactive' | 'working' | 'stuck' | 'completed' | 'idle';
+  lastEventAt?: number;
+}
+
+export interface ClassControls {
+  frozen: boolean;
+  chapterId?: string;
+  levelId?: string;
+  sandboxDisabled: boolean;
+  banner?: string;
+  updatedAt?: number;
+}
+
+export interface ClassMonitorEvent {
+  type: 'snapshot' | 'run' | 'hint' | 'complete' | 'idle' | 'controls';
+  classId: string;
+  at: number;
+  student?: ClassMonitorStudent;
+  students?: ClassMonitorStudent[];
+  controls?: ClassControls;
+}
+
 export interface TeacherClassSummary {
   id: string;
   name: string;
   inviteCode: string;
+  invite: ClassInvite;
+  archived: boolean;
   studentCount: number;
   hintLimit: number;
+  hintWindowMinutes: number;
   activeStudents: number;
   averageProgress: number;
   completionRate: number;
@@ -113,12 +254,18 @@ export interface TeacherClassDetail {
   class: {
     id: string;
     name: string;
+    ownerId?: string;
     inviteCode: string;
+    invite: ClassInvite;
     hintLimit: number;
+    hintWindowMinutes: number;
     studentCount: number;
     levelCount: number;
     averageProgress: number;
     completionRate: number;
+    archived: boolean;
+    archivedAt?: number;
+    unlockPolicy?: UnlockPolicy;
   };
   students: Array<{
     id: string;
@@ -230,7 +377,7 @@ class ApiClient {
       
       if (!response.ok) {
         const errorData = await response.json().catch(() => ({}));
-        return { error: errorData.message || `HTTP ${response.status}` };
+        return { error: errorData.message || `HTTP ${response.status}`, code: errorData.code };
       }
       
       const data = await response.json();
@@ -250,7 +397,7 @@ class ApiClient {
       
       if (!response.ok) {
         const errorData = await response.json().catch(() => ({}));
-        return { error: errorData.message || `HTTP ${response.status}` };
+        return { error: errorData.message || `HTTP ${response.status}`, code: errorData.code };
       }
       
       const data = await response.json();
@@ -270,7 +417,26 @@ class ApiClient {
       
       if (!response.ok) {
         const errorData = await response.json().catch(() => ({}));
-        return { error: errorData.message || `HTTP ${response.status}` };
+        return { error: errorData.message || `HTTP ${response.status}`, code: errorData.code };
+      }
+      
+      const data = await response.json();
+      return { data };
+    } catch (error) {
+      return { error: error instanceof Error ? error.message : '网络错误' };
+    }
+  }
+
+  async delete<T>(path: string): Promise<ApiResponse<T>> {
+    try {
+      const response = await fetch(`${API_BASE}${path}`, {
+        method: 'DELETE',
+        headers: this.getHeaders(),
+      });
+      
+      if (!response.ok) {
+        const errorData = await response.json().catch(() => ({}));
+        return { error: errorData.message || `HTTP ${response.status}`, code: errorData.code };
       }
       
       const data = await response.json();
@@ -290,7 +456,7 @@ class ApiClient {
       
       if (!response.ok) {
         const errorData = await response.json().catch(() => ({}));
-        return { error: errorData.message || `HTTP ${response.status}` };
+        return { error: errorData.message || `HTTP ${response.status}`, code: errorData.code };
       }
       
       const data = await response.json();
@@ -359,6 +525,14 @@ class ApiClient {
     return this.post(`/student/hints/${levelId}`, payload);
   }
 
+  async getStudentActivity(days?: number): Promise<ApiResponse<any>> {
+    return this.get(days ? `/student/activity?days=${days}` : '/student/activity');
+  }
+
+  async sendActivityHeartbeat(): Promise<ApiResponse<any>> {
+    return this.post('/student/activity/heartbeat', {});
+  }
+
   async getStudentSettings(): Promise<ApiResponse<any>> {
     return this.get('/student/settings');
   }
@@ -379,6 +553,110 @@ class ApiClient {
     return this.put('/student/avatar', { equipped });
   }
 
+  async getStudentShop(): Promise<ApiResponse<any>> {
+    return this.get('/student/shop');
+  }
+
+  async purchaseAvatarItem(itemId: string): Promise<ApiResponse<any>> {
+    return this.post('/student/shop/purchase', { itemId });
+  }
+
+  async getStudentWallet(): Promise<ApiResponse<any>> {
+    return this.get('/student/wallet');
+  }
+
+  async getStudentProjects(): Promise<ApiResponse<any>> {
+    return this.get('/student/projects');
+  }
+
+  async getStudentProject(projectId: string): Promise<ApiResponse<any>> {
+    return this.get(`/student/projects/${projectId}`);
+  }
+
+  async createStudentProject(project: any): Promise<ApiResponse<any>> {
+    return this.post('/student/projects', project);
+  }
+
+  async updateStudentProject(projectId: string, project: any): Promise<ApiResponse<any>> {
+    return this.put(`/student/projects/${projectId}`, project);
+  }
+
+  async deleteStudentProject(projectId: string): Promise<ApiResponse<any>> {
+    return this.delete(`/student/projects/${projectId}`);
+  }
+
+  async forkStudentProject(projectId: string): Promise<ApiResponse<any>> {
+    return this.post(`/student/projects/${projectId}/fork`);
+  }
+
+  async publishStudentProject(projectId: string): Promise<ApiResponse<any>> {
+    return this.post(`/student/projects/${projectId}/publish`);
+  }
+
+  async unpublishStudentProject(projectId: string): Promise<ApiResponse<any>> {
+    return this.post(`/student/projects/${projectId}/unpublish`);
+  }
+
+  async runStudentProject(projectId: string, program?: any[]): Promise<ApiResponse<any>> {
+    return this.post(`/student/projects/${projectId}/run`, program ? { program } : undefined);
+  }
+
+  async getClassGallery(): Promise<ApiResponse<any>> {
+    return this.get('/student/gallery');
+  }
+
+  async submitStudentWork(work: {
+    levelId?: string;
+    projectId?: string;
+    program?: any[];
+    source?: string;
+    title?: string;
+    assignmentId?: string;
+    itemId?: string;
+  }): Promise<ApiResponse<any>> {
+    return this.post('/student/works', work);
+  }
+
+  async getStudentWorks(): Promise<ApiResponse<{ works: any[] }>> {
+    return this.get('/student/works');
+  }
+
+  async getStudentAssignments(): Promise<ApiResponse<{ assignments: any[] }>> {
+    return this.get('/student/assignments');
+  }
+
+  async getStudentAssignment(assignmentId: string): Promise<ApiResponse<any>> {
+    return this.get(`/student/assignments/${assignmentId}`);
+  }
+
+  async getStudentAssessment(assignmentId: string): Promise<ApiResponse<StudentAssessment>> {
+    return this.get(`/student/assignments/${assignmentId}/assessment`);
+  }
+
+  async startStudentAssessment(assignmentId: string): Promise<ApiResponse<StudentAssessment>> {
+    return this.post(`/student/assignments/${assignmentId}/assessment/start`);
+  }
+
+  async submitStudentAssessment(
+    assignmentId: string,
+    levelId: string,
+    submission: { program?: any[]; source?: string }
+  ): Promise<ApiResponse<StudentAssessment>> {
+    return this.post(`/student/assignments/${assignmentId}/assessment/levels/${levelId}/submit`, submission);
+  }
+
+  async finishStudentAssessment(assignmentId: string): Promise<ApiResponse<StudentAssessment>> {
+    return this.post(`/student/assignments/${assignmentId}/assessment/finish`);
+  }
+
+  async getStudentNotifications(): Promise<ApiResponse<any>> {
+    return this.get('/student/notifications');
+  }
+
+  async markNotificationsRead(ids?: string[]): Promise<ApiResponse<any>> {
+    return this.post('/student/notifications/read', ids ? { ids } : undefined);
+  }
+
   // 教师端API
   async getTeacherCourses(): Promise<ApiResponse<{ courses: TeacherCourse[] }>> {
     return this.get('/teacher/courses');
@@ -392,8 +670,138 @@ class ApiClient {
     return this.get('/teacher/analytics/heatmap');
   }
 
-  async getTeacherClasses(): Promise<ApiResponse<{ classes: TeacherClassSummary[] }>> {
-    return this.get('/teacher/classes');
+  async getTeacherAnalyticsResource(
+    resource:
+      | 'progress'
+      | 'heatmap'
+      | 'funnel'
+      | 'attempts'
+      | 'stars'
+      | 'time'
+      | 'errors'
+      | 'timeline'
+      | 'misconceptions',
+    filters: { classId?: string; from?: string; to?: string } = {}
+  ): Promise<ApiResponse<any>> {
+    const query = new URLSearchParams(
+      Object.entries(filters).filter(([, value]) => value) as Array<[string, string]>
+    ).toString();
+    return this.get(`/teacher/analytics/${resource}${query ? `?${query}` : ''}`);
+  }
+
+  async getTeacherClasses(includeArchived = false): Promise<ApiResponse<{ classes: TeacherClassSummary[] }>> {
+    return this.get(`/teacher/classes${includeArchived ? '?archived=true' : ''}`);
+  }
+
+  async createTeacherClass(payload: {
+    name: string;
+    hintLimit?: number;
+    hintWindowMinutes?: number;
+    courseIds?: string[];
+    invite?: { maxUses?: number; expiresAt?: number };
+  }): Promise<ApiResponse<TeacherClassDetail>> {
+    return this.post('/teacher/classes', payload);
+  }
+
+  async updateTeacherClass(
+    classId: string,
+    update: { name?: string; hintLimit?: number; hintWindowMinutes?: number; archived?: boolean }
+  ): Promise<ApiResponse<TeacherClassDetail>> {
+    return this.patch(`/teacher/classes/${classId}`, update);
+  }
+
+  async regenerateClassInvite(classId: string, settings: { maxUses?: number; expiresAt?: number } = {}): Promise<ApiResponse<ClassInvite>> {
+    return this.post(`/teacher/classes/${classId}/invite`, settings);
+  }
+
+  async expireClassInvite(classId: string): Promise<ApiResponse<ClassInvite>> {
+    return this.post(`/teacher/classes/${classId}/invite/expire`);
+  }
+
+  async importClassRoster(classId: string, csv: string, dryRun = false): Promise<ApiResponse<any>> {
+    try {
+      const response = await fetch(`${API_BASE}/teacher/classes/${classId}/roster/import${dryRun ? '?dryRun=true' : ''}`, {
+        method: 'POST',
+        headers: { ...this.getHeaders(), 'Content-Type': 'text/csv' },
+        body: csv,
+      });
+
+      if (!response.ok) {
+        const errorData = await response.json().catch(() => ({}));
+        return { error: errorData.message || `HTTP ${response.status}`, code: errorData.code };
+      }
+
+      const data = await response.json();
+      return { data };
+    } catch (error) {
+      return { error: error instanceof Error ? error.message : '网络错误' };
+    }
+  }
+
+  async removeClassStudent(classId: string, studentId: string): Promise<ApiResponse<any>> {
+    return this.delete(`/teacher/classes/${classId}/students/${studentId}`);
+  }
+
+  async transferClassStudent(classId: string, studentId: string, targetClassId: string): Promise<ApiResponse<any>> {
+    return this.post(`/teacher/classes/${classId}/students/${studentId}/transfer`, { targetClassId });
+  }
+
+  async setCourseUnlockPolicy(courseId: string, policy: UnlockPolicy | null): Promise<ApiResponse<any>> {
+    const path = `/teacher/courses/${courseId}/unlock-policy`;
+    return policy ? this.put(path, policy) : this.delete(path);
+  }
+
+  async setClassUnlockPolicy(classId: string, policy: UnlockPolicy | null): Promise<ApiResponse<any>> {
+    const path = `/teacher/classes/${classId}/unlock-policy`;
+    return policy ? this.put(path, policy) : this.delete(path);
+  }
+
+  async getStudentUnlocks(classId: string, studentId: string): Promise<ApiResponse<{ unlocks: LevelUnlock[] }>> {
+    return this.get(`/teacher/classes/${classId}/students/${studentId}/unlocks`);
+  }
+
+  async unlockLevelForStudent(classId: string, studentId: string, levelId: string): Promise<ApiResponse<{ unlocks: LevelUnlock[] }>> {
+    return this.post(`/teacher/classes/${classId}/students/${studentId}/unlocks`, { levelId });
+  }
+
+  async revokeLevelUnlock(classId: string, studentId: string, levelId: string): Promise<ApiResponse<{ unlocks: LevelUnlock[] }>> {
+    return this.delete(`/teacher/classes/${classId}/students/${studentId}/unlocks/${levelId}`);
+  }
+
+  async getClassControls(classId: string): Promise<ApiResponse<ClassControls>> {
+    return this.get(`/teacher/classes/${classId}/controls`);
+  }
+
+  async updateClassControls(classId: string, controls: Partial<ClassControls>): Promise<ApiResponse<ClassControls>> {
+    return this.put(`/teacher/classes/${classId}/controls`, controls);
+  }
+
+  // Opens the live monitor of a class. WebSocket requests cannot carry the
+  // x-user-id header, so the teacher is sent as a query parameter.
+  openClassMonitor(classId: string, onEvent: (event: ClassMonitorEvent) => void): WebSocket {
+    return this.openStream(`/teacher/classes/${classId}/monitor`, onEvent);
+  }
+
+  // Opens the run stream of the current student, which delivers the
+  // classroom controls of their class as `controls` events.
+  openStudentStream(onEvent: (event: ClassMonitorEvent) => void): WebSocket {
+    return this.openStream('/student/run/stream', onEvent);
+  }
+
+  private openStream(path: string, onEvent: (event: ClassMonitorEvent) => void): WebSocket {
+    const url = new URL(`${API_BASE.replace(/^http/, 'ws')}${path}`);
+    if (this.userId) {
+      url.searchParams.set('userId', this.userId);
+    }
+    const socket = new WebSocket(url.toString());
+    socket.onmessage = (message) => {
+      try {
+        onEvent(JSON.parse(message.data) as ClassMonitorEvent);
+      } catch {
+        // Ignore malformed frames.
+      }
+    };
+    return socket;
   }
 
   async getTeacherClassDetail(classId: string): Promise<ApiResponse<TeacherClassDetail>> {
@@ -404,16 +812,113 @@ class ApiClient {
     return this.get('/teacher/works/pending');
   }
 
-  async reviewTeacherWork(workId: string, status: 'approved' | 'rejected'): Promise<ApiResponse<any>> {
-    return this.post(`/teacher/works/${workId}/review`, { status });
+  async reviewTeacherWork(
+    workId: string,
+    status: 'approved' | 'rejected',
+    feedback?: string,
+    stars?: number,
+    scores?: Array<{ criterionId: string; points?: number; comment?: string }>
+  ): Promise<ApiResponse<any>> {
+    return this.post(`/teacher/works/${workId}/review`, { status, feedback, stars, scores });
+  }
+
+  async getClassWorks(classId: string, status?: 'pending' | 'approved' | 'rejected'): Promise<ApiResponse<{ works: any[] }>> {
+    return this.get(`/teacher/classes/${classId}/works${status ? `?status=${status}` : ''}`);
+  }
+
+  async getClassRubrics(classId: string): Promise<ApiResponse<any>> {
+    return this.get(`/teacher/classes/${classId}/rubrics`);
+  }
+
+  async saveClassRubric(classId: string, rubricId: string, rubric: any): Promise<ApiResponse<any>> {
+    return this.put(`/teacher/classes/${classId}/rubrics/${rubricId}`, rubric);
+  }
+
+  async deleteClassRubric(classId: string, rubricId: string): Promise<ApiResponse<any>> {
+    return this.delete(`/teacher/classes/${classId}/rubrics/${rubricId}`);
+  }
+
+  async getClassAssignments(classId: string, status?: 'open' | 'closed'): Promise<ApiResponse<{ assignments: any[] }>> {
+    return this.get(`/teacher/classes/${classId}/assignments${status ? `?status=${status}` : ''}`);
+  }
+
+  async createClassAssignment(
+    classId: string,
+    assignment: {
+      title: string;
+      description?: string;
+      startAt?: number;
+      dueAt: number;
+      items: Array<{ kind: 'level' | 'project'; levelId?: string; title?: string; minStars?: number }>;
+      assessment?: Pick<AssessmentSettings, 'timeLimitMinutes' | 'maxRuns'>;
+    }
+  ): Promise<ApiResponse<any>> {
+    return this.post(`/teacher/classes/${classId}/assignments`, assignment);
+  }
+
+  async getAssignmentMatrix(classId: string, assignmentId: string): Promise<ApiResponse<any>> {
+    return this.get(`/teacher/classes/${classId}/assignments/${assignmentId}`);
+  }
+
+  async closeClassAssignment(classId: string, assignmentId: string): Promise<ApiResponse<any>> {
+    return this.post(`/teacher/classes/${classId}/assignments/${assignmentId}/close`);
+  }
+
+  async getAssessmentResults(classId: string, assignmentId: string): Promise<ApiResponse<AssessmentResults>> {
+    return this.get(`/teacher/classes/${classId}/assignments/${assignmentId}/assessment`);
+  }
+
+  async releaseAssessment(classId: string, assignmentId: string): Promise<ApiResponse<any>> {
+    return this.post(`/teacher/classes/${classId}/assignments/${assignmentId}/assessment/release`);
+  }
+
+  async getClassGradebook(classId: string): Promise<ApiResponse<any>> {
+    return this.get(`/teacher/classes/${classId}/gradebook`);
   }
 
   async assignCourseToClass(classId: string, courseId: string): Promise<ApiResponse<any>> {
     return this.post(`/teacher/classes/${classId}/assign-course`, { courseId });
   }
 
-  async updateClassHintLimit(classId: string, hintLimit: number): Promise<ApiResponse<any>> {
-    return this.patch(`/teacher/classes/${classId}/hint-limit`, { hintLimit });
+  async updateClassHintLimit(classId: string, hintLimit: number, hintWindowMinutes?: number): Promise<ApiResponse<any>> {
+    return this.patch(`/teacher/classes/${classId}/hint-limit`, { hintLimit, hintWindowMinutes });
+  }
+
+  async getClassProjects(classId: string, status?: 'pending' | 'published' | 'rejected'): Promise<ApiResponse<any>> {
+    return this.get(`/teacher/classes/${classId}/projects${status ? `?status=${status}` : ''}`);
+  }
+
+  async moderateClassProject(classId: string, projectId: string, approve: boolean, note?: string): Promise<ApiResponse<any>> {
+    return this.post(`/teacher/classes/${classId}/projects/${projectId}/moderate`, { approve, note });
+  }
+
+  async getExports(role: 'teacher' | 'parent'): Promise<ApiResponse<{ exports: ExportRecord[] }>> {
+    return this.get(`/${role}/exports`);
+  }
+
+  async createExport(role: 'teacher' | 'parent', request: ExportRequest): Promise<ApiResponse<ExportRecord>> {
+    return this.post(`/${role}/exports`, request);
+  }
+
+  async getExport(role: 'teacher' | 'parent', exportId: string): Promise<ApiResponse<ExportRecord>> {
+    return this.get(`/${role}/exports/${exportId}`);
+  }
+
+  async downloadExport(role: 'teacher' | 'parent', exportId: string): Promise<ApiResponse<Blob>> {
+    try {
+      const response = await fetch(`${API_BASE}/${role}/exports/${exportId}/download`, {
+        headers: this.getHeaders(),
+      });
+
+      if (!response.ok) {
+        const errorData = await response.json().catch(() => ({}));
+        return { error: errorData.message || `HTTP ${response.status}`, code: errorData.code };
+      }
+
+      return { data: await response.blob() };
+    } catch (error) {
+      return { error: error instanceof Error ? error.message : '网络错误' };
+    }
   }
 
   // 家长端API